@@ -0,0 +1,94 @@
+// Package audit writes a machine-readable log of the decisions a node makes in every round and
+// compares the logs of several nodes to find where the replicas diverged.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Round is one line of the audit log, written when a round ends. Byte strings are hex encoded.
+type Round struct {
+	Node        uint32              `json:"node"`
+	Round       uint32              `json:"round"`
+	Seed        string              `json:"seed"`
+	Proposals   []Proposal          `json:"proposals"`
+	MinProposal string              `json:"min_proposal,omitempty"` // digest of the proposal I chose
+	Prepared    string              `json:"prepared,omitempty"`     // digest I sent a Prepare for
+	Committed   string              `json:"committed,omitempty"`    // digest I sent a Commit for
+	Prepares    map[string][]uint32 `json:"prepares"`               // digest -> voters
+	Commits     map[string][]uint32 `json:"commits"`                // digest -> voters
+	Applied     string              `json:"applied,omitempty"`      // digest that reached the commit quorum
+}
+
+type Proposal struct {
+	Digest   string `json:"digest"`
+	Proposer uint32 `json:"proposer"`
+	Score    uint32 `json:"score"`
+	NumReqs  int    `json:"num_reqs"`
+}
+
+// Logger appends rounds to a JSON lines file
+type Logger struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+func Open(file string) (*Logger, error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %s", err.Error())
+	}
+	return &Logger{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Log appends r to the log. A nil Logger discards r.
+func (l *Logger) Log(r *Round) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(r)
+}
+
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// Read parses an audit log
+func Read(r io.Reader) ([]*Round, error) {
+	var rounds []*Round
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		round := &Round{}
+		if err := json.Unmarshal(scanner.Bytes(), round); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds, scanner.Err()
+}
+
+// ReadFile parses the audit log in file
+func ReadFile(file string) ([]*Round, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rounds, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err.Error())
+	}
+	return rounds, nil
+}
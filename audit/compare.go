@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type Severity string
+
+const (
+	// Safety divergences mean that the replicas' states differ or that a node is Byzantine
+	Safety Severity = "safety"
+	// Warnings are differences that honest nodes can have, e.g. when messages are late
+	Warning Severity = "warning"
+)
+
+type Divergence struct {
+	Round    uint32
+	Severity Severity
+	Kind     string
+	Detail   string
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("round %d: %s: %s: %s", d.Round, d.Severity, d.Kind, d.Detail)
+}
+
+// Compare finds the rounds in which the logs of several nodes disagree. rounds holds the entries
+// of all nodes in any order.
+func Compare(rounds []*Round) []Divergence {
+	byRound := make(map[uint32][]*Round)
+	for _, r := range rounds {
+		byRound[r.Round] = append(byRound[r.Round], r)
+	}
+	var divs []Divergence
+	for _, round := range slices.Sorted(maps.Keys(byRound)) {
+		divs = append(divs, compareRound(round, byRound[round])...)
+	}
+	return divs
+}
+
+func compareRound(round uint32, entries []*Round) []Divergence {
+	slices.SortFunc(entries, func(a, b *Round) int { return cmp.Compare(a.Node, b.Node) })
+	var divs []Divergence
+	add := func(severity Severity, kind, format string, args ...any) {
+		divs = append(divs, Divergence{Round: round, Severity: severity, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if seeds := group(entries, func(r *Round) string { return r.Seed }); len(seeds) > 1 {
+		add(Warning, "seed", "nodes disagree on the seed: %s", formatGroups(seeds))
+	}
+	if mins := group(entries, func(r *Round) string { return r.MinProposal }); len(mins) > 1 {
+		add(Warning, "min_proposal", "nodes chose different proposals: %s", formatGroups(mins))
+	}
+
+	applied := group(entries, func(r *Round) string { return r.Applied })
+	missing := applied[""]
+	delete(applied, "")
+	if len(applied) > 1 {
+		add(Safety, "applied", "nodes applied different proposals: %s", formatGroups(applied))
+	} else if len(applied) == 1 && len(missing) > 0 {
+		add(Warning, "applied", "nodes %v didn't apply %s", missing, short(slices.Collect(maps.Keys(applied))[0]))
+	}
+
+	// A voter seen voting for different digests in the same phase by different nodes equivocated
+	for _, phase := range []string{"prepare", "commit"} {
+		digests := make(map[uint32]map[string]bool) // voter -> digests
+		for _, r := range entries {
+			votes := r.Prepares
+			if phase == "commit" {
+				votes = r.Commits
+			}
+			for digest, voters := range votes {
+				for _, voter := range voters {
+					if digests[voter] == nil {
+						digests[voter] = make(map[string]bool)
+					}
+					digests[voter][digest] = true
+				}
+			}
+		}
+		for _, voter := range slices.Sorted(maps.Keys(digests)) {
+			if len(digests[voter]) > 1 {
+				ds := slices.Sorted(maps.Keys(digests[voter]))
+				for i := range ds {
+					ds[i] = short(ds[i])
+				}
+				add(Safety, "equivocation", "node %d sent a %s for each of %s", voter, phase, strings.Join(ds, ", "))
+			}
+		}
+	}
+	return divs
+}
+
+// group returns value -> nodes
+func group(entries []*Round, value func(r *Round) string) map[string][]uint32 {
+	groups := make(map[string][]uint32)
+	for _, r := range entries {
+		v := value(r)
+		groups[v] = append(groups[v], r.Node)
+	}
+	return groups
+}
+
+func formatGroups(groups map[string][]uint32) string {
+	var parts []string
+	for _, v := range slices.Sorted(maps.Keys(groups)) {
+		name := short(v)
+		if name == "" {
+			name = "none"
+		}
+		parts = append(parts, fmt.Sprintf("%s by nodes %v", name, groups[v]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// short abbreviates a hex digest for display
+func short(digest string) string {
+	if len(digest) > 8 {
+		return digest[:8]
+	}
+	return digest
+}
@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	entry := func(node uint32, applied string) *Round {
+		return &Round{
+			Node:        node,
+			Round:       3,
+			Seed:        "5eed",
+			MinProposal: "aaaa",
+			Prepares:    map[string][]uint32{"aaaa": {0, 1, 2}},
+			Commits:     map[string][]uint32{"aaaa": {0, 1, 2}},
+			Applied:     applied,
+		}
+	}
+
+	// agreement
+	require.Empty(t, Compare([]*Round{entry(0, "aaaa"), entry(1, "aaaa"), entry(2, "aaaa")}))
+
+	// a node that didn't reach the commit quorum is only a warning
+	divs := Compare([]*Round{entry(0, "aaaa"), entry(1, "aaaa"), entry(2, "")})
+	require.Equal(t, []Divergence{{Round: 3, Severity: Warning, Kind: "applied", Detail: "nodes [2] didn't apply aaaa"}}, divs)
+
+	// conflicting applied digests and votes
+	forked := entry(2, "bbbb")
+	forked.MinProposal = "bbbb"
+	forked.Prepares = map[string][]uint32{"bbbb": {1, 2}}
+	divs = Compare([]*Round{entry(0, "aaaa"), entry(1, "aaaa"), forked})
+	var kinds []string
+	for _, d := range divs {
+		kinds = append(kinds, string(d.Severity)+"/"+d.Kind)
+	}
+	require.Equal(t, []string{"warning/min_proposal", "safety/applied", "safety/equivocation", "safety/equivocation"}, kinds)
+	require.Equal(t, "nodes applied different proposals: aaaa by nodes [0 1]; bbbb by nodes [2]", divs[1].Detail)
+	require.Equal(t, "node 1 sent a prepare for each of aaaa, bbbb", divs[2].Detail)
+	require.Equal(t, "node 2 sent a prepare for each of aaaa, bbbb", divs[3].Detail)
+}
+
+func TestRead(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	require.NoError(t, enc.Encode(&Round{Node: 1, Round: 2, Applied: "aaaa"}))
+	require.NoError(t, enc.Encode(&Round{Node: 1, Round: 3}))
+	rounds, err := Read(&buf)
+	require.NoError(t, err)
+	require.Len(t, rounds, 2)
+	require.Equal(t, "aaaa", rounds[0].Applied)
+
+	_, err = Read(bytes.NewBufferString("{\n"))
+	require.ErrorContains(t, err, "line 1")
+}
@@ -0,0 +1,169 @@
+// Package beacon implements a randomness beacon from threshold BLS signatures. The beacon value of
+// a round is the group's signature of the round number, which takes partial signatures from
+// Threshold nodes to produce. It is unique, so unlike a seed chained from proposer proofs nobody
+// can bias it by choosing whether to publish, and anyone can verify it against the group key.
+package beacon
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+)
+
+// values of this many recent rounds are kept for queries
+const maxValues = 1024
+
+// Beacon collects the partial signatures of the nodes and recovers the beacon values
+type Beacon struct {
+	threshold int
+	groupKey  *bls12381.G2
+	shares    []*bls12381.G2
+	secret    *bls12381.Scalar
+
+	mu       sync.Mutex
+	partials map[uint32]map[uint32]*bls12381.G1 // round -> node -> partial signature
+	values   map[uint32][]byte                  // round -> beacon value
+	latest   uint32
+}
+
+// New sets up the beacon of a node given the public keys of the dealing and the node's secret
+// share
+func New(pub *Public, secret []byte) (*Beacon, error) {
+	b := &Beacon{
+		threshold: pub.Threshold,
+		groupKey:  new(bls12381.G2),
+		secret:    new(bls12381.Scalar),
+		partials:  make(map[uint32]map[uint32]*bls12381.G1),
+		values:    make(map[uint32][]byte),
+	}
+	if err := b.groupKey.SetBytes(pub.GroupKey); err != nil {
+		return nil, fmt.Errorf("invalid group key: %s", err.Error())
+	}
+	for i, share := range pub.Shares {
+		p := new(bls12381.G2)
+		if err := p.SetBytes(share); err != nil {
+			return nil, fmt.Errorf("invalid public key share of node %d: %s", i, err.Error())
+		}
+		b.shares = append(b.shares, p)
+	}
+	if b.threshold < 1 || b.threshold > len(b.shares) {
+		return nil, fmt.Errorf("invalid threshold %d for %d nodes", b.threshold, len(b.shares))
+	}
+	if err := b.secret.UnmarshalBinary(secret); err != nil {
+		return nil, fmt.Errorf("invalid secret share: %s", err.Error())
+	}
+	return b, nil
+}
+
+// Sign returns my partial signature of round
+func (b *Beacon) Sign(round uint32) []byte {
+	return sign(b.secret, round).BytesCompressed()
+}
+
+// AddPartial records the partial signature of node for round. It returns the beacon value if the
+// partial signature completed it. Partial signatures aren't checked one by one, the pairings are
+// too slow for that: only the recovered value is, and the partial signatures that went into it
+// if it's wrong.
+func (b *Beacon) AddPartial(round, node uint32, partial []byte) ([]byte, error) {
+	if int(node) >= len(b.shares) {
+		return nil, fmt.Errorf("unknown node %d", node)
+	}
+	p := new(bls12381.G1)
+	if err := p.SetBytes(partial); err != nil {
+		return nil, fmt.Errorf("invalid partial signature from node %d: %s", node, err.Error())
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.values[round]; ok {
+		return nil, nil
+	}
+	if _, ok := b.partials[round][node]; ok {
+		return nil, nil
+	}
+	if b.partials[round] == nil {
+		b.partials[round] = make(map[uint32]*bls12381.G1)
+	}
+	partials := b.partials[round]
+	partials[node] = p
+	if len(partials) < b.threshold {
+		return nil, nil
+	}
+	sig := recoverSig(partials)
+	if !verifyPoint(b.groupKey, round, sig) {
+		var bad []uint32
+		for i, partial := range partials {
+			if !verifyPoint(b.shares[i], round, partial) {
+				bad = append(bad, i)
+				delete(partials, i)
+			}
+		}
+		slices.Sort(bad)
+		err := fmt.Errorf("bad partial signatures of round %d from nodes %v", round, bad)
+		if len(partials) < b.threshold {
+			return nil, err
+		}
+		sig = recoverSig(partials)
+		b.setValue(round, sig.BytesCompressed())
+		return sig.BytesCompressed(), err
+	}
+	b.setValue(round, sig.BytesCompressed())
+	return sig.BytesCompressed(), nil
+}
+
+// AddValue records a beacon value received from elsewhere, e.g. along with a proposal
+func (b *Beacon) AddValue(round uint32, sig []byte) error {
+	if _, ok := b.Value(round); ok {
+		return nil
+	}
+	if err := verify(b.groupKey, round, sig); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.setValue(round, sig)
+	return nil
+}
+
+// Value returns the beacon value of round if I have it
+func (b *Beacon) Value(round uint32) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sig, ok := b.values[round]
+	return sig, ok
+}
+
+// Latest returns the beacon value of the highest round I have, false if I have none
+func (b *Beacon) Latest() (uint32, []byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sig, ok := b.values[b.latest]
+	return b.latest, sig, ok
+}
+
+// Prune drops the partial signatures of the rounds before round
+func (b *Beacon) Prune(round uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for r := range b.partials {
+		if r < round {
+			delete(b.partials, r)
+		}
+	}
+}
+
+// Must be called with b.mu held.
+func (b *Beacon) setValue(round uint32, sig []byte) {
+	b.values[round] = sig
+	delete(b.partials, round)
+	if round > b.latest || len(b.values) == 1 {
+		b.latest = round
+	}
+	for r := range b.values {
+		if r+maxValues <= b.latest {
+			delete(b.values, r)
+		}
+	}
+}
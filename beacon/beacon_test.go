@@ -0,0 +1,83 @@
+package beacon
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newBeacons(t *testing.T, n, threshold int) (*Public, []*Beacon) {
+	pub, secrets, err := Deal(rand.Reader, n, threshold)
+	require.NoError(t, err)
+	var beacons []*Beacon
+	for _, secret := range secrets {
+		b, err := New(pub, secret)
+		require.NoError(t, err)
+		beacons = append(beacons, b)
+	}
+	return pub, beacons
+}
+
+func TestThresholdSignature(t *testing.T) {
+	pub, beacons := newBeacons(t, 5, 3)
+
+	// any 3 nodes recover the same value
+	recoverFrom := func(nodes ...uint32) []byte {
+		b, err := New(pub, mustSecret(t, beacons[0]))
+		require.NoError(t, err)
+		var sig []byte
+		for i, node := range nodes {
+			sig, err = b.AddPartial(7, node, beacons[node].Sign(7))
+			require.NoError(t, err)
+			if i < len(nodes)-1 {
+				require.Nil(t, sig)
+			}
+		}
+		require.NotNil(t, sig)
+		return sig
+	}
+	sig := recoverFrom(0, 1, 2)
+	require.Equal(t, sig, recoverFrom(4, 2, 3))
+	require.Equal(t, sig, recoverFrom(1, 3, 4))
+	require.NoError(t, Verify(pub.GroupKey, 7, sig))
+	require.Error(t, Verify(pub.GroupKey, 8, sig))
+	require.Len(t, Randomness(sig), 32)
+
+	// bad partial signatures are found once they are enough to recover a value
+	_, err := beacons[0].AddPartial(7, 9, beacons[1].Sign(7))
+	require.Error(t, err)
+	_, err = beacons[0].AddPartial(7, 1, beacons[2].Sign(7)) // not its key share
+	require.NoError(t, err)
+	_, err = beacons[0].AddPartial(7, 2, beacons[2].Sign(8)) // not the round
+	require.NoError(t, err)
+	value, err := beacons[0].AddPartial(7, 3, beacons[3].Sign(7))
+	require.EqualError(t, err, "bad partial signatures of round 7 from nodes [1 2]")
+	require.Nil(t, value)
+	_, err = beacons[0].AddPartial(7, 0, beacons[0].Sign(7))
+	require.NoError(t, err)
+	value, err = beacons[0].AddPartial(7, 4, beacons[4].Sign(7))
+	require.NoError(t, err)
+	require.Equal(t, sig, value)
+
+	// a value received from elsewhere is verified before it is kept
+	require.Error(t, beacons[1].AddValue(8, sig))
+	require.NoError(t, beacons[1].AddValue(7, sig))
+	round, latest, ok := beacons[1].Latest()
+	require.True(t, ok)
+	require.EqualValues(t, 7, round)
+	require.Equal(t, sig, latest)
+}
+
+func TestDealThreshold(t *testing.T) {
+	_, _, err := Deal(rand.Reader, 5, 6)
+	require.Error(t, err)
+	_, _, err = Deal(rand.Reader, 5, 0)
+	require.Error(t, err)
+}
+
+func mustSecret(t *testing.T, b *Beacon) []byte {
+	secret, err := b.secret.MarshalBinary()
+	require.NoError(t, err)
+	return secret
+}
@@ -0,0 +1,162 @@
+package beacon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Threshold BLS on BLS12-381 with signatures in G1 and public keys in G2. A dealer splits a group
+// secret into n shares with a polynomial of degree t-1. Each node signs the round with its share,
+// and any t of these partial signatures interpolate to the signature of the group secret, which is
+// the same whichever t nodes signed.
+
+var dst = []byte("BEEFTEA-BEACON-V01-CS01-with-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+
+// Public is the public outcome of a dealing
+type Public struct {
+	// Number of partial signatures needed to recover a beacon value
+	Threshold int
+	// Key the beacon values verify against, a compressed G2 point
+	GroupKey []byte
+	// Public key share of each node, node index -> compressed G2 point
+	Shares [][]byte
+}
+
+// Deal splits a random group secret among n nodes so that any t of them can sign. It returns the
+// public keys and the secret share of each node. The dealer learns the group secret, so it has to
+// be trusted to forget it.
+func Deal(rng io.Reader, n, t int) (*Public, [][]byte, error) {
+	if t < 1 || t > n {
+		return nil, nil, fmt.Errorf("invalid threshold %d for %d nodes", t, n)
+	}
+	coeffs := make([]bls12381.Scalar, t)
+	for i := range coeffs {
+		if err := coeffs[i].Random(rng); err != nil {
+			return nil, nil, err
+		}
+	}
+	pub := &Public{Threshold: t, GroupKey: g2Mul(&coeffs[0]).BytesCompressed()}
+	var secrets [][]byte
+	for i := 0; i < n; i++ {
+		share := evalPoly(coeffs, uint64(i+1))
+		b, err := share.MarshalBinary()
+		if err != nil {
+			return nil, nil, err
+		}
+		secrets = append(secrets, b)
+		pub.Shares = append(pub.Shares, g2Mul(share).BytesCompressed())
+	}
+	return pub, secrets, nil
+}
+
+// Threshold returns the number of partial signatures needed among n nodes, 2f+1 where f is the
+// number of faulty nodes tolerated: the f faulty ones can't predict the values on their own, and
+// the honest ones recover them without the faulty ones
+func Threshold(n int) int {
+	return 2*((n-1)/3) + 1
+}
+
+// Verify checks that sig is the beacon value of round for the group key
+func Verify(groupKey []byte, round uint32, sig []byte) error {
+	key := new(bls12381.G2)
+	if err := key.SetBytes(groupKey); err != nil {
+		return fmt.Errorf("invalid group key: %s", err.Error())
+	}
+	return verify(key, round, sig)
+}
+
+// Randomness returns the random bytes of a beacon value
+func Randomness(sig []byte) []byte {
+	h := blake2b.Sum256(sig)
+	return h[:]
+}
+
+func verify(key *bls12381.G2, round uint32, sig []byte) error {
+	p := new(bls12381.G1)
+	if err := p.SetBytes(sig); err != nil {
+		return fmt.Errorf("invalid signature: %s", err.Error())
+	}
+	if !verifyPoint(key, round, p) {
+		return fmt.Errorf("signature of round %d doesn't verify", round)
+	}
+	return nil
+}
+
+// verifyPoint checks e(sig, g2) = e(H(round), key)
+func verifyPoint(key *bls12381.G2, round uint32, sig *bls12381.G1) bool {
+	res := bls12381.ProdPairFrac(
+		[]*bls12381.G1{sig, hashRound(round)},
+		[]*bls12381.G2{bls12381.G2Generator(), key},
+		[]int{1, -1},
+	)
+	return res.IsIdentity()
+}
+
+func sign(secret *bls12381.Scalar, round uint32) *bls12381.G1 {
+	sig := new(bls12381.G1)
+	sig.ScalarMult(secret, hashRound(round))
+	return sig
+}
+
+// recoverSig interpolates the partial signatures at 0, node index -> partial signature
+func recoverSig(partials map[uint32]*bls12381.G1) *bls12381.G1 {
+	sig := new(bls12381.G1)
+	sig.SetIdentity()
+	for i, partial := range partials {
+		// Lagrange coefficient of x_i = i+1: prod x_j / (x_j - x_i) over the other j
+		num, den := new(bls12381.Scalar), new(bls12381.Scalar)
+		num.SetOne()
+		den.SetOne()
+		xi := scalar(uint64(i) + 1)
+		for j := range partials {
+			if j == i {
+				continue
+			}
+			xj := scalar(uint64(j) + 1)
+			num.Mul(num, xj)
+			diff := new(bls12381.Scalar)
+			diff.Sub(xj, xi)
+			den.Mul(den, diff)
+		}
+		den.Inv(den)
+		num.Mul(num, den)
+		term := new(bls12381.G1)
+		term.ScalarMult(num, partial)
+		sig.Add(sig, term)
+	}
+	return sig
+}
+
+func hashRound(round uint32) *bls12381.G1 {
+	msg := binary.BigEndian.AppendUint32([]byte("beeftea beacon"), round)
+	p := new(bls12381.G1)
+	p.Hash(msg, dst)
+	return p
+}
+
+// evalPoly evaluates the polynomial with the given coefficients at x
+func evalPoly(coeffs []bls12381.Scalar, x uint64) *bls12381.Scalar {
+	res := new(bls12381.Scalar)
+	xs := scalar(x)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		res.Mul(res, xs)
+		res.Add(res, &coeffs[i])
+	}
+	return res
+}
+
+func g2Mul(k *bls12381.Scalar) *bls12381.G2 {
+	p := new(bls12381.G2)
+	p.ScalarMult(k, bls12381.G2Generator())
+	return p
+}
+
+func scalar(x uint64) *bls12381.Scalar {
+	s := new(bls12381.Scalar)
+	s.SetUint64(x)
+	return s
+}
@@ -0,0 +1,494 @@
+// Package client talks to a beeftea cluster on behalf of an application. It sends writes to any
+// healthy validator and retries them elsewhere under the same request id, which the nodes use to
+// drop retries of requests that were already committed. Reads either take the value f+1 nodes
+// agree on, so that at least one honest node vouches for it, or take the value of a single node
+// along with the certificate of the commit that wrote it (see Config.Verified).
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/genesis"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/patrickmao1/beeftea/utils"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoQuorum is returned by reads when the nodes that answered don't agree on a value
+var ErrNoQuorum = errors.New("no value has reached f+1 nodes")
+
+// ErrNoProof is returned by verified reads when no node could prove the value of the key, e.g.
+// because it was never written
+var ErrNoProof = errors.New("no node proved the value")
+
+type Node struct {
+	// Address of the node's external RPC server
+	Addr string
+	// Weight of the node in votes, 0 counts as 1, see types.Peer.Weight
+	Weight uint64
+	// Learners serve reads but don't take writes or count towards quorums
+	Learner bool
+	// Key the node signs its votes with, needed for verified reads
+	BLSKey *crypto.BLSPublicKey
+}
+
+type Config struct {
+	// Nodes of the cluster, in the order of its peers as the certificates refer to them by index
+	Nodes []*Node
+	// Verified reads take the value of a single node and check the certificate of the commit
+	// that wrote it against the BLS keys of the nodes. They prove that the value was committed,
+	// but a node that lags behind may return an older one. Reads of keys that no node can prove,
+	// e.g. keys that were never written, fall back to f+1 matching reads.
+	Verified bool
+	// Hash of the genesis of the cluster, which the certificates of verified reads are bound to
+	ChainHash []byte
+
+	// Bounds the calls whose context has no deadline, defaults to 10s
+	Timeout time.Duration
+	// Bounds each request to a node, defaults to 2s
+	AttemptTimeout time.Duration
+	// How long to wait before going through the nodes again once they all failed, defaults to
+	// 100ms. A node that failed is only tried after the others for 10 times as long.
+	Backoff time.Duration
+}
+
+type Client struct {
+	config  Config
+	nodes   []*node
+	blsKeys []*crypto.BLSPublicKey
+	// total weight of the validators
+	totalWeight uint64
+	// where the next call starts going through the nodes, so that load spreads
+	next atomic.Uint32
+}
+
+type node struct {
+	*Node
+	index  int
+	conn   *grpc.ClientConn
+	rpc    types.ExternalRPCClient
+	mu     sync.Mutex
+	downAt time.Time // zero if the last request succeeded
+}
+
+// New connects to the nodes. The connections are made lazily, so New doesn't fail when nodes are
+// down. The default dial options use plaintext connections.
+func New(config Config, opts ...grpc.DialOption) (*Client, error) {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.AttemptTimeout == 0 {
+		config.AttemptTimeout = 2 * time.Second
+	}
+	if config.Backoff == 0 {
+		config.Backoff = 100 * time.Millisecond
+	}
+	c := &Client{config: config}
+	for i, n := range config.Nodes {
+		if !n.Learner {
+			c.totalWeight += n.weight()
+		}
+		if config.Verified && !n.Learner && n.BLSKey == nil {
+			return nil, fmt.Errorf("verified reads need the BLS key of node %d", i)
+		}
+		c.blsKeys = append(c.blsKeys, n.BLSKey)
+	}
+	if c.totalWeight == 0 {
+		return nil, errors.New("no validators")
+	}
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	for i, n := range config.Nodes {
+		conn, err := grpc.NewClient(n.Addr, opts...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to dial node %d: %s", i, err.Error())
+		}
+		c.nodes = append(c.nodes, &node{Node: n, index: i, conn: conn, rpc: types.NewExternalRPCClient(conn)})
+	}
+	return c, nil
+}
+
+// ConfigFromGenesis returns the config of a client of the cluster of doc, whose peers must have
+// an rpc_url. Verified reads need the BLS keys of the nodes, which the genesis doesn't have.
+func ConfigFromGenesis(doc *genesis.Doc) (Config, error) {
+	config := Config{ChainHash: doc.Hash()}
+	for i, peer := range doc.Peers {
+		if peer.RPCURL == "" {
+			return Config{}, fmt.Errorf("peer %d of the genesis has no rpc_url", i)
+		}
+		config.Nodes = append(config.Nodes, &Node{Addr: peer.RPCURL, Weight: peer.Weight, Learner: peer.Learner})
+	}
+	return config, nil
+}
+
+// NewFromAddrs connects to nodes of the same weight, none of them learners, at the given
+// addresses
+func NewFromAddrs(addrs []string, opts ...grpc.DialOption) (*Client, error) {
+	var nodes []*Node
+	for _, addr := range addrs {
+		nodes = append(nodes, &Node{Addr: addr})
+	}
+	return New(Config{Nodes: nodes}, opts...)
+}
+
+func (c *Client) Close() error {
+	var errs []error
+	for _, n := range c.nodes {
+		errs = append(errs, n.conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Put writes val to key under a new request id, which it returns. The write is accepted once a
+// node has it in its mempool and is committed within a few rounds.
+func (c *Client) Put(ctx context.Context, key, val string) (string, error) {
+	id := rand.Text()
+	return id, c.Submit(ctx, &types.PutReq{Id: id, Kv: &types.KeyValue{Key: key, Val: val}})
+}
+
+// Delete removes key under a new request id, which it returns
+func (c *Client) Delete(ctx context.Context, key string) (string, error) {
+	id := rand.Text()
+	return id, c.Submit(ctx, &types.PutReq{Id: id, Kv: &types.KeyValue{Key: key}, Delete: true})
+}
+
+// Submit sends req to a validator, trying the others until one takes it or ctx is done.
+// Resubmitting a request with the id of one that was committed has no effect, as long as it
+// was committed recently.
+func (c *Client) Submit(ctx context.Context, req *types.PutReq) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var lastErr error
+	for {
+		for _, n := range c.order(false) {
+			actx, acancel := context.WithTimeout(ctx, c.config.AttemptTimeout)
+			_, err := n.rpc.Put(actx, req)
+			acancel()
+			if err == nil {
+				n.succeeded()
+				return nil
+			}
+			if ctx.Err() != nil {
+				return giveUp(ctx, err)
+			}
+			if !retryable(err) {
+				return fmt.Errorf("node %d: %w", n.index, err)
+			}
+			n.failed(err)
+			lastErr = fmt.Errorf("node %d: %w", n.index, err)
+		}
+		if err := c.backoff(ctx); err != nil {
+			return giveUp(ctx, lastErr)
+		}
+	}
+}
+
+// Get reads the value of key, "" if it was never written, with a verified read if
+// Config.Verified is set and an f+1 matching read otherwise
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	if c.config.Verified {
+		res, err := c.GetVerified(ctx, key)
+		if err == nil {
+			return res.Kv.Val, nil
+		}
+		if !errors.Is(err, ErrNoProof) {
+			return "", err
+		}
+	}
+	return c.GetQuorum(ctx, key)
+}
+
+// GetQuorum reads key from all validators and returns the value that validators of more than a
+// third of the total weight agree on, asking again until they do or ctx is done
+func (c *Client) GetQuorum(ctx context.Context, key string) (string, error) {
+	res, err := quorumRead(ctx, c, func(ctx context.Context, rpc types.ExternalRPCClient) (*types.GetRes, error) {
+		return rpc.Get(ctx, &types.GetReq{Key: key})
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Kv.GetVal(), nil
+}
+
+// Scan returns the keys that start with prefix and their values, the first limit ones only if
+// limit isn't 0, as validators of more than a third of the total weight return them
+func (c *Client) Scan(ctx context.Context, prefix string, limit uint32) (*types.ScanRes, error) {
+	return quorumRead(ctx, c, func(ctx context.Context, rpc types.ExternalRPCClient) (*types.ScanRes, error) {
+		return rpc.Scan(ctx, &types.ScanReq{Prefix: prefix, Limit: limit})
+	})
+}
+
+// quorumRead asks all validators and returns the answer that validators of more than a third of
+// the total weight agree on, asking again until they do or ctx is done
+func quorumRead[T proto.Message](ctx context.Context, c *Client, ask func(context.Context, types.ExternalRPCClient) (T, error)) (T, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	type result struct {
+		n   *node
+		res T
+		err error
+	}
+	var zero T
+	var lastErr error
+	for {
+		actx, acancel := context.WithTimeout(ctx, c.config.AttemptTimeout)
+		results := make(chan result, len(c.nodes))
+		asked := 0
+		for _, n := range c.nodes {
+			if n.Learner {
+				continue
+			}
+			asked++
+			go func() {
+				res, err := ask(actx, n.rpc)
+				results <- result{n: n, res: res, err: err}
+			}()
+		}
+		tally := make(map[string]uint64) // hash of the answer -> weight
+		for range asked {
+			r := <-results
+			if r.err != nil {
+				if ctx.Err() == nil {
+					r.n.failed(r.err)
+				}
+				continue
+			}
+			r.n.succeeded()
+			answer := string(utils.MustHash(r.res))
+			tally[answer] += r.n.weight()
+			if 3*tally[answer] > c.totalWeight {
+				acancel()
+				return r.res, nil
+			}
+		}
+		acancel()
+		lastErr = fmt.Errorf("%w: %d different answers", ErrNoQuorum, len(tally))
+		if err := c.backoff(ctx); err != nil {
+			return zero, giveUp(ctx, lastErr)
+		}
+	}
+}
+
+// GetVerified reads key from a single node and checks the proof that its value was committed,
+// going to the next node if it has none or a bad one. It returns ErrNoProof if the nodes that
+// answered had no valid proof. The round of the write is in the result.
+func (c *Client) GetVerified(ctx context.Context, key string) (*types.GetRes, error) {
+	if !c.config.Verified {
+		return nil, errors.New("verified reads need Config.Verified and the BLS keys of the nodes")
+	}
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	var lastErr error
+	for {
+		answered := false
+		for _, n := range c.order(true) {
+			actx, acancel := context.WithTimeout(ctx, c.config.AttemptTimeout)
+			res, err := n.rpc.Get(actx, &types.GetReq{Key: key, Prove: true})
+			acancel()
+			if ctx.Err() != nil {
+				return nil, giveUp(ctx, lastErr)
+			}
+			if err != nil {
+				n.failed(err)
+				lastErr = fmt.Errorf("node %d: %w", n.index, err)
+				continue
+			}
+			n.succeeded()
+			answered = true
+			if res.CommitQc == nil {
+				continue
+			}
+			if err := c.verify(key, res); err != nil {
+				log.Warnf("node %d sent a bad proof of key %s: %s", n.index, key, err.Error())
+				lastErr = fmt.Errorf("node %d: bad proof: %w", n.index, err)
+				continue
+			}
+			return res, nil
+		}
+		if answered {
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w, %w", ErrNoProof, lastErr)
+			}
+			return nil, ErrNoProof
+		}
+		if err := c.backoff(ctx); err != nil {
+			return nil, giveUp(ctx, lastErr)
+		}
+	}
+}
+
+// Watch calls fn with the writes and deletes committed from now on to the keys that start with
+// prefix, until ctx is done or fn returns an error, which Watch returns. When the node it watches
+// fails or drops it, it watches another one and skips the events of the rounds it has seen, but
+// misses those committed while it switches.
+func (c *Client) Watch(ctx context.Context, prefix string, fn func(*types.WatchEvent) error) error {
+	var seen *uint32 // round of the last event
+	var lastErr error
+	for {
+		for _, n := range c.order(true) {
+			stream, err := n.rpc.Watch(ctx, &types.WatchReq{Prefix: prefix})
+			switched := seen != nil
+			for err == nil {
+				var e *types.WatchEvent
+				if e, err = stream.Recv(); err != nil {
+					break
+				}
+				n.succeeded()
+				if switched && e.Round <= *seen {
+					continue
+				}
+				switched = false
+				seen = &e.Round
+				if err := fn(e); err != nil {
+					return err
+				}
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			n.failed(err)
+			lastErr = fmt.Errorf("node %d: %w", n.index, err)
+		}
+		if err := c.backoff(ctx); err != nil {
+			return giveUp(ctx, lastErr)
+		}
+	}
+}
+
+// verify checks that res.Kv was written by the proposal that res.CommitQc certifies
+func (c *Client) verify(key string, res *types.GetRes) error {
+	qc := res.CommitQc
+	if qc.Phase != types.Phase_PHASE_COMMIT || qc.Round != res.Round {
+		return fmt.Errorf("%s certificate of round %d for round %d", qc.Phase, qc.Round, res.Round)
+	}
+	if res.Proposal == nil || !bytes.Equal(res.Proposal.Hash(), qc.ProposalDigest) {
+		return errors.New("the proposal isn't the certified one")
+	}
+	if err := qc.Verify(c.config.ChainHash, c.blsKeys, c.isQuorum); err != nil {
+		return err
+	}
+	if res.Kv.GetKey() != key {
+		return fmt.Errorf("read key %s instead of %s", res.Kv.GetKey(), key)
+	}
+	// the last write of the key in the proposal is the one that was applied
+	var written *types.PutReq
+	for _, req := range res.Proposal.Reqs {
+		if req.Kv.GetKey() == key && req.Reconfig == nil {
+			written = req
+		}
+	}
+	if written == nil {
+		return fmt.Errorf("the proposal doesn't write %s", key)
+	}
+	val := written.Kv.Val
+	if written.Delete {
+		val = ""
+	}
+	if val != res.Kv.Val {
+		return fmt.Errorf("the proposal wrote %q, not %q", val, res.Kv.Val)
+	}
+	return nil
+}
+
+func (c *Client) isQuorum(signers []uint32) bool {
+	weight := uint64(0)
+	for _, signer := range signers {
+		if n := c.config.Nodes[signer]; !n.Learner {
+			weight += n.weight()
+		}
+	}
+	return 3*weight > 2*c.totalWeight
+}
+
+// order returns the nodes to try, validators only unless learners serve the request, starting
+// at a different node every call and with the nodes that failed recently last
+func (c *Client) order(learners bool) []*node {
+	start := int(c.next.Add(1))
+	var up, down []*node
+	for i := range c.nodes {
+		n := c.nodes[(start+i)%len(c.nodes)]
+		if n.Learner && !learners {
+			continue
+		}
+		if n.isDown(10 * c.config.Backoff) {
+			down = append(down, n)
+		} else {
+			up = append(up, n)
+		}
+	}
+	return append(up, down...)
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.config.Timeout)
+}
+
+func (c *Client) backoff(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.config.Backoff):
+		return nil
+	}
+}
+
+func (n *Node) weight() uint64 {
+	if n.Weight == 0 {
+		return 1
+	}
+	return n.Weight
+}
+
+func (n *node) failed(err error) {
+	log.Debugf("request to node %d failed: %s", n.index, err.Error())
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.downAt.IsZero() {
+		n.downAt = time.Now()
+	}
+}
+
+func (n *node) succeeded() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.downAt = time.Time{}
+}
+
+// isDown tells whether the node failed within the last cooldown
+func (n *node) isDown(cooldown time.Duration) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return !n.downAt.IsZero() && time.Since(n.downAt) < cooldown
+}
+
+// retryable tells whether another node may take a request that failed with err: the node is
+// down, slow, overloaded, or doesn't take writes
+func retryable(err error) bool {
+	return slices.Contains([]codes.Code{
+		codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.FailedPrecondition,
+	}, status.Code(err))
+}
+
+func giveUp(ctx context.Context, lastErr error) error {
+	if lastErr == nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("%w, last error: %w", ctx.Err(), lastErr)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+// certify returns a commit certificate of proposal in round signed by the given nodes
+func certify(t *testing.T, keys []*crypto.BLSPrivateKey, round uint32, proposal *types.Proposal, signers ...uint32) *types.QuorumCertificate {
+	qc := &types.QuorumCertificate{
+		Round:          round,
+		Phase:          types.Phase_PHASE_COMMIT,
+		ProposalDigest: proposal.Hash(),
+		Signers:        crypto.NewBitmap(len(keys)),
+	}
+	var sigs [][]byte
+	for _, signer := range signers {
+		crypto.Bitmap(qc.Signers).Set(signer)
+		sigs = append(sigs, crypto.BLSSign(keys[signer], qc.SignedMsg(nil)))
+	}
+	var err error
+	qc.AggSig, err = crypto.AggregateBLS(sigs)
+	require.NoError(t, err)
+	return qc
+}
+
+func TestVerify(t *testing.T) {
+	var keys []*crypto.BLSPrivateKey
+	config := Config{Verified: true}
+	for range 4 {
+		key := crypto.GenBLSKey()
+		keys = append(keys, key)
+		config.Nodes = append(config.Nodes, &Node{Addr: "localhost:1", BLSKey: key.PublicKey()})
+	}
+	c, err := New(config)
+	require.NoError(t, err)
+	defer c.Close()
+
+	proposal := &types.Proposal{Reqs: []*types.PutReq{
+		{Id: "1", Kv: &types.KeyValue{Key: "hello", Val: "old"}},
+		{Id: "2", Kv: &types.KeyValue{Key: "other", Val: "x"}},
+		{Id: "3", Kv: &types.KeyValue{Key: "hello", Val: "world"}},
+	}}
+	res := &types.GetRes{
+		Kv:       &types.KeyValue{Key: "hello", Val: "world"},
+		Round:    7,
+		Proposal: proposal,
+		CommitQc: certify(t, keys, 7, proposal, 0, 1, 3),
+	}
+	require.NoError(t, c.verify("hello", res))
+
+	// the value must be the last one the proposal wrote
+	res.Kv.Val = "old"
+	require.ErrorContains(t, c.verify("hello", res), "wrote")
+	res.Kv.Val = "world"
+	require.ErrorContains(t, c.verify("missing", res), "instead of")
+
+	res.CommitQc = certify(t, keys, 7, proposal, 0, 1)
+	require.ErrorContains(t, c.verify("hello", res), "not a quorum")
+	res.CommitQc = certify(t, keys, 6, proposal, 0, 1, 2)
+	require.ErrorContains(t, c.verify("hello", res), "certificate of round 6")
+	res.CommitQc = certify(t, keys, 7, &types.Proposal{}, 0, 1, 2)
+	require.ErrorContains(t, c.verify("hello", res), "isn't the certified one")
+	res.CommitQc = certify(t, keys, 7, proposal, 0, 1, 2)
+	res.CommitQc.AggSig = certify(t, keys, 8, proposal, 0, 1, 2).AggSig
+	require.ErrorContains(t, c.verify("hello", res), "doesn't verify")
+
+	// a delete proves the key is empty
+	deleted := &types.Proposal{Reqs: []*types.PutReq{{Id: "4", Kv: &types.KeyValue{Key: "hello"}, Delete: true}}}
+	res = &types.GetRes{
+		Kv:       &types.KeyValue{Key: "hello"},
+		Round:    8,
+		Proposal: deleted,
+		CommitQc: certify(t, keys, 8, deleted, 1, 2, 3),
+	}
+	require.NoError(t, c.verify("hello", res))
+
+	config.Nodes[2].BLSKey = nil
+	_, err = New(config)
+	require.ErrorContains(t, err, "BLS key of node 2")
+}
@@ -0,0 +1,38 @@
+// Package clock abstracts the time source of a node so that tests can skew it
+package clock
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) *time.Timer
+}
+
+// Real is the system clock
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) NewTimer(d time.Duration) *time.Timer {
+	return time.NewTimer(d)
+}
+
+// Skewed is a clock that is Offset ahead of the system clock and whose timers run Rate times
+// slower, e.g. with Rate 2 a timer of 1s fires after 2s. A zero Rate means 1.
+type Skewed struct {
+	Offset time.Duration
+	Rate   float64
+}
+
+func (c Skewed) Now() time.Time {
+	return time.Now().Add(c.Offset)
+}
+
+func (c Skewed) NewTimer(d time.Duration) *time.Timer {
+	if c.Rate == 0 {
+		return time.NewTimer(d)
+	}
+	return time.NewTimer(time.Duration(float64(d) * c.Rate))
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/patrickmao1/beeftea/keystore"
+	"github.com/patrickmao1/beeftea/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const usage = `usage: admin [flags] <command>
+
+commands:
+  status               print the node's round state, mempool, buffer and peer connectivity
+  set-behavior <spec>  inject a Byzantine behavior (see fault.Parse), "" makes the node honest,
+                       if the node allows fault injection
+  add-peer <url> <public key> [weight]
+                       add a peer, given by its consensus address and hex public key (see
+                       beeftea keys show)
+  remove-peer <index>  remove a peer
+  replace-peer <index> <url> <public key> [weight]
+                       replace a peer with another machine
+
+The membership changes are signed with the operator key of the genesis, see -operator-key.
+
+flags:
+`
+
+func main() {
+	addr := flag.String("addr", "localhost:7071", "address of the node's admin server")
+	asJSON := flag.Bool("json", false, "print the status as JSON")
+	timeout := flag.Duration("timeout", 5*time.Second, "RPC timeout")
+	learner := flag.Bool("learner", false, "add-peer and replace-peer add a learner, which replicates the store without voting")
+	operatorKey := flag.String("operator-key", "operator.key", "keystore of the operator key, which signs the membership changes")
+	passphraseFile := flag.String("passphrase-file", "", "file whose first line is the passphrase of the operator keystore, $BEEFTEA_PASSPHRASE or a prompt if empty")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cc, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fail(err)
+	}
+	defer cc.Close()
+	client := types.NewAdminRPCClient(cc)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch flag.Arg(0) {
+	case "status":
+		res, err := client.Status(ctx, &types.StatusReq{})
+		if err != nil {
+			fail(err)
+		}
+		if *asJSON {
+			fmt.Println(protojson.Format(res))
+		} else {
+			printStatus(res)
+		}
+	case "set-behavior":
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		_, err := client.SetBehavior(ctx, &types.SetBehaviorReq{Spec: flag.Arg(1)})
+		if err != nil {
+			fail(err)
+		}
+	case "add-peer", "remove-peer", "replace-peer":
+		rc, err := parseReconfig(flag.Arg(0), flag.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			flag.Usage()
+			os.Exit(2)
+		}
+		rc.Learner = *learner
+		if err := sign(ctx, client, rc, *operatorKey, *passphraseFile); err != nil {
+			fail(err)
+		}
+		id := fmt.Sprintf("reconfig-%d", time.Now().UnixNano())
+		if _, err := client.Reconfigure(ctx, &types.ReconfigureReq{Id: id, Reconfig: rc}); err != nil {
+			fail(err)
+		}
+		fmt.Printf("submitted %s, see the pending reconfigs in the status\n", id)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func parseReconfig(cmd string, args []string) (*types.Reconfig, error) {
+	rc := &types.Reconfig{}
+	if cmd != "add-peer" {
+		if len(args) == 0 {
+			return nil, errors.New("missing peer index")
+		}
+		idx, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer index: %s", err.Error())
+		}
+		rc.Index = uint32(idx)
+		args = args[1:]
+	}
+	switch cmd {
+	case "remove-peer":
+		rc.Op = types.ReconfigOp_RECONFIG_OP_REMOVE
+		if len(args) != 0 {
+			return nil, errors.New("too many arguments")
+		}
+		return rc, nil
+	case "add-peer":
+		rc.Op = types.ReconfigOp_RECONFIG_OP_ADD
+	case "replace-peer":
+		rc.Op = types.ReconfigOp_RECONFIG_OP_REPLACE
+	}
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("expected a url, a public key and an optional weight")
+	}
+	rc.Url = args[0]
+	var err error
+	if rc.PublicKey, err = hex.DecodeString(args[1]); err != nil {
+		return nil, fmt.Errorf("invalid public key: %s", err.Error())
+	}
+	if len(args) == 3 {
+		if rc.Weight, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid weight: %s", err.Error())
+		}
+	}
+	return rc, nil
+}
+
+// sign signs rc with the operator key as the next membership change of the node's cluster
+func sign(ctx context.Context, client types.AdminRPCClient, rc *types.Reconfig, keyFile, passphraseFile string) error {
+	passphrase, err := keystore.ReadPassphrase(passphraseFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+	key, err := keystore.Load(keyFile, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to open the operator keystore %s: %s", keyFile, err.Error())
+	}
+	status, err := client.Status(ctx, &types.StatusReq{})
+	if err != nil {
+		return err
+	}
+	rc.Seq = status.ReconfigSeq
+	rc.Sign(key, status.ChainHash)
+	return nil
+}
+
+func printStatus(res *types.StatusRes) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "node\t%d\n", res.NodeIndex)
+	if res.ChainId != "" {
+		fmt.Fprintf(w, "chain\t%s (%x)\n", res.ChainId, res.ChainHash)
+	}
+	fmt.Fprintf(w, "round\t%d\n", res.Round)
+	fmt.Fprintf(w, "seed\t%x\n", res.Seed)
+	if res.LastCommittedRound != nil {
+		fmt.Fprintf(w, "last committed round\t%d\n", res.GetLastCommittedRound())
+	} else {
+		fmt.Fprintf(w, "last committed round\tnone\n")
+	}
+	fmt.Fprintf(w, "proposals\t%d\n", res.NumProposals)
+	if p := res.MinProposal; p != nil {
+		fmt.Fprintf(w, "min proposal\t%x (node %d, score %d, %d reqs)\n", p.Digest, p.ProposerIndex, p.Score, p.NumReqs)
+	} else {
+		fmt.Fprintf(w, "min proposal\tnone\n")
+	}
+	fmt.Fprintf(w, "prepared\t%t\n", res.Prepared)
+	for _, tally := range res.Prepares {
+		fmt.Fprintf(w, "\t%x: %s\n", tally.Digest, voters(tally.Voters))
+	}
+	fmt.Fprintf(w, "committed\t%t\n", res.Committed)
+	for _, tally := range res.Commits {
+		fmt.Fprintf(w, "\t%x: %s\n", tally.Digest, voters(tally.Voters))
+	}
+	fmt.Fprintf(w, "mempool\t%d reqs\n", res.MempoolSize)
+	b := res.FutureBuffer
+	fmt.Fprintf(w, "future buffer\t%d buffered, %d released, %d dropped, %d expired\n",
+		b.GetBuffered(), b.GetReleased(), b.GetDropped(), b.GetExpired())
+	for _, peer := range res.Peers {
+		if peer.Removed {
+			fmt.Fprintf(w, "peer %d\t%s\tremoved\n", peer.Index, peer.Url)
+			continue
+		}
+		if peer.Learner {
+			fmt.Fprintf(w, "peer %d\t%s\t%s, learner\n", peer.Index, peer.Url, peer.State)
+			continue
+		}
+		fmt.Fprintf(w, "peer %d\t%s\t%s, weight %d\n", peer.Index, peer.Url, peer.State, peer.Weight)
+	}
+	for _, p := range res.PendingReconfigs {
+		rc := p.Reconfig
+		switch rc.Op {
+		case types.ReconfigOp_RECONFIG_OP_ADD:
+			fmt.Fprintf(w, "pending\tadd %s in round %d\n", rc.Url, p.Round)
+		case types.ReconfigOp_RECONFIG_OP_REMOVE:
+			fmt.Fprintf(w, "pending\tremove peer %d in round %d\n", rc.Index, p.Round)
+		case types.ReconfigOp_RECONFIG_OP_REPLACE:
+			fmt.Fprintf(w, "pending\treplace peer %d with %s in round %d\n", rc.Index, rc.Url, p.Round)
+		}
+	}
+}
+
+func voters(indices []uint32) string {
+	strs := make([]string, len(indices))
+	for i, idx := range indices {
+		strs[i] = fmt.Sprint(idx)
+	}
+	return fmt.Sprintf("%d votes from [%s]", len(indices), strings.Join(strs, " "))
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/patrickmao1/beeftea/audit"
+)
+
+const usage = `usage: audit [flags] <log file>...
+
+Reads the audit logs written by the nodes (Config.AuditLogFile) and prints the rounds in which
+the replicas diverged. Exits with status 1 if there are safety divergences, i.e. nodes applied
+different proposals in the same round or a node equivocated.
+
+flags:
+`
+
+func main() {
+	quiet := flag.Bool("quiet", false, "only print safety divergences")
+	asJSON := flag.Bool("json", false, "print the divergences as JSON lines")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var rounds []*audit.Round
+	for _, file := range flag.Args() {
+		rs, err := audit.ReadFile(file)
+		if err != nil {
+			fail(err)
+		}
+		rounds = append(rounds, rs...)
+	}
+
+	unsafe := false
+	enc := json.NewEncoder(os.Stdout)
+	for _, d := range audit.Compare(rounds) {
+		if d.Severity == audit.Safety {
+			unsafe = true
+		} else if *quiet {
+			continue
+		}
+		if *asJSON {
+			if err := enc.Encode(d); err != nil {
+				fail(err)
+			}
+		} else {
+			fmt.Println(d)
+		}
+	}
+	if unsafe {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/patrickmao1/beeftea/client"
+	"github.com/patrickmao1/beeftea/genesis"
+	"github.com/patrickmao1/beeftea/testnet/ports"
+	"github.com/patrickmao1/beeftea/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const usage = `usage: beeftea-cli [flags] <command>
+
+Talks to the whole cluster, sending writes to any node that takes them and taking the values
+that f+1 nodes agree on, or to a single node with -node or a single -addr.
+
+commands:
+  put <key> <value>  write a value, printing the request id
+  get <key>          print the value of a key, empty if it isn't set
+  delete <key>       delete a key, printing the request id
+  scan [prefix]      print the keys that start with prefix and their values
+  watch [prefix]     print the writes and deletes committed from now on to the keys that start
+                     with prefix, until interrupted
+  status             print the round, last commit, mempool and connected peers of each node of
+                     -admin-addr
+
+flags:
+`
+
+func main() {
+	addrs := flag.String("addr", strings.Join(ports.ClientURLs(5), ","),
+		"comma separated addresses of the nodes' external RPC servers")
+	adminAddrs := flag.String("admin-addr", strings.Join(ports.AdminURLs(5), ","),
+		"comma separated addresses of the nodes' admin servers, which status queries")
+	config := flag.String("config", "", "node config or genesis file of the cluster, whose peers' rpc_url replace -addr")
+	only := flag.Int("node", -1, "only talk to node i of -config or -addr, and of -admin-addr")
+	asJSON := flag.Bool("json", false, "print JSON lines instead of tables")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of the command, except watch")
+	id := flag.String("id", "", "request id of put and delete, random if empty")
+	limit := flag.Uint("limit", 0, "print the first limit keys of scan only, all of them if 0")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	cmd, args := flag.Arg(0), flag.Args()[min(1, flag.NArg()):]
+
+	nodes, err := loadNodes(*config, *addrs, *only)
+	if err != nil {
+		fail(err)
+	}
+	cl, err := client.New(client.Config{Nodes: nodes, Timeout: *timeout})
+	if err != nil {
+		fail(err)
+	}
+	defer cl.Close()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if cmd != "watch" {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	switch {
+	case cmd == "put" && len(args) == 2:
+		err = submit(ctx, cl, &types.PutReq{Id: *id, Kv: &types.KeyValue{Key: args[0], Val: args[1]}}, *asJSON)
+	case cmd == "delete" && len(args) == 1:
+		err = submit(ctx, cl, &types.PutReq{Id: *id, Kv: &types.KeyValue{Key: args[0]}, Delete: true}, *asJSON)
+	case cmd == "get" && len(args) == 1:
+		var val string
+		if val, err = cl.Get(ctx, args[0]); err == nil {
+			if *asJSON {
+				printJSON(&types.KeyValue{Key: args[0], Val: val})
+			} else {
+				fmt.Println(val)
+			}
+		}
+	case cmd == "scan" && len(args) <= 1:
+		var res *types.ScanRes
+		if res, err = cl.Scan(ctx, strings.Join(args, ""), uint32(*limit)); err == nil {
+			printScan(res, *asJSON)
+		}
+	case cmd == "watch" && len(args) <= 1:
+		err = watch(ctx, cl, strings.Join(args, ""), *asJSON)
+	case cmd == "status" && len(args) == 0:
+		admins := strings.Split(*adminAddrs, ",")
+		if *only >= 0 && *only < len(admins) {
+			admins = admins[*only : *only+1]
+		}
+		status(ctx, admins, *asJSON)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// loadNodes returns the nodes of the cluster from the genesis of config, or at addrs if config
+// is empty, node only if it isn't -1
+func loadNodes(config, addrs string, node int) ([]*client.Node, error) {
+	var nodes []*client.Node
+	if config != "" {
+		doc, err := genesis.LoadClusterDoc(config)
+		if err != nil {
+			return nil, err
+		}
+		cc, err := client.ConfigFromGenesis(doc)
+		if err != nil {
+			return nil, err
+		}
+		nodes = cc.Nodes
+	} else {
+		for _, addr := range strings.Split(addrs, ",") {
+			nodes = append(nodes, &client.Node{Addr: strings.TrimSpace(addr)})
+		}
+	}
+	if node == -1 {
+		return nodes, nil
+	}
+	if node < 0 || node >= len(nodes) {
+		return nil, fmt.Errorf("no node %d among %d", node, len(nodes))
+	}
+	// a single node is trusted, even a learner
+	return []*client.Node{{Addr: nodes[node].Addr}}, nil
+}
+
+func submit(ctx context.Context, cl *client.Client, req *types.PutReq, asJSON bool) error {
+	if req.Id == "" {
+		req.Id = rand.Text()
+	}
+	if err := cl.Submit(ctx, req); err != nil {
+		return err
+	}
+	if asJSON {
+		printJSON(&types.PutRes{Id: req.Id})
+	} else {
+		fmt.Println(req.Id)
+	}
+	return nil
+}
+
+func printScan(res *types.ScanRes, asJSON bool) {
+	if asJSON {
+		for _, kv := range res.Kvs {
+			printJSON(kv)
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE")
+		for _, kv := range res.Kvs {
+			fmt.Fprintf(w, "%s\t%s\n", kv.Key, kv.Val)
+		}
+		w.Flush()
+	}
+	if res.Truncated {
+		fmt.Fprintf(os.Stderr, "only the first %d keys are shown\n", len(res.Kvs))
+	}
+}
+
+func watch(ctx context.Context, cl *client.Client, prefix string, asJSON bool) error {
+	err := cl.Watch(ctx, prefix, func(e *types.WatchEvent) error {
+		switch {
+		case asJSON:
+			printJSON(e)
+		case e.Deleted:
+			fmt.Printf("round %d\tdelete\t%s\n", e.Round, e.Kv.Key)
+		default:
+			fmt.Printf("round %d\tput\t%s\t%s\n", e.Round, e.Kv.Key, e.Kv.Val)
+		}
+		return nil
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// status prints the status of the node of each admin server address, or why it couldn't be fetched
+func status(ctx context.Context, addrs []string, asJSON bool) {
+	statuses := make([]*types.StatusRes, len(addrs))
+	errs := make([]error, len(addrs))
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i], errs[i] = nodeStatus(ctx, addr)
+		}()
+	}
+	wg.Wait()
+
+	if asJSON {
+		for i, addr := range addrs {
+			line := map[string]any{"addr": addr}
+			if errs[i] != nil {
+				line["error"] = errs[i].Error()
+			} else {
+				line["status"] = json.RawMessage(protojson.Format(statuses[i]))
+			}
+			bs, _ := json.Marshal(line)
+			fmt.Println(string(bs))
+		}
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "ADDR\tNODE\tROUND\tLAST COMMIT\tMEMPOOL\tPEERS")
+	for i, addr := range addrs {
+		s := statuses[i]
+		if errs[i] != nil {
+			fmt.Fprintf(w, "%s\t\t\t\t\t%s\n", addr, errs[i].Error())
+			continue
+		}
+		last := "none"
+		if s.LastCommittedRound != nil {
+			last = fmt.Sprint(s.GetLastCommittedRound())
+		}
+		ready, peers := 0, 0
+		for _, peer := range s.Peers {
+			if peer.Removed || peer.Index == s.NodeIndex {
+				continue
+			}
+			peers++
+			if peer.State == "READY" {
+				ready++
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%d\t%d/%d connected\n", addr, s.NodeIndex, s.Round, last, s.MempoolSize, ready, peers)
+	}
+}
+
+func nodeStatus(ctx context.Context, addr string) (*types.StatusRes, error) {
+	cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer cc.Close()
+	return types.NewAdminRPCClient(cc).Status(ctx, &types.StatusReq{})
+}
+
+func printJSON(m proto.Message) {
+	bs, err := protojson.Marshal(m)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println(string(bs))
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
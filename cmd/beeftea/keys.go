@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/keystore"
+	"github.com/patrickmao1/beeftea/types"
+)
+
+const keysUsage = `usage: beeftea keys <command> [flags] [keystore]
+
+commands:
+  gen     generate a key into a new keystore file
+  show    print the algorithm, public key and node ID of a keystore
+  export  print the peer entry of a keystore for the cluster config
+  rotate  replace the key of a keystore with a new one, keeping the old keystore as <keystore>.old
+
+The passphrase is read from the file given with -passphrase-file, else from $BEEFTEA_PASSPHRASE,
+or else from the first line of the standard input.
+`
+
+func keysMain(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, keysUsage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("keys "+args[0], flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, keysUsage+"\nflags:\n")
+		fs.PrintDefaults()
+	}
+	passphraseFile := fs.String("passphrase-file", "", "file whose first line is the passphrase")
+	var err error
+	switch args[0] {
+	case "gen":
+		out := fs.String("out", "node.key", "keystore file to create")
+		algName := fs.String("alg", "ed25519", "key algorithm, p256 or ed25519")
+		fs.Parse(args[1:])
+		err = genKey(*out, *algName, *passphraseFile)
+	case "show":
+		fs.Parse(args[1:])
+		err = showKey(keystoreArg(fs))
+	case "export":
+		url := fs.String("url", "", "address the node's consensus server is reachable at")
+		weight := fs.Uint64("weight", 0, "weight of the peer, 0 counts as 1")
+		learner := fs.Bool("learner", false, "the node replicates the store without voting")
+		rpcURL := fs.String("rpc-url", "", "address clients reach the node's external RPC server at")
+		fs.Parse(args[1:])
+		err = exportKey(keystoreArg(fs), *url, *rpcURL, *weight, *learner)
+	case "rotate":
+		algName := fs.String("alg", "", "algorithm of the new key, that of the old one if empty")
+		fs.Parse(args[1:])
+		err = rotateKey(keystoreArg(fs), *algName, *passphraseFile)
+	default:
+		fmt.Fprint(os.Stderr, keysUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func keystoreArg(fs *flag.FlagSet) string {
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return fs.Arg(0)
+}
+
+func genKey(out, algName, passphraseFile string) error {
+	alg, err := crypto.ParseAlgorithm(algName)
+	if err != nil {
+		return err
+	}
+	passphrase, err := keystore.ReadPassphrase(passphraseFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+	key := crypto.GenKeyAlg(alg)
+	if err := keystore.Save(out, key, passphrase); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s key %s to %s\n", alg, crypto.NodeID(key.PublicKey()), out)
+	return nil
+}
+
+func showKey(path string) error {
+	f, err := keystore.Read(path)
+	if err != nil {
+		return err
+	}
+	pub, err := f.Public()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("algorithm:  %s\npublic key: %s\nnode ID:    %s\n", pub.Algorithm(), f.PublicKey, crypto.NodeID(pub))
+	return nil
+}
+
+func exportKey(path, url, rpcURL string, weight uint64, learner bool) error {
+	if url == "" {
+		return errors.New("-url is required")
+	}
+	f, err := keystore.Read(path)
+	if err != nil {
+		return err
+	}
+	pub, err := f.Public()
+	if err != nil {
+		return err
+	}
+	entry := types.NewPeerEntry(url, pub, weight)
+	entry.Learner = learner
+	entry.RPCURL = rpcURL
+	bs, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bs))
+	return nil
+}
+
+func rotateKey(path, algName, passphraseFile string) error {
+	passphrase, err := keystore.ReadPassphrase(passphraseFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+	// make sure the passphrase opens the old keystore before replacing it
+	old, err := keystore.Load(path, passphrase)
+	if err != nil {
+		return err
+	}
+	alg := old.Algorithm()
+	if algName != "" {
+		if alg, err = crypto.ParseAlgorithm(algName); err != nil {
+			return err
+		}
+	}
+	backup := path + ".old"
+	if _, err := os.Stat(backup); err == nil {
+		return fmt.Errorf("%s exists, remove it first", backup)
+	}
+	if err := os.Rename(path, backup); err != nil {
+		return err
+	}
+	key := crypto.GenKeyAlg(alg)
+	if err := keystore.Save(path, key, passphrase); err != nil {
+		os.Rename(backup, path)
+		return err
+	}
+	fmt.Printf("rotated %s from %s to %s key %s, the old keystore is %s\n",
+		path, crypto.NodeID(old.PublicKey()), alg, crypto.NodeID(key.PublicKey()), backup)
+	fmt.Println("update the peer entry of the node in the cluster config, see `beeftea keys export`")
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -1,37 +1,133 @@
 package main
 
 import (
-	"math"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/patrickmao1/beeftea/beacon"
 	"github.com/patrickmao1/beeftea/consensus"
 	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/genesis"
 	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/blake2b"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "keys":
+			keysMain(os.Args[2:])
+			return
+		case "testnet":
+			testnetMain(os.Args[2:])
+			return
+		}
+	}
+	configPath := flag.String("config", "", "node config file, see `beeftea testnet`. Runs the dev cluster of compose.yaml if empty")
+	flag.Parse()
+
+	var config *types.Config
+	if *configPath != "" {
+		var doc *genesis.Doc
+		var err error
+		config, doc, err = genesis.LoadNode(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if wait := time.Until(doc.GenesisTime); wait > 0 {
+			log.Infof("waiting %s for the genesis time of %s", wait.Round(time.Second), doc.ChainID)
+			time.Sleep(wait)
+		}
+	} else {
+		config = devConfig()
+	}
+
+	s := consensus.NewService(config)
+	if err := s.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	// docker stop sends SIGTERM
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	log.Infof("received %s, shutting down", <-sig)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		log.Errorf("unclean shutdown: %s", err.Error())
+	}
+}
+
+// how long the RPCs in flight have to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// devConfig returns the config of the dev cluster of compose.yaml, which has hard-coded keys and
+// finds which node it is from its IP
+func devConfig() *types.Config {
 	config := &types.Config{
-		InitTime:          time.Unix(1745816400, 0), // 2025-4-28 00:00:00
 		RoundDuration:     4 * time.Second,
 		ProposalDuration:  1 * time.Second,
-		ProposalThreshold: computeThreshold(5),
+		ProposalThreshold: consensus.ProposalThreshold(5),
+		Responsive:        os.Getenv("BEEFTEA_RESPONSIVE") != "",
+		AdaptiveTimeouts:  os.Getenv("BEEFTEA_ADAPTIVE_TIMEOUTS") != "",
+		MetricsListenAddr: "0.0.0.0:2112",
+		HTTPListenAddr:    "0.0.0.0:8180",
+		// compose.yaml only publishes it on the host's loopback
+		AdminListenAddr:     "0.0.0.0:7070",
+		AllowFaultInjection: os.Getenv("BEEFTEA_FAULT_INJECTION") != "",
+		OTLPEndpoint:        os.Getenv("BEEFTEA_OTLP_ENDPOINT"),
+		TraceFile:           os.Getenv("BEEFTEA_TRACE_FILE"),
+		AuditLogFile:        os.Getenv("BEEFTEA_AUDIT_LOG"),
 		Peers: []*types.Peer{
-			{URL: "172.16.0.1:9090", Key: crypto.UnmarshalHex("c71e183d51e9fae1d4fc410ca16a17a3a89da8e105b0e108576e2a77133f87b0")},
-			{URL: "172.16.0.2:9090", Key: crypto.UnmarshalHex("26c65dc72d016ebe50a5751c258d8ff3ddc3da40b5dcf7ac638619e041119b71")},
-			{URL: "172.16.0.3:9090", Key: crypto.UnmarshalHex("6a7e2b2ee79a8444d489c900f0c32bd944c88530882c9348b0d477b825773956")},
-			{URL: "172.16.0.4:9090", Key: crypto.UnmarshalHex("64d691d9af74ff28b23f38e49bedbae5aa5298933c477d60173a3990eb263481")},
-			{URL: "172.16.0.5:9090", Key: crypto.UnmarshalHex("376bb541ff3c913ea6b07cc0c405b991d354e140b4c3b8908884b84ef1475984")},
+			{URL: "172.16.0.1:9090", Key: devKey("c71e183d51e9fae1d4fc410ca16a17a3a89da8e105b0e108576e2a77133f87b0")},
+			{URL: "172.16.0.2:9090", Key: devKey("26c65dc72d016ebe50a5751c258d8ff3ddc3da40b5dcf7ac638619e041119b71")},
+			{URL: "172.16.0.3:9090", Key: devKey("6a7e2b2ee79a8444d489c900f0c32bd944c88530882c9348b0d477b825773956")},
+			{URL: "172.16.0.4:9090", Key: devKey("64d691d9af74ff28b23f38e49bedbae5aa5298933c477d60173a3990eb263481")},
+			{URL: "172.16.0.5:9090", Key: devKey("376bb541ff3c913ea6b07cc0c405b991d354e140b4c3b8908884b84ef1475984")},
 		},
 	}
 
-	s := consensus.NewService(config)
-	s.Start()
+	// BEEFTEA_KEY_ALG=ed25519 reads the hard-coded peer keys as Ed25519 seeds instead
+	if name := os.Getenv("BEEFTEA_KEY_ALG"); name != "" {
+		alg, err := crypto.ParseAlgorithm(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, peer := range config.Peers {
+			if peer.Key, err = crypto.Unmarshal(append([]byte{byte(alg)}, peer.Key.Bytes()...)); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	// Dev BLS keys for quorum certificates, derived from the peer keys
+	for _, peer := range config.Peers {
+		peer.BLSKey = crypto.BLSKeyFromSeed(crypto.Marshal(peer.Key))
+	}
+
+	// Dev beacon keys, dealt from a fixed seed as the peer keys above are hard-coded
+	xof, err := blake2b.NewXOF(blake2b.OutputLengthUnknown, []byte("beeftea dev beacon"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	beaconKeys, beaconSecrets, err := beacon.Deal(xof, len(config.Peers), beacon.Threshold(len(config.Peers)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.Beacon = beaconKeys
+	config.BeaconSecret = beaconSecrets[config.MyIndex()]
+	return config
 }
 
-func computeThreshold(n int) uint32 {
-	// T = f(N) such that the probability of no one proposing a block is 0.01
-	// f(N) = 1 - e^(-4.60517/N),
-	const constant = 4.60517
-	t := 1.0 - math.Exp(-constant/float64(n))
-	return uint32(float64(math.MaxUint32) * t)
+func devKey(hx string) crypto.PrivateKey {
+	key, err := crypto.UnmarshalHex(hx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return key
 }
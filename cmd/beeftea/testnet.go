@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/testnet"
+	"github.com/patrickmao1/beeftea/testnet/ports"
+)
+
+const testnetUsage = `usage: beeftea testnet [flags]
+
+Generates a testnet in a new directory: a keystore and a config file per node, the genesis file,
+and either a compose.yaml to run the nodes with docker compose or a run.sh that runs them as local
+processes. Run it from the root of the repository, where the nodes are built from.
+
+flags:
+`
+
+func testnetMain(args []string) {
+	fs := flag.NewFlagSet("testnet", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, testnetUsage)
+		fs.PrintDefaults()
+	}
+	nodes := fs.Int("nodes", 4, "number of nodes")
+	learners := fs.Int("learners", 0, "number of learner nodes, which replicate the store without voting")
+	out := fs.String("out", "testnet", "directory to create")
+	mode := fs.String("mode", testnet.Local, "local to run the nodes as processes, docker to run them with docker compose")
+	algName := fs.String("alg", "ed25519", "key algorithm, p256 or ed25519")
+	chainID := fs.String("chain-id", "beeftea-testnet", "chain id of the genesis")
+	startDelay := fs.Duration("start-delay", 10*time.Second, "how long after now the first round starts")
+	round := fs.Duration("round", 4*time.Second, "round duration")
+	proposal := fs.Duration("proposal", time.Second, "proposal phase duration")
+	responsive := fs.Bool("responsive", false, "run rounds back to back")
+	withBeacon := fs.Bool("beacon", false, "seed sortition with a threshold BLS randomness beacon")
+	faultInjection := fs.Bool("fault-injection", false, "let admin set-behavior make the nodes Byzantine")
+	stateFile := fs.String("state", "", "JSON object of the initial contents of the key-value store")
+	repo := fs.String("repo", ".", "path of the beeftea repository")
+	fs.Parse(args)
+
+	var state map[string]string
+	if *stateFile != "" {
+		bs, err := os.ReadFile(*stateFile)
+		if err != nil {
+			fail(err)
+		}
+		if err := json.Unmarshal(bs, &state); err != nil {
+			fail(fmt.Errorf("invalid initial state %s: %s", *stateFile, err.Error()))
+		}
+	}
+
+	alg, err := crypto.ParseAlgorithm(*algName)
+	if err != nil {
+		fail(err)
+	}
+	err = testnet.Generate(testnet.Options{
+		Nodes:            *nodes,
+		Learners:         *learners,
+		Out:              *out,
+		Mode:             *mode,
+		Algorithm:        alg,
+		ChainID:          *chainID,
+		StartDelay:       *startDelay,
+		RoundDuration:    *round,
+		ProposalDuration: *proposal,
+		Responsive:       *responsive,
+		Beacon:           *withBeacon,
+		FaultInjection:   *faultInjection,
+		InitialState:     state,
+		Repo:             *repo,
+	})
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("wrote a %d-node testnet to %s\n", *nodes+*learners, *out)
+	if *mode == testnet.Docker {
+		fmt.Printf("start it with: docker compose -f %s/compose.yaml up --build\n", *out)
+	} else {
+		fmt.Printf("start it with: %s/run.sh\n", *out)
+	}
+	fmt.Printf("run the docker tests against it with: BEEFTEA_TEST_URLS=%s go test ./tests/beeftea\n",
+		strings.Join(ports.ClientURLs(*nodes), ","))
+}
@@ -0,0 +1,107 @@
+package consensus
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/patrickmao1/beeftea/fault"
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetBehavior injects the Byzantine behavior described by req.Spec into the node, if
+// Config.AllowFaultInjection is set
+func (s *Service) SetBehavior(ctx context.Context, req *types.SetBehaviorReq) (*types.Empty, error) {
+	if !s.AllowFaultInjection {
+		return nil, status.Error(codes.PermissionDenied, "fault injection isn't allowed on this node")
+	}
+	b, err := fault.Parse(req.Spec)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Warnf("injecting Byzantine behavior \"%s\"", req.Spec)
+	s.InjectBehavior(b)
+	return &types.Empty{}, nil
+}
+
+// Status reports what the node is currently doing
+func (s *Service) Status(ctx context.Context, req *types.StatusReq) (*types.StatusRes, error) {
+	bufferStats := s.FutureBufferStats()
+	peerStates := s.PeerStates()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := &types.StatusRes{
+		NodeIndex:          s.MyIndex(),
+		MempoolSize:        uint32(len(s.reqs)),
+		LastCommittedRound: s.lastCommittedRound,
+		LastCommitQc:       s.lastCommitQC,
+		ChainId:            s.ChainID,
+		ChainHash:          s.ChainHash,
+		PendingReconfigs:   slices.Clone(s.pendingReconfigs),
+		ReconfigSeq:        s.reconfigSeq,
+		FutureBuffer: &types.FutureBufferStats{
+			Buffered: uint32(bufferStats.Buffered),
+			Released: bufferStats.Released,
+			Dropped:  bufferStats.Dropped,
+			Expired:  bufferStats.Expired,
+		},
+	}
+	for i, peer := range s.Peers {
+		state := "UNKNOWN"
+		if i < len(peerStates) {
+			state = peerStates[i].String()
+		}
+		res.Peers = append(res.Peers, &types.PeerStatus{
+			Index:   uint32(i),
+			Url:     peer.URL,
+			State:   state,
+			Weight:  s.Weight(uint32(i)),
+			Removed: peer.Removed,
+			Learner: peer.Learner,
+		})
+	}
+	if s.roundState == nil {
+		return res, nil
+	}
+	res.Round = s.roundState.round
+	res.Seed = s.roundState.seed
+	res.NumProposals = uint32(len(s.roundState.proposals))
+	res.Prepared = s.roundState.prepared
+	res.Committed = s.roundState.committed
+	res.Prepares = tallies(s.roundState.prepares)
+	res.Commits = tallies(s.roundState.commits)
+	if p := s.roundState.minProposal; p != nil {
+		res.MinProposal = &types.ProposalSummary{
+			Digest:        p.Hash(),
+			ProposerIndex: p.ProposerIndex,
+			Score:         p.Score(),
+			NumReqs:       uint32(len(p.Reqs)),
+		}
+	}
+	return res, nil
+}
+
+// tallies lists the voters of each digest, most voted digest first
+func tallies(votes map[string]map[uint32]bool) []*types.VoteTally {
+	var res []*types.VoteTally
+	for digest, voters := range votes {
+		tally := &types.VoteTally{Digest: []byte(digest)}
+		for voter := range voters {
+			tally.Voters = append(tally.Voters, voter)
+		}
+		slices.Sort(tally.Voters)
+		res = append(res, tally)
+	}
+	slices.SortFunc(res, func(a, b *types.VoteTally) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Voters), len(a.Voters)),
+			slices.Compare(a.Digest, b.Digest),
+		)
+	})
+	return res
+}
@@ -0,0 +1,55 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"slices"
+
+	"github.com/patrickmao1/beeftea/audit"
+)
+
+// auditEntry summarizes the decisions made in the round for the audit log of node me
+func (r *roundState) auditEntry(me uint32) *audit.Round {
+	entry := &audit.Round{
+		Node:     me,
+		Round:    r.round,
+		Seed:     hex.EncodeToString(r.seed),
+		Prepares: auditVotes(r.prepares),
+		Commits:  auditVotes(r.commits),
+		Applied:  hex.EncodeToString(r.applied),
+	}
+	for _, p := range r.proposals {
+		entry.Proposals = append(entry.Proposals, audit.Proposal{
+			Digest:   hex.EncodeToString(p.Hash()),
+			Proposer: p.ProposerIndex,
+			Score:    p.Score(),
+			NumReqs:  len(p.Reqs),
+		})
+	}
+	if r.minProposal != nil {
+		entry.MinProposal = hex.EncodeToString(r.minProposal.Hash())
+	}
+	for digest, voters := range r.prepares {
+		if voters[me] {
+			entry.Prepared = hex.EncodeToString([]byte(digest))
+		}
+	}
+	for digest, voters := range r.commits {
+		if voters[me] {
+			entry.Committed = hex.EncodeToString([]byte(digest))
+		}
+	}
+	return entry
+}
+
+func auditVotes(votes map[string]map[uint32]bool) map[string][]uint32 {
+	res := make(map[string][]uint32, len(votes))
+	for digest, voters := range votes {
+		var nodes []uint32
+		for voter := range voters {
+			nodes = append(nodes, voter)
+		}
+		slices.Sort(nodes)
+		res[hex.EncodeToString([]byte(digest))] = nodes
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+package consensus
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// beacon shares are accepted for rounds at most this far ahead of mine
+const beaconRoundsAhead = 16
+
+// sendBeaconShares broadcasts my partial signatures of the beacon values of round, unless I
+// already have it, and of the next round, so that the next seed is ready by the time the nodes
+// get there
+func (s *Service) sendBeaconShares(round uint32) {
+	if s.beacon == nil {
+		return
+	}
+	for _, r := range []uint32{round, round + 1} {
+		if _, ok := s.beacon.Value(r); ok {
+			continue
+		}
+		msg := &types.Message{Round: r, Type: &types.Message_BeaconShare{BeaconShare: &types.BeaconShare{
+			PartialSig: s.beacon.Sign(r),
+		}}}
+		s.broadcast(context.Background(), msg)
+	}
+}
+
+func (s *Service) handleBeaconShare(share *types.BeaconShare, round, nodeIdx uint32) error {
+	if s.beacon == nil {
+		return nil
+	}
+	s.mu.RLock()
+	current := uint32(0)
+	if s.roundState != nil {
+		current = s.roundState.round
+	}
+	s.mu.RUnlock()
+	if round < current {
+		// I'm done with the round, the share came too late to matter
+		return nil
+	}
+	if round > current+beaconRoundsAhead {
+		return fmt.Errorf("dropping beacon share for round %d from node %d (current round %d)", round, nodeIdx, current)
+	}
+	// err may name bad shares even though the value was recovered from the others
+	sig, err := s.beacon.AddPartial(round, nodeIdx, share.PartialSig)
+	if sig == nil {
+		return err
+	}
+	log.Infof("recovered the beacon value of round %d", round)
+
+	s.mu.Lock()
+	propose := s.setBeaconSeed(round, sig)
+	s.mu.Unlock()
+	if propose {
+		s.propose()
+	}
+	return err
+}
+
+// setBeaconSeed sets the seed of the round I'm in from its beacon value if I was waiting for it,
+// and tells whether I can propose now. Must be called with s.mu held.
+func (s *Service) setBeaconSeed(round uint32, sig []byte) bool {
+	if s.roundState == nil || s.roundState.round != round || s.roundState.seed != nil {
+		return false
+	}
+	s.roundState.seed = beaconSeed(round, sig)
+	return true
+}
+
+// beaconSeed computes s_r = r | HASH(beacon value of r), in the same format as computeRoundSeed
+func beaconSeed(round uint32, sig []byte) []byte {
+	return slices.Concat(binary.BigEndian.AppendUint32(nil, round), beacon.Randomness(sig))
+}
+
+func (s *Service) GetBeacon(ctx context.Context, req *types.GetBeaconReq) (*types.GetBeaconRes, error) {
+	if s.beacon == nil {
+		return nil, status.Error(codes.Unavailable, "the node doesn't run a randomness beacon")
+	}
+	var round uint32
+	var sig []byte
+	var ok bool
+	if req.Round != nil {
+		round = req.GetRound()
+		sig, ok = s.beacon.Value(round)
+	} else {
+		round, sig, ok = s.beacon.Latest()
+	}
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no beacon value for round %d", round)
+	}
+	return &types.GetBeaconRes{Round: round, Signature: sig, Randomness: beacon.Randomness(sig)}, nil
+}
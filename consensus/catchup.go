@@ -0,0 +1,76 @@
+package consensus
+
+import (
+	"context"
+
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/blake2b"
+	"google.golang.org/protobuf/proto"
+)
+
+// A node that fell behind may be asked to move more than one round ahead, by the messages of the
+// others or by a timeout certificate. The proposals committed in the rounds in between never
+// reached it, so instead of skipping them it takes the state of the cluster from the snapshots
+// of its peers first, see syncState.
+
+// syncTimeout is how many round durations a node waits for the snapshots of its peers
+const syncTimeout = 2
+
+// syncState takes the state from the snapshots of the other validators, the one that validators
+// of more than 1/3 of the weight agree on so that at least one of them is honest, and returns the
+// round it is at the start of. It returns false if they don't agree or if I'm as far as they are,
+// and I then stay in my round.
+func (s *Service) syncState() (uint32, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout*s.RoundDuration)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	snaps := s.Network.Snapshots(ctx, s.snapshotReq(true))
+
+	// the snapshots taken at the same point of the same rounds are equal
+	votes := make(map[[32]byte][]uint32)
+	agreed := make(map[[32]byte]*types.Snapshot)
+	for node, snap := range snaps {
+		digest, err := snapshotDigest(snap)
+		if err != nil {
+			log.Warnf("bad snapshot from peer %d: %s", node, err.Error())
+			continue
+		}
+		votes[digest] = append(votes[digest], node)
+		agreed[digest] = snap
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for digest, nodes := range votes {
+		snap := agreed[digest]
+		if !s.oneHonest(nodes) || snap.Round <= s.roundState.round {
+			continue
+		}
+		if err := s.restore(snap); err != nil {
+			log.Errorf("failed to restore the snapshot of peers %v: %s", nodes, err.Error())
+			break
+		}
+		log.Warnf("caught up with peers %v at round %d", nodes, snap.Round)
+		s.metrics.StateSyncs.WithLabelValues("synced").Inc()
+		return snap.Round, true
+	}
+	s.metrics.StateSyncs.WithLabelValues("failed").Inc()
+	log.Warnf("round %d: %d peers sent snapshots, but not enough of them agree on one ahead of me",
+		s.roundState.round, len(snaps))
+	return 0, false
+}
+
+func snapshotDigest(snap *types.Snapshot) ([32]byte, error) {
+	bs, err := proto.MarshalOptions{Deterministic: true}.Marshal(snap)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return blake2b.Sum256(bs), nil
+}
@@ -0,0 +1,126 @@
+package consensus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// evidenceRounds is how many rounds before the current one proofs of equivocation are accepted
+// and kept for, which bounds the evidence held against each node
+const evidenceRounds = 100
+
+// checkEquivocation records e as the sender's vote in votes. If the sender has already voted for
+// a different digest in this round, a proof containing both signed votes is stored and gossiped
+// to the other nodes, and an error is returned so that the second vote is not counted.
+// Must be called with s.mu held.
+func (s *Service) checkEquivocation(votes map[uint32]*types.Envelope, e *types.Envelope, digest []byte) error {
+	first, ok := votes[e.NodeIndex]
+	if !ok {
+		votes[e.NodeIndex] = e
+		return nil
+	}
+	firstDigest := voteDigest(first.Msg)
+	if bytes.Equal(firstDigest, digest) {
+		return nil
+	}
+
+	s.addEvidence(&types.EquivocationProof{First: first, Second: e})
+	return fmt.Errorf("node %d equivocated in round %d: voted for %x and %x",
+		e.NodeIndex, e.Msg.Round, firstDigest, digest)
+}
+
+// handleEquivocation stores a proof gossiped by another node after checking it myself, and passes
+// it on if it's new to me
+func (s *Service) handleEquivocation(proof *types.EquivocationProof, nodeIdx uint32) error {
+	s.mu.RLock()
+	if s.roundState == nil {
+		s.mu.RUnlock()
+		return fmt.Errorf("equivocation proof from node %d arrived before the first round started", nodeIdx)
+	}
+	round := s.roundState.round
+	s.mu.RUnlock()
+	if vote := proof.GetFirst().GetMsg(); vote != nil && (vote.Round > round || vote.Round+evidenceRounds < round) {
+		return fmt.Errorf("equivocation proof from node %d is for round %d, I'm in round %d", nodeIdx, vote.Round, round)
+	}
+	err := s.validateEquivocation(proof)
+	if err != nil {
+		return fmt.Errorf("invalid equivocation proof from node %d: %s", nodeIdx, err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addEvidence(proof)
+	return nil
+}
+
+func (s *Service) validateEquivocation(proof *types.EquivocationProof) error {
+	first, second := proof.GetFirst(), proof.GetSecond()
+	if first.GetMsg() == nil || second.GetMsg() == nil {
+		return errors.New("missing vote")
+	}
+	if first.NodeIndex != second.NodeIndex {
+		return fmt.Errorf("votes from different nodes %d and %d", first.NodeIndex, second.NodeIndex)
+	}
+	if first.Msg.Round != second.Msg.Round {
+		return fmt.Errorf("votes from different rounds %d and %d", first.Msg.Round, second.Msg.Round)
+	}
+	if votePhase(first.Msg) == "" || votePhase(first.Msg) != votePhase(second.Msg) {
+		return fmt.Errorf("votes of different types %T and %T", first.Msg.Type, second.Msg.Type)
+	}
+	if bytes.Equal(voteDigest(first.Msg), voteDigest(second.Msg)) {
+		return errors.New("votes are for the same digest")
+	}
+	return s.VerifyEnvelopes(first, second)
+}
+
+// addEvidence stores proof and gossips it to the other nodes, unless there already is a proof of
+// the same misbehavior. Must be called with s.mu held.
+func (s *Service) addEvidence(proof *types.EquivocationProof) {
+	vote := proof.First
+	key := fmt.Sprintf("%d/%d/%s", vote.NodeIndex, vote.Msg.Round, votePhase(vote.Msg))
+	if _, exists := s.evidence[key]; exists {
+		return
+	}
+	s.evidence[key] = proof
+	log.Warnf("recorded equivocation evidence against node %d in round %d (%s)",
+		vote.NodeIndex, vote.Msg.Round, votePhase(vote.Msg))
+	msg := &types.Message{Round: s.roundState.round, Type: &types.Message_Equivocation{
+		Equivocation: proof,
+	}}
+	s.broadcast(s.roundState.phaseCtx(), msg)
+}
+
+// pruneEvidence drops the proofs that are more than evidenceRounds rounds old.
+// Must be called with s.mu held.
+func (s *Service) pruneEvidence(round uint32) {
+	for key, proof := range s.evidence {
+		if proof.First.Msg.Round+evidenceRounds < round {
+			delete(s.evidence, key)
+		}
+	}
+}
+
+func votePhase(msg *types.Message) string {
+	switch msg.Type.(type) {
+	case *types.Message_Prepare:
+		return "prepare"
+	case *types.Message_Commit:
+		return "commit"
+	default:
+		return ""
+	}
+}
+
+func voteDigest(msg *types.Message) []byte {
+	switch msg.Type.(type) {
+	case *types.Message_Prepare:
+		return msg.GetPrepare().ProposalDigest
+	case *types.Message_Commit:
+		return msg.GetCommit().ProposalDigest
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/patrickmao1/beeftea/clock"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/fault"
+	"github.com/patrickmao1/beeftea/metrics"
+	"github.com/patrickmao1/beeftea/network"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/crypto/blake2b"
+)
+
+// testOperator signs the membership changes of the test services
+var testOperator = crypto.GenKey()
+
+func newTestService(t *testing.T, n int) *Service {
+	config := &types.Config{OperatorKey: testOperator.PublicKey()}
+	for i := 0; i < n; i++ {
+		config.Peers = append(config.Peers, &types.Peer{Key: crypto.GenKey()})
+	}
+	config.SetMyIndex(0)
+	s := &Service{
+		Config:        config,
+		reqs:          make(map[string]*types.PutReq),
+		mempoolSpans:  make(map[string]trace.Span),
+		db:            make(map[string]string),
+		writes:        make(map[string]*commitProof),
+		committedIDs:  make(map[string]uint32),
+		watchers:      make(map[*watcher]bool),
+		evidence:      make(map[string]*types.EquivocationProof),
+		metrics:       metrics.New(),
+		tracer:        noop.NewTracerProvider().Tracer(""),
+		pacer:         newPacer(false, 0, 0, 0, 0),
+		clock:         clock.Real{},
+		timeoutRounds: make(map[uint32]uint32),
+	}
+	s.Network = network.NewNetwork(0, config.Peers[0].Key, nil, config.Peers, s.handleMessage, s.metrics)
+	return s
+}
+
+func signedVote(t *testing.T, s *Service, nodeIdx, round uint32, commit bool, digest []byte) *types.Envelope {
+	msg := &types.Message{Round: round}
+	if commit {
+		msg.Type = &types.Message_Commit{Commit: &types.Commit{ProposalDigest: digest}}
+	} else {
+		msg.Type = &types.Message_Prepare{Prepare: &types.Prepare{ProposalDigest: digest}}
+	}
+	bs, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return &types.Envelope{Msg: msg, NodeIndex: nodeIdx, Sig: crypto.Sign(s.Peers[nodeIdx].Key, bs)}
+}
+
+func TestEquivocationProof(t *testing.T) {
+	s := newTestService(t, 4)
+	a, b := blake2b.Sum256([]byte("a")), blake2b.Sum256([]byte("b"))
+
+	first := signedVote(t, s, 2, 7, false, a[:])
+	second := signedVote(t, s, 2, 7, false, b[:])
+	require.NoError(t, s.validateEquivocation(&types.EquivocationProof{First: first, Second: second}))
+
+	// same digest twice is not equivocation
+	again := signedVote(t, s, 2, 7, false, a[:])
+	require.Error(t, s.validateEquivocation(&types.EquivocationProof{First: first, Second: again}))
+	// a prepare and a commit for different digests is allowed
+	commit := signedVote(t, s, 2, 7, true, b[:])
+	require.Error(t, s.validateEquivocation(&types.EquivocationProof{First: first, Second: commit}))
+	// different rounds
+	later := signedVote(t, s, 2, 8, false, b[:])
+	require.Error(t, s.validateEquivocation(&types.EquivocationProof{First: first, Second: later}))
+	// different nodes
+	other := signedVote(t, s, 3, 7, false, b[:])
+	require.Error(t, s.validateEquivocation(&types.EquivocationProof{First: first, Second: other}))
+	// forged signature
+	forged := signedVote(t, s, 3, 7, false, b[:])
+	forged.NodeIndex = 2
+	require.Error(t, s.validateEquivocation(&types.EquivocationProof{First: first, Second: forged}))
+}
+
+func TestCheckEquivocation(t *testing.T) {
+	s := newTestService(t, 4)
+	s.roundState = &roundState{round: 7}
+	votes := make(map[uint32]*types.Envelope)
+	a, b := blake2b.Sum256([]byte("a")), blake2b.Sum256([]byte("b"))
+
+	require.NoError(t, s.checkEquivocation(votes, signedVote(t, s, 2, 7, false, a[:]), a[:]))
+	require.NoError(t, s.checkEquivocation(votes, signedVote(t, s, 2, 7, false, a[:]), a[:]))
+	require.Empty(t, s.evidence)
+
+	require.Error(t, s.checkEquivocation(votes, signedVote(t, s, 2, 7, false, b[:]), b[:]))
+	require.Len(t, s.evidence, 1)
+	res, err := s.ListEvidence(t.Context(), &types.ListEvidenceReq{NodeIndex: proto.Uint32(2)})
+	require.NoError(t, err)
+	require.Len(t, res.Proofs, 1)
+	require.NoError(t, s.validateEquivocation(res.Proofs[0]))
+
+	// repeated equivocation in the same round and phase is only recorded once
+	c := blake2b.Sum256([]byte("c"))
+	require.Error(t, s.checkEquivocation(votes, signedVote(t, s, 2, 7, false, c[:]), c[:]))
+	require.Len(t, s.evidence, 1)
+
+	res, err = s.ListEvidence(t.Context(), &types.ListEvidenceReq{NodeIndex: proto.Uint32(1)})
+	require.NoError(t, err)
+	require.Empty(t, res.Proofs)
+}
+
+// recorder keeps what the node broadcasts instead of sending it
+type recorder struct {
+	fault.Honest
+	sent []*types.Message
+}
+
+func (r *recorder) OnSend(msg *types.Message, _ int) []fault.Outgoing {
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func TestHandleEquivocation(t *testing.T) {
+	s := newTestService(t, 4)
+	s.roundState = &roundState{round: 200}
+	sent := &recorder{}
+	s.InjectBehavior(sent)
+	a, b := blake2b.Sum256([]byte("a")), blake2b.Sum256([]byte("b"))
+	proof := func(round uint32) *types.EquivocationProof {
+		return &types.EquivocationProof{
+			First:  signedVote(t, s, 2, round, false, a[:]),
+			Second: signedVote(t, s, 2, round, false, b[:]),
+		}
+	}
+
+	// proofs of rounds too old or not reached yet are dropped
+	require.Error(t, s.handleEquivocation(proof(200-evidenceRounds-1), 1))
+	require.Error(t, s.handleEquivocation(proof(201), 1))
+	require.Empty(t, s.evidence)
+	require.Empty(t, sent.sent)
+
+	// a new proof is passed on once
+	require.NoError(t, s.handleEquivocation(proof(150), 1))
+	require.NoError(t, s.handleEquivocation(proof(150), 3))
+	require.Len(t, s.evidence, 1)
+	require.Len(t, sent.sent, 1)
+	require.EqualValues(t, 150, sent.sent[0].GetEquivocation().First.Msg.Round)
+
+	// and dropped once it's too old
+	s.pruneEvidence(150 + evidenceRounds)
+	require.Len(t, s.evidence, 1)
+	s.pruneEvidence(150 + evidenceRounds + 1)
+	require.Empty(t, s.evidence)
+}
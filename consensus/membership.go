@@ -0,0 +1,445 @@
+package consensus
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// reconfigDelay is how many rounds after the round it is committed in a membership change takes
+// effect, so that the nodes a few rounds behind still apply it in the same round as the others
+const reconfigDelay = 4
+
+var errNotAPeer = errors.New("my key isn't a peer of the cluster yet")
+
+// how long a signed GetSnapshotReq is accepted for
+const snapshotReqTTL = time.Minute
+
+// Reconfigure submits a membership change. It is ordered through consensus like a write and
+// takes effect reconfigDelay rounds after it is committed.
+func (s *Service) Reconfigure(ctx context.Context, req *types.ReconfigureReq) (*types.PutRes, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing id")
+	}
+	if req.Reconfig == nil {
+		return nil, status.Error(codes.InvalidArgument, "no reconfig")
+	}
+	s.mu.RLock()
+	err := s.checkReconfig(req.Reconfig)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.submit(ctx, &types.PutReq{Id: req.Id, Reconfig: req.Reconfig})
+}
+
+// checkReconfig checks that the operator signed rc as the next change, and that it applies on
+// top of the current peers and the pending changes. Must be called with s.mu held.
+func (s *Service) checkReconfig(rc *types.Reconfig) error {
+	if err := s.reconfigurable(); err != nil {
+		return err
+	}
+	if err := rc.VerifyOperator(s.OperatorKey, s.ChainHash); err != nil {
+		return err
+	}
+	if rc.Seq != s.reconfigSeq {
+		return fmt.Errorf("seq %d isn't the one of the next membership change, %d", rc.Seq, s.reconfigSeq)
+	}
+	_, err := applyReconfig(s.futurePeers(), rc)
+	return err
+}
+
+// reconfigurable tells why the peers can't change, if they can't: the keys of quorum
+// certificates and of the beacon are dealt for a fixed peer set
+func (s *Service) reconfigurable() error {
+	if s.blsKeys != nil {
+		return errors.New("membership changes aren't supported with BLS keys")
+	}
+	if s.beacon != nil {
+		return errors.New("membership changes aren't supported with a randomness beacon")
+	}
+	return nil
+}
+
+// scheduleReconfig schedules a membership change committed in round, if the operator signed it as
+// the next one: proposals with unsigned changes are rejected, and the seq keeps a Byzantine
+// proposer from replaying a signed one. Must be called with s.mu held.
+func (s *Service) scheduleReconfig(round uint32, rc *types.Reconfig) {
+	err := s.reconfigurable()
+	if err == nil {
+		err = rc.VerifyOperator(s.OperatorKey, s.ChainHash)
+	}
+	if err == nil && rc.Seq != s.reconfigSeq {
+		err = fmt.Errorf("seq %d, expected %d", rc.Seq, s.reconfigSeq)
+	}
+	if err != nil {
+		log.Errorf("round %d: ignoring committed %s: %s", round, describeReconfig(rc), err.Error())
+		return
+	}
+	s.reconfigSeq++
+	effective := round + reconfigDelay
+	s.pendingReconfigs = append(s.pendingReconfigs, &types.ScheduledReconfig{Round: effective, Reconfig: rc})
+	log.Infof("round %d: committed %s, taking effect in round %d", round, describeReconfig(rc), effective)
+}
+
+// applyReconfigs makes the membership changes due by round take effect and dials the new peers.
+// Must be called with s.mu held.
+func (s *Service) applyReconfigs(round uint32) {
+	peers, pending := advanceMembership(s.Peers, s.pendingReconfigs, round)
+	s.pendingReconfigs = pending
+	if slices.Equal(peers, s.Peers) {
+		return
+	}
+	s.Peers = peers
+	s.Network.SetPeers(peers)
+	log.Infof("round %d: %d peers of total weight %d", round, len(peers), s.TotalWeight())
+	if !s.isMember() {
+		log.Warnf("round %d: I'm no longer a peer of the cluster", round)
+	}
+}
+
+// isMember tells whether I still hold my slot. Must be called with s.mu held.
+func (s *Service) isMember() bool {
+	me := s.Peers[s.MyIndex()]
+	return !me.Removed && me.Key != nil
+}
+
+// isLearner tells whether I only replicate the store, see types.Peer.Learner. Must be called
+// with s.mu held.
+func (s *Service) isLearner() bool {
+	return s.Peers[s.MyIndex()].Learner
+}
+
+// advanceMembership applies the changes of pending due by round to peers, in the order they were
+// committed, and returns the resulting peers and the changes still pending. Changes that don't
+// apply, e.g. removing a peer twice, are skipped by all nodes alike.
+func advanceMembership(peers []*types.Peer, pending []*types.ScheduledReconfig, round uint32) ([]*types.Peer, []*types.ScheduledReconfig) {
+	var rest []*types.ScheduledReconfig
+	for _, p := range pending {
+		if p.Round > round {
+			rest = append(rest, p)
+			continue
+		}
+		next, err := applyReconfig(peers, p.Reconfig)
+		if err != nil {
+			log.Errorf("round %d: skipping %s: %s", p.Round, describeReconfig(p.Reconfig), err.Error())
+			continue
+		}
+		log.Warnf("round %d: %s", p.Round, describeReconfig(p.Reconfig))
+		peers = next
+	}
+	return peers, rest
+}
+
+// applyReconfig returns the peers after rc. peers itself isn't modified, as it is shared with the
+// network.
+func applyReconfig(peers []*types.Peer, rc *types.Reconfig) ([]*types.Peer, error) {
+	next := slices.Clone(peers)
+	switch rc.Op {
+	case types.ReconfigOp_RECONFIG_OP_ADD:
+		peer, err := newPeer(next, rc)
+		if err != nil {
+			return nil, err
+		}
+		return append(next, peer), nil
+	case types.ReconfigOp_RECONFIG_OP_REMOVE:
+		if err := checkSlot(next, rc.Index); err != nil {
+			return nil, err
+		}
+		if next[rc.Index].IsValidator() && validators(next) == 1 {
+			return nil, errors.New("can't remove the last validator")
+		}
+		removed := *next[rc.Index]
+		removed.Removed = true
+		next[rc.Index] = &removed
+		return next, nil
+	case types.ReconfigOp_RECONFIG_OP_REPLACE:
+		if err := checkSlot(next, rc.Index); err != nil {
+			return nil, err
+		}
+		// the old key may be the new one, e.g. to move a node to another address
+		next[rc.Index] = &types.Peer{Removed: true}
+		peer, err := newPeer(next, rc)
+		if err != nil {
+			return nil, err
+		}
+		if peer.Learner && peers[rc.Index].IsValidator() && validators(next) == 0 {
+			return nil, errors.New("can't replace the last validator with a learner")
+		}
+		next[rc.Index] = peer
+		return next, nil
+	}
+	return nil, fmt.Errorf("unknown reconfig op %d", rc.Op)
+}
+
+// newPeer decodes the peer added by rc, whose key must not be the key of another peer
+func newPeer(peers []*types.Peer, rc *types.Reconfig) (*types.Peer, error) {
+	if rc.Url == "" {
+		return nil, errors.New("empty peer url")
+	}
+	peer, err := types.NewPeer(&types.PeerInfo{Url: rc.Url, PublicKey: rc.PublicKey, Weight: rc.Weight, Learner: rc.Learner})
+	if err != nil {
+		return nil, err
+	}
+	if slices.IndexFunc(peers, func(p *types.Peer) bool { return !p.Removed && samePeerKey(p, rc.PublicKey) }) >= 0 {
+		return nil, fmt.Errorf("key %s is already a peer's", crypto.NodeID(peer.PublicKey))
+	}
+	return peer, nil
+}
+
+func checkSlot(peers []*types.Peer, idx uint32) error {
+	if int(idx) >= len(peers) {
+		return fmt.Errorf("no peer %d", idx)
+	}
+	if peers[idx].Removed {
+		return fmt.Errorf("peer %d was removed", idx)
+	}
+	return nil
+}
+
+func validators(peers []*types.Peer) int {
+	n := 0
+	for _, peer := range peers {
+		if peer.IsValidator() {
+			n++
+		}
+	}
+	return n
+}
+
+func samePeerKey(peer *types.Peer, pubkey []byte) bool {
+	return bytes.Equal(crypto.MarshalPublic(peer.PubKey()), pubkey)
+}
+
+func describeReconfig(rc *types.Reconfig) string {
+	kind := "peer"
+	if rc.Learner {
+		kind = "learner"
+	}
+	switch rc.Op {
+	case types.ReconfigOp_RECONFIG_OP_ADD:
+		return fmt.Sprintf("add %s at %s", kind, rc.Url)
+	case types.ReconfigOp_RECONFIG_OP_REMOVE:
+		return fmt.Sprintf("remove peer %d", rc.Index)
+	case types.ReconfigOp_RECONFIG_OP_REPLACE:
+		return fmt.Sprintf("replace peer %d with %s at %s", rc.Index, kind, rc.Url)
+	}
+	return fmt.Sprintf("reconfig op %d", rc.Op)
+}
+
+// serveSnapshot returns my state for a node to join from or catch up with, if it is or will be a
+// peer. Unless req.Now, it waits until a round is applied and returns the state at the start of
+// the next one, so that the joining node gets there before its proposals are sent, unless the
+// others move on as soon as they commit (responsive mode).
+func (s *Service) serveSnapshot(ctx context.Context, req *types.GetSnapshotReq) (*types.Snapshot, error) {
+	s.mu.RLock()
+	err := s.authorizeSnapshot(req)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	for {
+		s.mu.RLock()
+		if s.roundState != nil && (s.roundState.done || req.Now) {
+			snap := s.snapshot()
+			s.mu.RUnlock()
+			return snap, nil
+		}
+		s.mu.RUnlock()
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-s.stop:
+			return nil, status.Error(codes.Unavailable, "node is shutting down")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// authorizeSnapshot checks that req was signed in the last snapshotReqTTL by the key of a peer,
+// or of one a pending change adds, as the snapshot holds the whole store. Must be called with
+// s.mu held.
+func (s *Service) authorizeSnapshot(req *types.GetSnapshotReq) error {
+	if age := time.Since(time.UnixMilli(req.TimeMs)); age > snapshotReqTTL || age < -snapshotReqTTL {
+		return fmt.Errorf("request is %s old", age)
+	}
+	key, err := crypto.UnmarshalPublic(req.PublicKey)
+	if err != nil {
+		return err
+	}
+	if !crypto.Verify(key, req.SignedBytes(s.ChainHash), req.Sig) {
+		return errors.New("bad signature")
+	}
+	if !slices.ContainsFunc(s.futurePeers(), func(p *types.Peer) bool {
+		return !p.Removed && samePeerKey(p, req.PublicKey)
+	}) {
+		return errNotAPeer
+	}
+	return nil
+}
+
+// futurePeers returns the peers once the pending changes have taken effect. Must be called with
+// s.mu held.
+func (s *Service) futurePeers() []*types.Peer {
+	peers := s.Peers
+	for _, pending := range s.pendingReconfigs {
+		if next, err := applyReconfig(peers, pending.Reconfig); err == nil {
+			peers = next
+		}
+	}
+	return peers
+}
+
+// snapshot returns the state at the start of the round after the current one if it is done, or
+// at the start of the current one otherwise. Must be called with s.mu held.
+func (s *Service) snapshot() *types.Snapshot {
+	snap := &types.Snapshot{
+		Round:             s.roundState.round,
+		PrevProposerProof: s.roundState.prevProposerProof,
+		Db:                maps.Clone(s.db),
+		PendingReconfigs:  slices.Clone(s.pendingReconfigs),
+		ChainHash:         s.ChainHash,
+		ReconfigSeq:       s.reconfigSeq,
+		CommittedIds:      maps.Clone(s.committedIDs),
+	}
+	if s.roundState.done {
+		snap.Round++
+		if applied := s.roundState.appliedProposal(); applied != nil {
+			snap.PrevProposerProof = applied.ProposerProof
+		}
+	}
+	for _, peer := range s.Peers {
+		snap.Peers = append(snap.Peers, peer.Info())
+	}
+	return snap
+}
+
+// join takes the state and the peers of the cluster from the node whose consensus server is at
+// addr, retrying until a Reconfigure has given me a slot
+func (s *Service) join(addr string) {
+	for {
+		snap, err := s.fetchSnapshot(addr, 4*s.RoundDuration)
+		if err == nil {
+			if err = s.restore(snap); err == nil {
+				log.Infof("joined the cluster from %s as peer %d at round %d", addr, s.MyIndex(), snap.Round)
+				return
+			}
+		}
+		if !errors.Is(err, errNotAPeer) && status.Code(err) != codes.PermissionDenied {
+			log.Errorf("failed to join from %s: %s", addr, err.Error())
+		} else {
+			log.Infof("waiting for the cluster to add key %s", crypto.NodeID(s.MyKey().PublicKey()))
+		}
+		time.Sleep(s.RoundDuration)
+	}
+}
+
+func (s *Service) fetchSnapshot(addr string, timeout time.Duration) (*types.Snapshot, error) {
+	req := s.snapshotReq(false)
+	cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer cc.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return types.NewConsensusRPCClient(cc).GetSnapshot(ctx, req)
+}
+
+// snapshotReq returns a request for a snapshot signed with my key
+func (s *Service) snapshotReq(now bool) *types.GetSnapshotReq {
+	req := &types.GetSnapshotReq{PublicKey: crypto.MarshalPublic(s.MyKey().PublicKey()), TimeMs: time.Now().UnixMilli(), Now: now}
+	req.Sig = crypto.Sign(s.MyKey(), req.SignedBytes(s.ChainHash))
+	return req
+}
+
+// restore makes me start from snap in the slot of my key, when joining or catching up. The
+// watchers are told about the keys that changed as if they were written in the round before
+// snap's. Must be called with s.mu held once I'm running.
+func (s *Service) restore(snap *types.Snapshot) error {
+	if !bytes.Equal(snap.ChainHash, s.ChainHash) {
+		return fmt.Errorf("the node runs chain %x, not %x", snap.ChainHash, s.ChainHash)
+	}
+	var peers []*types.Peer
+	for _, info := range snap.Peers {
+		peer, err := types.NewPeer(info)
+		if err != nil {
+			return err
+		}
+		peers = append(peers, peer)
+	}
+	peers, pending := advanceMembership(peers, snap.PendingReconfigs, snap.Round)
+	key := s.MyKey()
+	me := slices.IndexFunc(peers, func(p *types.Peer) bool {
+		return !p.Removed && samePeerKey(p, crypto.MarshalPublic(key.PublicKey()))
+	})
+	if me < 0 {
+		return errNotAPeer
+	}
+	if s.roundState != nil && uint32(me) != s.MyIndex() {
+		// I'm running, and the network knows me by my index
+		return fmt.Errorf("the snapshot has me as peer %d, not %d", me, s.MyIndex())
+	}
+	peers[me].Key = key
+	s.Peers = peers
+	s.SetMyIndex(uint32(me))
+	if s.Network != nil {
+		s.Network.SetPeers(peers)
+	}
+
+	db := snap.Db
+	if db == nil {
+		db = make(map[string]string)
+	}
+	for key, val := range s.db {
+		if newVal, ok := db[key]; !ok {
+			s.notifyWatchers(&types.WatchEvent{Kv: &types.KeyValue{Key: key}, Round: snap.Round - 1, Deleted: true})
+			delete(s.writes, key)
+		} else if newVal != val {
+			s.notifyWatchers(&types.WatchEvent{Kv: &types.KeyValue{Key: key, Val: newVal}, Round: snap.Round - 1})
+			delete(s.writes, key)
+		}
+	}
+	for key, val := range db {
+		if _, ok := s.db[key]; !ok {
+			s.notifyWatchers(&types.WatchEvent{Kv: &types.KeyValue{Key: key, Val: val}, Round: snap.Round - 1})
+		}
+	}
+	s.db = db
+
+	// the requests committed in the rounds I missed aren't proposed again
+	s.committedIDs = make(map[string]uint32)
+	s.committedOrder = nil
+	for id, round := range snap.CommittedIds {
+		s.committedIDs[id] = round
+		s.committedOrder = append(s.committedOrder, committedID{id: id, round: round})
+	}
+	slices.SortFunc(s.committedOrder, func(a, b committedID) int { return cmp.Compare(a.round, b.round) })
+	for id := range s.reqs {
+		if _, ok := s.committedIDs[id]; ok {
+			delete(s.reqs, id)
+			if span, ok := s.mempoolSpans[id]; ok {
+				span.End()
+				delete(s.mempoolSpans, id)
+			}
+		}
+	}
+	s.pendingReconfigs = pending
+	s.reconfigSeq = snap.ReconfigSeq
+	s.joinRound = snap.Round
+	s.joinProof = snap.PrevProposerProof
+	return nil
+}
@@ -0,0 +1,273 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func addPeer(key crypto.PrivateKey, url string) *types.Reconfig {
+	return &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_ADD, Url: url, PublicKey: crypto.MarshalPublic(key.PublicKey())}
+}
+
+// signed makes rc the next membership change of s, signed by the operator
+func signed(s *Service, rc *types.Reconfig) *types.Reconfig {
+	rc.Seq = s.reconfigSeq
+	rc.Sign(testOperator, s.ChainHash)
+	return rc
+}
+
+func TestApplyReconfig(t *testing.T) {
+	s := newTestService(t, 4)
+	peers := s.Peers
+
+	newKey := crypto.GenKeyAlg(crypto.Ed25519)
+	added, err := applyReconfig(peers, addPeer(newKey, "new:9090"))
+	require.NoError(t, err)
+	require.Len(t, added, 5)
+	require.Len(t, peers, 4)
+	require.Equal(t, "new:9090", added[4].URL)
+	_, err = applyReconfig(added, addPeer(newKey, "other:9090"))
+	require.ErrorContains(t, err, "already a peer's")
+
+	remove := &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 1}
+	removed, err := applyReconfig(peers, remove)
+	require.NoError(t, err)
+	require.True(t, removed[1].Removed)
+	require.False(t, peers[1].Removed)
+	_, err = applyReconfig(removed, remove)
+	require.ErrorContains(t, err, "was removed")
+
+	// a removed peer's key can come back in a new slot
+	back, err := applyReconfig(removed, addPeer(peers[1].Key, "back:9090"))
+	require.NoError(t, err)
+	require.Len(t, back, 5)
+
+	replace := addPeer(newKey, "new:9090")
+	replace.Op, replace.Index = types.ReconfigOp_RECONFIG_OP_REPLACE, 2
+	replaced, err := applyReconfig(peers, replace)
+	require.NoError(t, err)
+	require.Len(t, replaced, 4)
+	require.Equal(t, "new:9090", replaced[2].URL)
+	require.True(t, samePeerKey(replaced[2], replace.PublicKey))
+
+	// moving a peer keeps its key, taking another's doesn't work
+	move := addPeer(peers[2].Key, "moved:9090")
+	move.Op, move.Index = types.ReconfigOp_RECONFIG_OP_REPLACE, 2
+	_, err = applyReconfig(peers, move)
+	require.NoError(t, err)
+	move.Index = 3
+	_, err = applyReconfig(peers, move)
+	require.ErrorContains(t, err, "already a peer's")
+
+	_, err = applyReconfig(peers, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 4})
+	require.ErrorContains(t, err, "no peer 4")
+}
+
+func TestReconfigQuorum(t *testing.T) {
+	s := newTestService(t, 5)
+	require.False(t, s.isQuorum([]uint32{0, 1, 2}))
+
+	remove := signed(s, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 4})
+	s.scheduleReconfig(10, remove)
+	require.NoError(t, s.checkReconfig(signed(s, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 3})))
+	require.Error(t, s.checkReconfig(signed(s, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 4})))
+
+	// nothing changes until the round the removal takes effect in
+	s.applyReconfigs(10 + reconfigDelay - 1)
+	require.Len(t, s.pendingReconfigs, 1)
+	require.False(t, s.Peers[4].Removed)
+	s.applyReconfigs(10 + reconfigDelay)
+	require.Empty(t, s.pendingReconfigs)
+	require.True(t, s.Peers[4].Removed)
+
+	// 3 of the 4 remaining nodes make a quorum, and the removed node's votes weigh nothing
+	require.True(t, s.isQuorum([]uint32{0, 1, 2}))
+	require.False(t, s.isQuorum([]uint32{0, 1, 4}))
+	for round := range uint32(50) {
+		s.roundState = &roundState{seed: computeRoundSeed(round, nil)}
+		require.NotEqualValues(t, 4, s.backupProposer())
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	s := newTestService(t, 4)
+	proposal := &types.Proposal{ProposerProof: []byte("proof"), ProposerIndex: 2}
+	s.roundState = &roundState{
+		round:             7,
+		prevProposerProof: []byte("old proof"),
+		proposals:         []*types.Proposal{proposal},
+		applied:           proposal.Hash(),
+		done:              true,
+	}
+	s.db["hello"] = "world"
+
+	joiner := newTestService(t, 1)
+	key := joiner.MyKey()
+	require.ErrorIs(t, joiner.restore(s.snapshot()), errNotAPeer)
+
+	// the addition committed in round 4 takes effect in the round the snapshot starts at
+	s.scheduleReconfig(8-reconfigDelay, signed(s, addPeer(key, "joiner:9090")))
+	s.scheduleReconfig(9, signed(s, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 0}))
+	require.NoError(t, joiner.restore(s.snapshot()))
+	require.EqualValues(t, 4, joiner.MyIndex())
+	require.Len(t, joiner.Peers, 5)
+	require.Equal(t, key, joiner.MyKey())
+	require.Equal(t, "world", joiner.db["hello"])
+	require.EqualValues(t, 8, joiner.joinRound)
+	require.Equal(t, []byte("proof"), joiner.joinProof)
+	require.Len(t, joiner.pendingReconfigs, 1)
+	require.EqualValues(t, 2, joiner.reconfigSeq)
+
+	other := newTestService(t, 1)
+	other.ChainHash = []byte("other chain")
+	require.ErrorContains(t, other.restore(s.snapshot()), "runs chain")
+}
+
+func TestRestoreRunning(t *testing.T) {
+	// the others are at the start of round 7, which isn't applied yet
+	s := newTestService(t, 4)
+	s.roundState = &roundState{round: 7, prevProposerProof: []byte("proof")}
+	s.db = map[string]string{"b": "3", "c": "4"}
+	s.rememberCommitted("req1", 5)
+	snap := s.snapshot()
+	require.EqualValues(t, 7, snap.Round)
+
+	// I'm still in round 3
+	s.roundState = &roundState{round: 3}
+	s.db = map[string]string{"a": "1", "b": "2", "c": "4"}
+	s.committedIDs, s.committedOrder = make(map[string]uint32), nil
+	s.reqs["req1"] = &types.PutReq{Id: "req1"}
+	s.reqs["req2"] = &types.PutReq{Id: "req2"}
+	w := &watcher{events: make(chan *types.WatchEvent, 3)}
+	s.watchers[w] = true
+
+	require.NoError(t, s.restore(snap))
+	require.Equal(t, snap.Db, s.db)
+	require.NotContains(t, s.reqs, "req1", "committed requests aren't proposed again")
+	require.Contains(t, s.reqs, "req2")
+	require.Len(t, w.events, 2)
+	for range 2 {
+		e := <-w.events
+		require.EqualValues(t, 6, e.Round)
+		require.Contains(t, []string{"a", "b"}, e.Kv.Key)
+		require.Equal(t, e.Kv.Key == "a", e.Deleted)
+	}
+
+	// the snapshot seeds the round I resume at
+	s.initRound(7)
+	require.Equal(t, computeRoundSeed(7, []byte("proof")), s.seed)
+
+	// my slot doesn't move while I run
+	snap.Peers[0], snap.Peers[1] = snap.Peers[1], snap.Peers[0]
+	require.ErrorContains(t, s.restore(snap), "has me as peer 1")
+}
+
+func TestReconfigSignature(t *testing.T) {
+	s := newTestService(t, 5)
+	unsigned := &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 4}
+	require.ErrorContains(t, s.checkReconfig(unsigned), "isn't signed by the operator")
+	forged := &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 4}
+	forged.Sign(s.MyKey(), s.ChainHash)
+	require.ErrorContains(t, s.checkReconfig(forged), "isn't signed by the operator")
+
+	// committed changes that aren't signed, or were already applied, are ignored
+	s.scheduleReconfig(10, unsigned)
+	require.Empty(t, s.pendingReconfigs)
+	remove := signed(s, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 4})
+	s.scheduleReconfig(10, remove)
+	s.scheduleReconfig(11, remove)
+	require.Len(t, s.pendingReconfigs, 1)
+	require.ErrorContains(t, s.checkReconfig(remove), "seq 0")
+
+	// the signature covers the chain and the change
+	other := proto.Clone(remove).(*types.Reconfig)
+	other.Index = 3
+	require.Error(t, other.VerifyOperator(testOperator.PublicKey(), s.ChainHash))
+	require.Error(t, remove.VerifyOperator(testOperator.PublicKey(), []byte("other chain")))
+
+	// nodes without an operator key take no change
+	s.OperatorKey = nil
+	require.ErrorContains(t, s.checkReconfig(signed(s, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 3})), "operator key")
+}
+
+func TestProposalReconfig(t *testing.T) {
+	s := newTestService(t, 4)
+	seed := computeRoundSeed(3, nil)
+	s.roundState = &roundState{round: 3, seed: seed}
+	proposal := &types.Proposal{
+		ProposerIndex: 1,
+		ProposerProof: crypto.Sign(s.Peers[1].Key, seed),
+		Reqs:          []*types.PutReq{{Id: "rc", Reconfig: &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 3}}},
+	}
+	require.ErrorContains(t, s.handleProposal(proposal, 3, 1), "isn't signed by the operator")
+	require.Empty(t, s.roundState.proposals)
+
+	signed(s, proposal.Reqs[0].Reconfig)
+	s.handleProposal(proposal, 3, 1)
+	require.Len(t, s.roundState.proposals, 1)
+}
+
+func TestSnapshotAuth(t *testing.T) {
+	s := newTestService(t, 4)
+	s.ChainHash = []byte("chain")
+	snapshotReq := func(key crypto.PrivateKey, at time.Time) *types.GetSnapshotReq {
+		req := &types.GetSnapshotReq{PublicKey: crypto.MarshalPublic(key.PublicKey()), TimeMs: at.UnixMilli()}
+		req.Sig = crypto.Sign(key, req.SignedBytes(s.ChainHash))
+		return req
+	}
+	require.NoError(t, s.authorizeSnapshot(snapshotReq(s.Peers[2].Key, time.Now())))
+
+	// strangers, stale requests and other chains are refused before the snapshot is taken
+	stranger := crypto.GenKey()
+	_, err := s.serveSnapshot(context.Background(), snapshotReq(stranger, time.Now()))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, s.authorizeSnapshot(snapshotReq(s.Peers[2].Key, time.Now().Add(-2*snapshotReqTTL))), "old")
+	req := snapshotReq(s.Peers[2].Key, time.Now())
+	req.TimeMs++
+	require.ErrorContains(t, s.authorizeSnapshot(req), "bad signature")
+
+	// a node may take the snapshot as soon as its addition is committed
+	s.scheduleReconfig(5, signed(s, addPeer(stranger, "stranger:9090")))
+	require.NoError(t, s.authorizeSnapshot(snapshotReq(stranger, time.Now())))
+}
+
+func TestLearner(t *testing.T) {
+	s := newTestService(t, 5)
+	learner := *s.Peers[4]
+	learner.Learner = true
+	s.Peers[4] = &learner
+
+	// the learner weighs nothing and is never the backup proposer
+	require.EqualValues(t, 4, s.TotalWeight())
+	require.True(t, s.isQuorum([]uint32{0, 1, 2}))
+	require.False(t, s.isQuorum([]uint32{0, 1, 4}))
+	for round := range uint32(50) {
+		s.roundState = &roundState{seed: computeRoundSeed(round, nil)}
+		require.NotEqualValues(t, 4, s.backupProposer())
+	}
+
+	add := addPeer(crypto.GenKey(), "learner:9090")
+	add.Learner = true
+	peers, err := applyReconfig(s.Peers, add)
+	require.NoError(t, err)
+	require.True(t, peers[5].Learner)
+
+	// the last validator stays one
+	peers = s.Peers[3:]
+	_, err = applyReconfig(peers, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 0})
+	require.ErrorContains(t, err, "last validator")
+	_, err = applyReconfig(peers, &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 1})
+	require.NoError(t, err)
+	add.Op, add.Index = types.ReconfigOp_RECONFIG_OP_REPLACE, 0
+	_, err = applyReconfig(peers, add)
+	require.ErrorContains(t, err, "last validator")
+}
@@ -1,88 +1,171 @@
 package consensus
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/tracing"
 	"github.com/patrickmao1/beeftea/types"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (s *Service) handleMessage(nodeIdx uint32, msg *types.Message) (shouldDefer bool) {
+func (s *Service) handleMessage(e *types.Envelope) {
 	var err error
+	msg := e.Msg
+	if msg == nil {
+		log.Errorf("handleMessage err: envelope without a message from node %d", e.NodeIndex)
+		return
+	}
+	_, span := s.tracer.Start(tracing.Extract(context.Background(), e.TraceContext), "handle "+msg.TypeName(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.Int64("round", int64(msg.Round)), attribute.Int64("from", int64(e.NodeIndex))),
+	)
+	defer span.End()
 	switch msg.Type.(type) {
 	case *types.Message_Proposal:
-		shouldDefer, err = s.handleProposal(msg.GetProposal(), nodeIdx)
+		err = s.handleProposal(msg.GetProposal(), msg.Round, e.NodeIndex)
 	case *types.Message_Prepare:
-		shouldDefer, err = s.handlePrepare(msg.GetPrepare(), nodeIdx)
+		err = s.handlePrepare(e)
 	case *types.Message_Commit:
-		shouldDefer, err = s.handleCommit(msg.GetCommit(), nodeIdx)
+		err = s.handleCommit(e)
+	case *types.Message_Equivocation:
+		err = s.handleEquivocation(msg.GetEquivocation(), e.NodeIndex)
+	case *types.Message_Timeout:
+		err = s.handleTimeout(msg.Round, e.NodeIndex)
+	case *types.Message_BeaconShare:
+		err = s.handleBeaconShare(msg.GetBeaconShare(), msg.Round, e.NodeIndex)
+	case *types.Message_QuorumCertificate:
+		err = s.handleQuorumCertificate(msg.GetQuorumCertificate(), msg.Round, e.NodeIndex)
 	default:
-		log.Panicf("unsupported message type: %T", msg.Type)
+		err = fmt.Errorf("unsupported message type %T from node %d", msg.Type, e.NodeIndex)
 	}
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		log.Errorf("handleMessage err: %s", err.Error())
 	}
-	return shouldDefer
 }
 
 // this method is called when the message is a proposal
-func (s *Service) handleProposal(proposal *types.Proposal, nodeIdx uint32) (shouldDefer bool, err error) {
+func (s *Service) handleProposal(proposal *types.Proposal, round, nodeIdx uint32) error {
 	// collect all proposals, then once the timer ends, call prepare with the minimum
 	// actually keep all proposals for later, edit roundstate so that it stores all proposals
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.metrics.ProposalsReceived.Inc()
+	s.mu.RLock()
+	err := s.checkRound(round)
+	var seed []byte
+	var proposer *types.Peer
+	if err == nil {
+		seed = s.seed
+		if int(proposal.ProposerIndex) < len(s.Peers) {
+			proposer = s.Peers[proposal.ProposerIndex]
+		}
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		s.metrics.ProposalsRejected.WithLabelValues("wrong_round").Inc()
+		return err
+	}
+	if proposer == nil || !proposer.IsValidator() {
+		s.metrics.ProposalsRejected.WithLabelValues("bad_proof").Inc()
+		return fmt.Errorf("proposal from unknown node %d", proposal.ProposerIndex)
+	}
 
-	pubkey := &s.Peers[proposal.ProposerIndex].Key.PublicKey
-	pass := crypto.Verify(pubkey, s.seed, proposal.ProposerProof)
+	// the beacon value and the proof are checked without holding the lock, so that the other
+	// messages of the round aren't held up by the verifications
+	if seed == nil {
+		// The proposer had the beacon value before me, take it from the proposal
+		if err := s.beacon.AddValue(round, proposal.Beacon); err != nil {
+			s.metrics.ProposalsRejected.WithLabelValues("bad_beacon").Inc()
+			return fmt.Errorf("proposal from node %d has a bad beacon value: %s", proposal.ProposerIndex, err.Error())
+		}
+		seed = beaconSeed(round, proposal.Beacon)
+	}
+	pass := crypto.Verify(proposer.PubKey(), seed, proposal.ProposerProof)
 	if !pass {
-		log.Warnf("proposal from node %d verify fail", proposal.ProposerIndex)
-		// verification failed maybe because I'm not in the same round (due to a bit of desync)
-		// as the proposer, retry processing this proposal later.
-		return true, nil
+		s.metrics.ProposalsRejected.WithLabelValues("bad_proof").Inc()
+		return fmt.Errorf("proposal from node %d verify fail", proposal.ProposerIndex)
+	}
+	// every node would apply a membership change in a committed proposal, so the proposer can't
+	// be trusted to have checked it
+	for _, req := range proposal.Reqs {
+		if req.Reconfig == nil {
+			continue
+		}
+		if err := req.Reconfig.VerifyOperator(s.OperatorKey, s.ChainHash); err != nil {
+			s.metrics.ProposalsRejected.WithLabelValues("bad_reconfig").Inc()
+			return fmt.Errorf("proposal from node %d: %s", proposal.ProposerIndex, err.Error())
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkRound(round); err != nil {
+		s.metrics.ProposalsRejected.WithLabelValues("wrong_round").Inc()
+		return err
+	}
+	if s.setBeaconSeed(round, proposal.Beacon) {
+		go s.propose()
+	}
+	if !bytes.Equal(s.seed, seed) {
+		// the seed changed while I was checking the proof
+		s.metrics.ProposalsRejected.WithLabelValues("bad_proof").Inc()
+		return fmt.Errorf("proposal from node %d was checked against a stale seed", proposal.ProposerIndex)
 	}
 	s.roundState.proposals = append(s.roundState.proposals, proposal)
 
-	newScore := proposal.Score()
-	if newScore >= s.ProposalThreshold {
-		return false, fmt.Errorf("received proposal score too big %d > %d", newScore, s.ProposalThreshold)
+	selected, newPriority := s.sortition(proposal.ProposerIndex, proposal.ProposerProof)
+	if selected == 0 && proposal.ProposerIndex != s.backupProposer() {
+		s.metrics.ProposalsRejected.WithLabelValues("score_too_high").Inc()
+		return fmt.Errorf("received proposal from node %d with no selected sub-users (score %d)",
+			proposal.ProposerIndex, proposal.Score())
 	}
+	s.roundState.lastProposalAt = s.clock.Now()
 
 	if s.roundState.minProposal == nil {
 		s.roundState.minProposal = proposal
-		log.Infof("Set initial minProposal to proposal with score %d", newScore)
+		s.roundState.minPriority = newPriority
+		log.Infof("Set initial minProposal to proposal with priority %d", newPriority)
 	} else {
-		curMinScore := s.minProposal.Score()
-		if newScore < curMinScore {
+		curMinPriority := s.roundState.minPriority
+		if newPriority < curMinPriority {
 			s.roundState.minProposal = proposal
-			log.Infof("Updated minProposal to proposal with smaller score %d < %d", newScore, curMinScore)
+			s.roundState.minPriority = newPriority
+			log.Infof("Updated minProposal to proposal with smaller priority %d < %d", newPriority, curMinPriority)
 		} else {
-			log.Infof("Ignored proposal with larger score %d > %d", newScore, curMinScore)
+			log.Infof("Ignored proposal with larger priority %d > %d", newPriority, curMinPriority)
 		}
 	}
 
-	return false, nil
+	return nil
 }
 
-func (s *Service) handlePrepare(prep *types.Prepare, nodeIdx uint32) (shouldDefer bool, err error) {
+func (s *Service) handlePrepare(e *types.Envelope) error {
 	// msg that i get here should be the same as minproposal
 	// store all prepares in ether an array or a map, then check if we have reached quorum on any of the prepares
 	// once we reach quorum, call commit
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.roundState == nil || s.roundState.round != s.round() {
-		log.Warnf("Deferring Prepare: not in the correct round (expected %d, got %d)", s.roundState.round, s.round())
-		return true, nil
+	prep, nodeIdx := e.Msg.GetPrepare(), e.NodeIndex
+	if err := s.checkRound(e.Msg.Round); err != nil {
+		return err
 	}
-
-	if !s.roundState.prepared {
-		log.Warnf("Deferring Prepare: haven't prepared yet, so can't accept others' prepares")
-		return true, nil
+	if err := checkDigest(prep.ProposalDigest); err != nil {
+		return fmt.Errorf("bad Prepare from node %d: %s", nodeIdx, err.Error())
+	}
+	if err := s.checkEquivocation(s.roundState.prepareVotes, e, prep.ProposalDigest); err != nil {
+		return err
 	}
 
-	digest := string(prep.ProposalDigest[:8])
+	digest := string(prep.ProposalDigest)
 
 	// initialize map if digest is seen for the first time
 	if s.roundState.prepares == nil {
@@ -95,42 +178,36 @@ func (s *Service) handlePrepare(prep *types.Prepare, nodeIdx uint32) (shouldDefe
 	// Check for double-vote
 	if s.roundState.prepares[digest][nodeIdx] {
 		log.Warnf("Duplicate Prepare received from node %d for digest %x", nodeIdx, prep.ProposalDigest)
-		return false, nil
+		return nil
 	}
 
 	// Record the prepare vote
 	s.roundState.prepares[digest][nodeIdx] = true
+	s.addVoteSig(types.Phase_PHASE_PREPARE, prep.ProposalDigest, nodeIdx, prep.BlsSig)
 	log.Infof("Accepted Prepare from node %d for digest %x, current count %d",
 		nodeIdx, prep.ProposalDigest, len(s.roundState.prepares[digest]))
 
-	if len(s.roundState.prepares[digest]) >= 3 && !s.roundState.committed {
-		log.Infof("Prepare quorum reached for digest %x, broadcasting Commit", prep.ProposalDigest)
-		go func() {
-			err := s.commit(prep.ProposalDigest) // Call asynchronously to avoid deadlock
-			if err != nil {
-				log.Errorf("commit failed: digest %x, err %s", prep.ProposalDigest, err.Error())
-			}
-		}()
-	}
-	return false, nil
+	s.checkPrepareQuorum(prep.ProposalDigest)
+	return nil
 }
 
 // this method is called when the message is a commit
-func (s *Service) handleCommit(comm *types.Commit, nodeIdx uint32) (shouldDefer bool, err error) {
+func (s *Service) handleCommit(e *types.Envelope) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.roundState == nil || s.roundState.round != s.round() {
-		log.Warnf("Deferring Commit: not in the correct round (expected %d, got %d)", s.roundState.round, s.round())
-		return true, nil
+	comm, nodeIdx := e.Msg.GetCommit(), e.NodeIndex
+	if err := s.checkRound(e.Msg.Round); err != nil {
+		return err
 	}
-
-	if !s.roundState.committed {
-		log.Warnf("Deferring Commit: haven't committed yet, can't accept others' commits")
-		return true, nil
+	if err := checkDigest(comm.ProposalDigest); err != nil {
+		return fmt.Errorf("bad Commit from node %d: %s", nodeIdx, err.Error())
+	}
+	if err := s.checkEquivocation(s.roundState.commitVotes, e, comm.ProposalDigest); err != nil {
+		return err
 	}
 
-	digest := string(comm.ProposalDigest[:8])
+	digest := string(comm.ProposalDigest)
 
 	// Initialize commit map if needed
 	if s.roundState.commits == nil {
@@ -143,17 +220,99 @@ func (s *Service) handleCommit(comm *types.Commit, nodeIdx uint32) (shouldDefer
 	// Prevent duplicate votes from the same node
 	if s.roundState.commits[digest][nodeIdx] {
 		log.Warnf("Duplicate Commit received from node %d for digest %x", nodeIdx, comm.ProposalDigest)
-		return false, nil
+		return nil
 	}
 
 	// Record the vote
 	s.roundState.commits[digest][nodeIdx] = true
+	s.addVoteSig(types.Phase_PHASE_COMMIT, comm.ProposalDigest, nodeIdx, comm.BlsSig)
 	log.Infof("Accepted Commit from node %d for digest %x", nodeIdx, comm.ProposalDigest)
 
-	// Quorum reached: finalize the decision
-	if len(s.roundState.commits[digest]) >= 3 {
-		log.Infof("Commit quorum reached for digest %x. Finalizing commit.", comm.ProposalDigest)
-		go s.commitLocal(comm.ProposalDigest) // Call asynchronously to apply state changes
+	s.checkCommitQuorum(comm.ProposalDigest)
+	return nil
+}
+
+// checkRound makes sure a message belongs to the round I'm in. The network only hands over
+// messages of the current round, but the round can move on while a message is being handled.
+// Must be called with s.mu held.
+func (s *Service) checkRound(round uint32) error {
+	if s.roundState == nil {
+		return fmt.Errorf("round %d msg arrived before the first round started", round)
+	}
+	if s.roundState.round != round {
+		return fmt.Errorf("dropping stale round %d msg (current round %d)", round, s.roundState.round)
+	}
+	return nil
+}
+
+// checkDigest makes sure a vote carries a full proposal digest
+func checkDigest(digest []byte) error {
+	if len(digest) != types.DigestLen {
+		return fmt.Errorf("invalid digest length %d, expected %d", len(digest), types.DigestLen)
+	}
+	return nil
+}
+
+// checkPrepareQuorum broadcasts a Commit once digest has collected a quorum of prepares.
+// Must be called with s.mu held.
+func (s *Service) checkPrepareQuorum(digest []byte) {
+	key := string(digest)
+	if !s.isQuorum(voters(s.roundState.prepares[key])) {
+		return
+	}
+	if s.roundState.prepareQC == nil {
+		s.roundState.prepareQC = s.certify(types.Phase_PHASE_PREPARE, digest)
+	}
+	if !s.roundState.committed {
+		log.Infof("Prepare quorum reached for digest %x, broadcasting Commit", digest)
+		s.metrics.QuorumLatency.WithLabelValues("prepare").Observe(s.since(s.roundState.startTime).Seconds())
+		go func() {
+			err := s.commit(digest) // Call asynchronously to avoid deadlock
+			if err != nil {
+				log.Errorf("commit failed: digest %x, err %s", digest, err.Error())
+			}
+		}()
+	}
+}
+
+// checkCommitQuorum applies the proposal once digest has collected a quorum of commits.
+// Must be called with s.mu held.
+func (s *Service) checkCommitQuorum(digest []byte) {
+	key := string(digest)
+	if !s.isQuorum(voters(s.roundState.commits[key])) {
+		return
+	}
+	if s.roundState.commitQC == nil {
+		if qc := s.certify(types.Phase_PHASE_COMMIT, digest); qc != nil {
+			s.setCommitQC(qc)
+			// for the nodes that miss Commits
+			msg := &types.Message{Round: qc.Round, Type: &types.Message_QuorumCertificate{QuorumCertificate: qc}}
+			s.broadcast(s.roundState.phaseCtx(), msg)
+		}
+	}
+	if !s.roundState.finalized {
+		log.Infof("Commit quorum reached for digest %x. Finalizing commit.", digest)
+		s.finalize(digest)
+	}
+}
+
+// finalize applies the proposal with the given digest, which a quorum committed.
+// Must be called with s.mu held.
+func (s *Service) finalize(digest []byte) {
+	s.roundState.finalized = true
+	s.roundState.applied = digest
+	s.pacer.observeCommit(s.since(s.roundState.startTime))
+	s.metrics.QuorumLatency.WithLabelValues("commit").Observe(s.since(s.roundState.startTime).Seconds())
+	if !s.roundState.committedAt.IsZero() {
+		s.metrics.PhaseDuration.WithLabelValues("commit").Observe(s.since(s.roundState.committedAt).Seconds())
 	}
-	return false, nil
+	go func(state *roundState) {
+		s.commitLocal(state, digest) // Call asynchronously to apply state changes
+		s.signal()
+	}(s.roundState)
+}
+
+// voters lists the nodes in a vote set
+func voters(votes map[uint32]bool) []uint32 {
+	return slices.Collect(maps.Keys(votes))
 }
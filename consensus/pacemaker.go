@@ -0,0 +1,218 @@
+package consensus
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// run is the main loop, until Stop. Rounds are driven by messages rather than by the wall clock, so that nodes
+// agree on the round whatever their clocks say:
+//   - when its round timer expires a node broadcasts a Timeout for the round,
+//   - Timeouts for a round from nodes of more than 1/3 of the weight make a node send its own, as
+//     at least one honest node timed out,
+//   - Timeouts for a round from a quorum (a timeout certificate) move a node to the next round,
+//     even if the node is behind,
+//   - messages from nodes of more than 1/3 of the weight two or more rounds ahead make a node
+//     that fell behind jump there.
+//
+// A node that moves more than one round ahead takes the state of its peers first, see syncState.
+//
+// In responsive mode a node also moves on as soon as it has applied the round's proposal, and
+// times out right after the proposal phase if no proposal arrived.
+func (s *Service) run() {
+	// enough nodes being ahead means that at least one honest node has moved on
+	s.Network.OnRoundAhead(func(nodes []uint32) bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.oneHonest(nodes)
+	}, s.roundAhead)
+
+	next := s.joinRound
+	for {
+		round := next
+		next = round + 1
+		s.initRound(round)
+		s.propose()
+
+		proposalTimeout, roundTimeout := s.timeouts()
+		if !s.Responsive {
+			roundTimeout = s.RoundDuration
+		}
+		proposalPhaseEnd := s.clock.NewTimer(proposalTimeout)
+		roundEnd := s.clock.NewTimer(roundTimeout)
+	wait:
+		for {
+			select {
+			case <-s.stop:
+				proposalPhaseEnd.Stop()
+				roundEnd.Stop()
+				return
+			case <-proposalPhaseEnd.C:
+				err := s.prepare()
+				if err != nil {
+					log.Error(err)
+				}
+				if s.Responsive && !s.hasProposal() {
+					log.Infof("round %d: no proposal", round)
+					s.sendTimeout(round)
+				}
+			case <-s.wake:
+				s.mu.RLock()
+				done, catchUp := s.done, s.catchUpRound
+				s.mu.RUnlock()
+				if catchUp > round+1 {
+					// the rounds in between may have committed, take their state from the peers
+					synced, ok := s.syncState()
+					if !ok {
+						// try again later rather than skip rounds
+						time.AfterFunc(s.RoundDuration, s.signal)
+						continue
+					}
+					next = synced
+					break wait
+				}
+				if catchUp > round {
+					next = catchUp
+					break wait
+				}
+				// Wait for the proposal to be applied so that its requests aren't proposed again
+				if s.Responsive && done {
+					break wait
+				}
+			case <-roundEnd.C:
+				log.Infof("round %d: timer expired", round)
+				s.sendTimeout(round)
+			}
+		}
+		proposalPhaseEnd.Stop()
+		roundEnd.Stop()
+	}
+}
+
+// sendTimeout broadcasts a Timeout for round unless I already did
+func (s *Service) sendTimeout(round uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendTimeoutLocked(round)
+}
+
+// Must be called with s.mu held.
+func (s *Service) sendTimeoutLocked(round uint32) {
+	if last, ok := s.timeoutRounds[s.MyIndex()]; ok && last >= round {
+		return
+	}
+	msg := &types.Message{Round: round, Type: &types.Message_Timeout{Timeout: &types.Timeout{}}}
+	s.broadcast(s.roundState.phaseCtx(), msg)
+	s.addTimeout(round, s.MyIndex())
+}
+
+func (s *Service) handleTimeout(round, nodeIdx uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addTimeout(round, nodeIdx)
+	return nil
+}
+
+// addTimeout records a Timeout. Only the highest round each node timed out in is kept: an honest
+// node that timed out in a round has timed out in or committed all the rounds before it. So the
+// nodes that timed out in r or later are done with round r. Must be called with s.mu held.
+func (s *Service) addTimeout(round, nodeIdx uint32) {
+	if last, ok := s.timeoutRounds[nodeIdx]; ok && last >= round {
+		return
+	}
+	s.timeoutRounds[nodeIdx] = round
+
+	current := uint32(0)
+	if s.roundState != nil {
+		current = s.roundState.round
+	}
+	if r, ok := s.timeoutRound(s.oneHonest); ok && r >= current {
+		// at least one honest node timed out in r, join it
+		s.sendTimeoutLocked(r)
+	}
+	if r, ok := s.timeoutRound(s.isQuorum); ok && r >= current {
+		// timeout certificate
+		s.catchUpLocked(r + 1)
+	}
+}
+
+// timeoutRound returns the highest round r such that the nodes that timed out in r or later are
+// enough
+func (s *Service) timeoutRound(enough func(nodes []uint32) bool) (uint32, bool) {
+	nodes := slices.Collect(maps.Keys(s.timeoutRounds))
+	// highest round first
+	slices.SortFunc(nodes, func(a, b uint32) int {
+		return cmp.Compare(s.timeoutRounds[b], s.timeoutRounds[a])
+	})
+	for i, node := range nodes {
+		if enough(nodes[:i+1]) {
+			return s.timeoutRounds[node], true
+		}
+	}
+	return 0, false
+}
+
+// roundAhead is called when f+1 nodes have sent messages for a later round
+func (s *Service) roundAhead(round uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Being one round behind is normal in responsive mode: the others may have reached the commit
+	// quorum first, and their Commits, which I need to apply the proposal and compute the next
+	// seed, are on their way
+	if s.roundState != nil && round <= s.roundState.round+1 {
+		return
+	}
+	s.catchUpLocked(round)
+}
+
+// Must be called with s.mu held.
+func (s *Service) catchUpLocked(round uint32) {
+	if round <= s.catchUpRound {
+		return
+	}
+	if s.roundState != nil && round <= s.roundState.round {
+		return
+	}
+	s.catchUpRound = round
+	s.signal()
+}
+
+// signal wakes up the main loop without blocking
+func (s *Service) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// weightOf returns the total weight of nodes
+func (s *Service) weightOf(nodes []uint32) uint64 {
+	weight := uint64(0)
+	for _, node := range nodes {
+		weight += s.Weight(node)
+	}
+	return weight
+}
+
+// isQuorum reports whether nodes hold more than 2/3 of the total weight. Any two quorums then
+// share nodes of more than 1/3 of the weight, at least one of which is honest as long as faulty
+// nodes hold less than 1/3.
+func (s *Service) isQuorum(nodes []uint32) bool {
+	return 3*s.weightOf(nodes) > 2*s.TotalWeight()
+}
+
+// oneHonest reports whether nodes hold more than 1/3 of the total weight, so that at least one of
+// them is honest
+func (s *Service) oneHonest(nodes []uint32) bool {
+	return 3*s.weightOf(nodes) > s.TotalWeight()
+}
+
+// since returns the time elapsed on my clock since t
+func (s *Service) since(t time.Time) time.Duration {
+	return s.clock.Now().Sub(t)
+}
@@ -0,0 +1,45 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutCertificate(t *testing.T) {
+	s := newTestService(t, 4) // f = 1
+	s.roundState = &roundState{round: 5}
+
+	require.NoError(t, s.handleTimeout(5, 1))
+	require.Zero(t, s.catchUpRound)
+	_, sent := s.timeoutRounds[0]
+	require.False(t, sent)
+
+	// f+1 nodes timed out, I join them and the 2f+1 Timeouts move me to the next round
+	require.NoError(t, s.handleTimeout(5, 2))
+	require.EqualValues(t, 5, s.timeoutRounds[0])
+	require.EqualValues(t, 6, s.catchUpRound)
+
+	// Timeouts for past rounds don't count
+	require.NoError(t, s.handleTimeout(4, 3))
+	require.EqualValues(t, 6, s.catchUpRound)
+}
+
+func TestTimeoutCatchUp(t *testing.T) {
+	s := newTestService(t, 4)
+	s.roundState = &roundState{round: 2}
+
+	// the others are far ahead, the highest round of each node counts
+	require.NoError(t, s.handleTimeout(9, 1))
+	require.NoError(t, s.handleTimeout(3, 2))
+	require.NoError(t, s.handleTimeout(12, 2))
+	require.EqualValues(t, 9, s.timeoutRounds[0])
+	require.EqualValues(t, 10, s.catchUpRound)
+
+	// a single node can't move me
+	s = newTestService(t, 4)
+	s.roundState = &roundState{round: 2}
+	require.NoError(t, s.handleTimeout(100, 3))
+	require.NoError(t, s.handleTimeout(101, 3))
+	require.Zero(t, s.catchUpRound)
+}
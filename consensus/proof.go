@@ -0,0 +1,71 @@
+package consensus
+
+import "github.com/patrickmao1/beeftea/types"
+
+// When the nodes have BLS keys, a node keeps for every key the proposal that last wrote it and
+// the certificate of that proposal's commit quorum, so that a client can check a value read from
+// a single node against the BLS public keys of the peers instead of asking f+1 nodes.
+
+// idempotencyRounds is for how many rounds the ids of committed requests are remembered, so that
+// a client retrying a Put that was already committed doesn't write it again
+const idempotencyRounds = 1000
+
+// commitProof proves that proposal was committed in round. It is shared by the keys the proposal
+// wrote.
+type commitProof struct {
+	round    uint32
+	proposal *types.Proposal
+	qc       *types.QuorumCertificate // nil until I have the certificate
+}
+
+type committedID struct {
+	id    string
+	round uint32
+}
+
+// recordWrite keeps the proof of the applied proposal for key. Must be called with s.mu held.
+func (s *Service) recordWrite(state *roundState, proposal *types.Proposal, key string) {
+	if s.blsKeys == nil {
+		return
+	}
+	if state.proof == nil {
+		state.proof = &commitProof{round: state.round, proposal: proposal, qc: state.commitQC}
+	}
+	s.writes[key] = state.proof
+}
+
+// setCommitQC records the certificate of the current round's commit quorum. Must be called with
+// s.mu held.
+func (s *Service) setCommitQC(qc *types.QuorumCertificate) {
+	s.roundState.commitQC = qc
+	s.lastCommitQC = qc
+	if s.roundState.proof != nil {
+		s.roundState.proof.qc = qc
+	}
+}
+
+// proveWrite fills in the proof of the value of res.Kv if I have one. Must be called with s.mu
+// held.
+func (s *Service) proveWrite(res *types.GetRes) {
+	proof := s.writes[res.Kv.Key]
+	if proof == nil || proof.qc == nil {
+		return
+	}
+	res.Round = proof.round
+	res.Proposal = proof.proposal
+	res.CommitQc = proof.qc
+}
+
+// rememberCommitted records the id of a committed request and forgets those committed more than
+// idempotencyRounds ago. Must be called with s.mu held.
+func (s *Service) rememberCommitted(id string, round uint32) {
+	s.committedIDs[id] = round
+	s.committedOrder = append(s.committedOrder, committedID{id: id, round: round})
+	for len(s.committedOrder) > 0 && s.committedOrder[0].round+idempotencyRounds < round {
+		old := s.committedOrder[0]
+		if s.committedIDs[old.id] == old.round {
+			delete(s.committedIDs, old.id)
+		}
+		s.committedOrder = s.committedOrder[1:]
+	}
+}
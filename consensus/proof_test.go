@@ -0,0 +1,22 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRememberCommitted(t *testing.T) {
+	s := newTestService(t, 4)
+	s.rememberCommitted("a", 1)
+	s.rememberCommitted("b", 5)
+	s.rememberCommitted("a", 10)
+	require.Len(t, s.committedIDs, 2)
+
+	// "a" was committed again since round 1, so it stays until round 10 is forgotten
+	s.rememberCommitted("c", 1+idempotencyRounds+1)
+	require.Equal(t, map[string]uint32{"a": 10, "b": 5, "c": 1 + idempotencyRounds + 1}, s.committedIDs)
+	s.rememberCommitted("d", 10+idempotencyRounds+1)
+	require.Equal(t, map[string]uint32{"c": 1 + idempotencyRounds + 1, "d": 10 + idempotencyRounds + 1}, s.committedIDs)
+	require.Len(t, s.committedOrder, 2)
+}
@@ -0,0 +1,137 @@
+package consensus
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Besides the ECDSA signature of its envelope, each vote carries a BLS signature of the vote when
+// the nodes have BLS keys. Once a digest has a quorum of votes in a phase, their BLS signatures are
+// aggregated into a QuorumCertificate, a few hundred bytes whatever the number of nodes. The commit
+// certificates are forwarded to the other nodes, so that a node that missed Commits can still
+// finalize the round.
+
+// signVote returns my BLS signature of a vote, nil if the nodes don't have BLS keys. Must be
+// called with s.mu held.
+func (s *Service) signVote(phase types.Phase, digest []byte) []byte {
+	if s.blsKeys == nil {
+		return nil
+	}
+	sig := crypto.BLSSign(s.Peers[s.MyIndex()].BLSKey, types.VoteMsg(s.ChainHash, phase, s.roundState.round, digest))
+	s.addVoteSig(phase, digest, s.MyIndex(), sig)
+	return sig
+}
+
+// addVoteSig records the BLS signature of a vote. It isn't checked until the certificate is built.
+// Must be called with s.mu held.
+func (s *Service) addVoteSig(phase types.Phase, digest []byte, voter uint32, sig []byte) {
+	if s.blsKeys == nil || len(sig) == 0 {
+		return
+	}
+	if s.roundState.voteSigs[phase] == nil {
+		s.roundState.voteSigs[phase] = make(map[string]map[uint32][]byte)
+	}
+	sigs := s.roundState.voteSigs[phase]
+	if sigs[string(digest)] == nil {
+		sigs[string(digest)] = make(map[uint32][]byte)
+	}
+	if _, ok := sigs[string(digest)][voter]; !ok {
+		sigs[string(digest)][voter] = sig
+	}
+}
+
+// certify builds the certificate of the votes for digest in a phase, nil if the nodes don't have
+// BLS keys or the valid signatures aren't a quorum (yet). Must be called with s.mu held.
+func (s *Service) certify(phase types.Phase, digest []byte) *types.QuorumCertificate {
+	if s.blsKeys == nil {
+		return nil
+	}
+	sigs := s.roundState.voteSigs[phase][string(digest)]
+	signers := slices.Sorted(maps.Keys(sigs))
+	if !s.isQuorum(signers) {
+		return nil
+	}
+	bitmap := crypto.NewBitmap(len(s.Peers))
+	var parts [][]byte
+	for _, signer := range signers {
+		bitmap.Set(signer)
+		parts = append(parts, sigs[signer])
+	}
+	qc := &types.QuorumCertificate{
+		Round:          s.roundState.round,
+		Phase:          phase,
+		ProposalDigest: digest,
+		Signers:        bitmap,
+	}
+	var err error
+	qc.AggSig, err = crypto.AggregateBLS(parts)
+	if err == nil {
+		err = s.verifyQC(qc)
+	}
+	if err == nil {
+		return qc
+	}
+
+	// Some signatures are bad, drop them and try again with the others
+	msg := types.VoteMsg(s.ChainHash, phase, s.roundState.round, digest)
+	var bad []uint32
+	for _, signer := range signers {
+		if !crypto.BLSVerify(s.blsKeys[signer], msg, sigs[signer]) {
+			bad = append(bad, signer)
+			delete(sigs, signer)
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	log.Errorf("round %d: bad BLS vote signatures from nodes %v", s.roundState.round, bad)
+	return s.certify(phase, digest)
+}
+
+// verifyQC checks that a quorum signed the certificate
+func (s *Service) verifyQC(qc *types.QuorumCertificate) error {
+	if s.blsKeys == nil {
+		return fmt.Errorf("the nodes don't have BLS keys")
+	}
+	return qc.Verify(s.ChainHash, s.blsKeys, s.isQuorum)
+}
+
+// handleQuorumCertificate finalizes the round with a commit certificate from another node if I
+// haven't reached the commit quorum myself
+func (s *Service) handleQuorumCertificate(qc *types.QuorumCertificate, round, nodeIdx uint32) error {
+	if qc.Phase != types.Phase_PHASE_COMMIT {
+		return fmt.Errorf("unexpected %s certificate from node %d", qc.Phase, nodeIdx)
+	}
+	if qc.Round != round {
+		return fmt.Errorf("certificate of round %d sent in round %d by node %d", qc.Round, round, nodeIdx)
+	}
+	s.mu.RLock()
+	err := s.checkRound(round)
+	finalized := err == nil && s.roundState.finalized
+	s.mu.RUnlock()
+	if err != nil || finalized {
+		return err
+	}
+	if err := checkDigest(qc.ProposalDigest); err != nil {
+		return fmt.Errorf("bad certificate from node %d: %s", nodeIdx, err.Error())
+	}
+	// the pairings are slow, check the certificate without holding the lock
+	if err := s.verifyQC(qc); err != nil {
+		return fmt.Errorf("bad certificate from node %d: %s", nodeIdx, err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkRound(round); err != nil || s.roundState.finalized {
+		return err
+	}
+	log.Infof("round %d: finalizing with the commit certificate from node %d", round, nodeIdx)
+	s.setCommitQC(qc)
+	s.finalize(qc.ProposalDigest)
+	return nil
+}
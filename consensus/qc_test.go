@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newBLSTestService(t *testing.T, n int) *Service {
+	s := newTestService(t, n)
+	for _, peer := range s.Peers {
+		peer.BLSKey = crypto.GenBLSKey()
+		s.blsKeys = append(s.blsKeys, peer.BLSKey.PublicKey())
+	}
+	s.ChainHash = []byte("chain")
+	s.roundState = &roundState{round: 7, voteSigs: make(map[types.Phase]map[string]map[uint32][]byte)}
+	return s
+}
+
+func TestCertify(t *testing.T) {
+	s := newBLSTestService(t, 4)
+	digest := make([]byte, 32)
+	vote := func(voter uint32, msg []byte) {
+		s.addVoteSig(types.Phase_PHASE_PREPARE, digest, voter, crypto.BLSSign(s.Peers[voter].BLSKey, msg))
+	}
+	msg := types.VoteMsg(s.ChainHash, types.Phase_PHASE_PREPARE, 7, digest)
+
+	s.signVote(types.Phase_PHASE_PREPARE, digest)
+	vote(1, msg)
+	require.Nil(t, s.certify(types.Phase_PHASE_PREPARE, digest))
+
+	// the bad signature is dropped and the others aren't a quorum anymore
+	vote(2, types.VoteMsg(s.ChainHash, types.Phase_PHASE_COMMIT, 7, digest))
+	require.Nil(t, s.certify(types.Phase_PHASE_PREPARE, digest))
+	vote(2, msg)
+	vote(3, msg)
+	qc := s.certify(types.Phase_PHASE_PREPARE, digest)
+	require.NotNil(t, qc)
+	require.Equal(t, []uint32{0, 1, 2, 3}, crypto.Bitmap(qc.Signers).Indices())
+	require.NoError(t, s.verifyQC(qc))
+}
+
+func TestVerifyQC(t *testing.T) {
+	s := newBLSTestService(t, 4)
+	digest := make([]byte, 32)
+	msg := types.VoteMsg(s.ChainHash, types.Phase_PHASE_COMMIT, 7, digest)
+	newQC := func(signers ...uint32) *types.QuorumCertificate {
+		bitmap := crypto.NewBitmap(len(s.Peers))
+		var sigs [][]byte
+		for _, signer := range signers {
+			bitmap.Set(signer)
+			sigs = append(sigs, crypto.BLSSign(s.Peers[signer].BLSKey, msg))
+		}
+		aggSig, err := crypto.AggregateBLS(sigs)
+		require.NoError(t, err)
+		return &types.QuorumCertificate{Round: 7, Phase: types.Phase_PHASE_COMMIT, ProposalDigest: digest, Signers: bitmap, AggSig: aggSig}
+	}
+
+	require.NoError(t, s.verifyQC(newQC(0, 2, 3)))
+	require.Error(t, s.verifyQC(newQC(0, 2)))
+
+	qc := newQC(0, 1, 2)
+	qc.Signers = crypto.NewBitmap(16)
+	for _, signer := range []uint32{0, 1, 2, 9} {
+		crypto.Bitmap(qc.Signers).Set(signer)
+	}
+	require.EqualError(t, s.verifyQC(qc), "unknown signer 9")
+
+	qc = newQC(0, 1, 2)
+	qc.Round = 8
+	require.Error(t, s.verifyQC(qc))
+	qc = newQC(0, 1, 2)
+	qc.Phase = types.Phase_PHASE_PREPARE
+	require.Error(t, s.verifyQC(qc))
+
+	// certificates of another network don't verify
+	qc = newQC(0, 1, 2)
+	require.Error(t, qc.Verify([]byte("other chain"), s.blsKeys, s.isQuorum))
+}
@@ -1,31 +1,97 @@
 package consensus
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"github.com/patrickmao1/beeftea/tracing"
 	"github.com/patrickmao1/beeftea/types"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net"
+	"net/http"
+	"slices"
+	"strings"
 )
 
-// Handles the incoming request from clients that wants to interact with the system
-func (s *Service) startRPC() {
-	svr := grpc.NewServer()
-	lis, err := net.Listen("tcp", "0.0.0.0:8080")
+// serveGRPC serves svr on lis until it is stopped
+func serveGRPC(name string, svr *grpc.Server, lis net.Listener) {
+	log.Infof("%s listening on %s", name, lis.Addr())
+	err := svr.Serve(lis)
 	if err != nil {
-		log.Fatal(err)
+		log.Errorf("%s failed: %s", name, err.Error())
 	}
-	log.Infof("External server listening on 0.0.0.0:8080")
-	types.RegisterExternalRPCServer(svr, s)
-	err = svr.Serve(lis)
-	if err != nil {
-		log.Fatal(err)
+}
+
+// serveHTTP serves on lis until svr is shut down
+func serveHTTP(name string, svr *http.Server, lis net.Listener) {
+	log.Infof("%s listening on %s", name, lis.Addr())
+	err := svr.Serve(lis)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Errorf("%s failed: %s", name, err.Error())
 	}
 }
 
 func (s *Service) Put(ctx context.Context, req *types.PutReq) (*types.PutRes, error) {
+	if req.Reconfig != nil {
+		return nil, status.Error(codes.InvalidArgument, "membership changes are submitted with AdminRPC.Reconfigure")
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing id")
+	}
+	if req.Kv == nil || req.Kv.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	return s.submit(ctx, req)
+}
+
+// Delete removes a key once committed, like a Put
+func (s *Service) Delete(ctx context.Context, req *types.DeleteReq) (*types.PutRes, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing id")
+	}
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	return s.submit(ctx, &types.PutReq{Id: req.Id, Kv: &types.KeyValue{Key: req.Key}, Delete: true})
+}
+
+// submit adds req to the mempool
+func (s *Service) submit(ctx context.Context, req *types.PutReq) (*types.PutRes, error) {
+	s.mu.RLock()
+	learner := s.isLearner()
+	s.mu.RUnlock()
+	if learner {
+		// I never propose, so the request would never be committed
+		return nil, status.Error(codes.FailedPrecondition, "learners don't take requests, send them to a validator")
+	}
+	// The client may send its trace context in the gRPC metadata or in the request
+	ctx = tracing.Extract(tracing.ExtractGRPC(ctx), req.TraceContext)
+	ctx, span := s.tracer.Start(ctx, "Put",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("id", req.Id), attribute.String("key", req.Kv.GetKey())),
+	)
+	defer span.End()
+	// The request waits in the mempool until it's committed
+	_, mempoolSpan := s.tracer.Start(ctx, "mempool")
+	req.TraceContext = tracing.Inject(trace.ContextWithSpan(ctx, mempoolSpan))
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if round, ok := s.committedIDs[req.Id]; ok {
+		// a client retrying a request that was committed meanwhile
+		log.Infof("request %s was committed in round %d already", req.Id, round)
+		mempoolSpan.End()
+		return &types.PutRes{Id: req.Id}, nil
+	}
+	if old, ok := s.mempoolSpans[req.Id]; ok {
+		old.End()
+	}
+	s.mempoolSpans[req.Id] = mempoolSpan
 	s.reqs[req.Id] = req
 	return &types.PutRes{Id: req.Id}, nil
 }
@@ -34,8 +100,52 @@ func (s *Service) Get(ctx context.Context, req *types.GetReq) (*types.GetRes, er
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	val := s.db[req.Key]
-	return &types.GetRes{Kv: &types.KeyValue{
+	res := &types.GetRes{Kv: &types.KeyValue{
 		Key: req.Key,
 		Val: val,
-	}}, nil
+	}}
+	if req.Prove {
+		s.proveWrite(res)
+	}
+	return res, nil
+}
+
+func (s *Service) Scan(ctx context.Context, req *types.ScanReq) (*types.ScanRes, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []string
+	for key := range s.db {
+		if strings.HasPrefix(key, req.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	res := &types.ScanRes{}
+	if req.Limit > 0 && len(keys) > int(req.Limit) {
+		keys = keys[:req.Limit]
+		res.Truncated = true
+	}
+	for _, key := range keys {
+		res.Kvs = append(res.Kvs, &types.KeyValue{Key: key, Val: s.db[key]})
+	}
+	return res, nil
+}
+
+func (s *Service) ListEvidence(ctx context.Context, req *types.ListEvidenceReq) (*types.ListEvidenceRes, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := &types.ListEvidenceRes{}
+	for _, proof := range s.evidence {
+		if req.NodeIndex != nil && proof.First.NodeIndex != req.GetNodeIndex() {
+			continue
+		}
+		res.Proofs = append(res.Proofs, proof)
+	}
+	slices.SortFunc(res.Proofs, func(a, b *types.EquivocationProof) int {
+		return cmp.Or(
+			cmp.Compare(a.First.Msg.Round, b.First.Msg.Round),
+			cmp.Compare(a.First.NodeIndex, b.First.NodeIndex),
+		)
+	})
+	return res, nil
 }
@@ -0,0 +1,47 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPutValidation(t *testing.T) {
+	s := newTestService(t, 4)
+	ctx := context.Background()
+
+	for _, req := range []*types.PutReq{
+		{Id: "nil kv"},
+		{Id: "no key", Kv: &types.KeyValue{Val: "v"}},
+		{Id: "reconfig", Kv: &types.KeyValue{Key: "k"}, Reconfig: &types.Reconfig{}},
+	} {
+		_, err := s.Put(ctx, req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), req.Id)
+	}
+	_, err := s.Delete(ctx, &types.DeleteReq{Id: "no key"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Empty(t, s.reqs)
+
+	_, err = s.Put(ctx, &types.PutReq{Id: "ok", Kv: &types.KeyValue{Key: "k", Val: "v"}})
+	require.NoError(t, err)
+	require.Contains(t, s.reqs, "ok")
+}
+
+// Requests are deduplicated by id, so one without an id would shadow every later one
+func TestPutMissingID(t *testing.T) {
+	s := newTestService(t, 4)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := s.Put(ctx, &types.PutReq{Kv: &types.KeyValue{Key: "k", Val: "v"}})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = s.Delete(ctx, &types.DeleteReq{Key: "k"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+	require.Empty(t, s.reqs)
+	require.NotContains(t, s.committedIDs, "")
+}
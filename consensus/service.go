@@ -2,17 +2,32 @@ package consensus
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
+	"math"
+	"net"
+	"net/http"
 	"slices"
 	"sync"
 	"time"
 
+	"github.com/patrickmao1/beeftea/audit"
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/clock"
 	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/fault"
+	"github.com/patrickmao1/beeftea/gateway"
+	"github.com/patrickmao1/beeftea/metrics"
 	"github.com/patrickmao1/beeftea/network"
+	"github.com/patrickmao1/beeftea/tracing"
 	"github.com/patrickmao1/beeftea/types"
+	"github.com/patrickmao1/beeftea/utils"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/blake2b"
+	"google.golang.org/grpc"
 )
 
 // list of the proposals, in commitlocal check individually if the digests match, when they do then putReq
@@ -21,13 +36,80 @@ import (
 type roundState struct {
 	round             uint32
 	prevProposerProof []byte
-	seed              []byte
+	seed              []byte // nil until I have the round's beacon value, if the nodes run a beacon
 	minProposal       *types.Proposal
+	minPriority       uint32 // sortition priority of minProposal
 	proposals         []*types.Proposal
-	prepares          map[string]map[uint32]bool
-	commits           map[string]map[uint32]bool
+	prepares          map[string]map[uint32]bool // digest -> voters
+	commits           map[string]map[uint32]bool // digest -> voters
+	prepareVotes      map[uint32]*types.Envelope // voter -> first Prepare seen from it
+	commitVotes       map[uint32]*types.Envelope // voter -> first Commit seen from it
+	proposed          bool
 	prepared          bool
 	committed         bool
+	finalized         bool
+	applied           []byte // digest that reached the commit quorum
+	done              bool   // commitLocal has run for the applied digest
+
+	// BLS signatures of the votes and the certificates built from them, if the nodes have BLS keys
+	voteSigs  map[types.Phase]map[string]map[uint32][]byte // phase -> digest -> voter -> signature
+	prepareQC *types.QuorumCertificate
+	commitQC  *types.QuorumCertificate
+	// proof of the applied proposal for the keys it wrote, set by commitLocal
+	proof *commitProof
+
+	// when the round started and when I sent my Prepare and Commit, for metrics
+	startTime   time.Time
+	preparedAt  time.Time
+	committedAt time.Time
+	// when the last valid proposal arrived, for adapting the timeouts
+	lastProposalAt time.Time
+
+	// ctx carries the span of the round, phaseSpan is the span of the phase the round is in
+	ctx       context.Context
+	phaseSpan trace.Span
+}
+
+// appliedProposal returns the proposal that reached the commit quorum, nil if none did or I don't
+// have it
+func (r *roundState) appliedProposal() *types.Proposal {
+	if r.applied == nil {
+		return nil
+	}
+	for _, proposal := range r.proposals {
+		if bytes.Equal(proposal.Hash(), r.applied) {
+			return proposal
+		}
+	}
+	return nil
+}
+
+// startPhase ends the span of the previous phase and starts the span of the given phase
+func (r *roundState) startPhase(tracer trace.Tracer, phase string) {
+	if r.phaseSpan != nil {
+		r.phaseSpan.End()
+	}
+	_, r.phaseSpan = tracer.Start(r.ctx, "phase "+phase)
+}
+
+// endSpans ends the spans of the round and its current phase
+func (r *roundState) endSpans() {
+	if r.phaseSpan != nil {
+		r.phaseSpan.End()
+	}
+	trace.SpanFromContext(r.ctx).End()
+}
+
+// phaseCtx returns the context of the current phase, so that the messages sent in the phase are
+// traced as its children
+func (r *roundState) phaseCtx() context.Context {
+	if r == nil || r.ctx == nil {
+		return context.Background()
+	}
+	if r.phaseSpan == nil {
+		return r.ctx
+	}
+	return trace.ContextWithSpan(r.ctx, r.phaseSpan)
 }
 
 type Service struct {
@@ -39,91 +121,413 @@ type Service struct {
 
 	// Operations requested by users
 	reqs map[string]*types.PutReq // id -> PutReq
+	// Spans of the requests put to me, ended when the requests are committed
+	mempoolSpans map[string]trace.Span // id -> span
 
 	// The key-value store
 	db map[string]string
+	// Proofs of the last write of each key, if the nodes have BLS keys
+	writes map[string]*commitProof
+	// Ids of the requests committed in the last idempotencyRounds rounds, in commit order
+	committedIDs   map[string]uint32 // id -> round
+	committedOrder []committedID
+	// Clients watching the writes to the store
+	watchers map[*watcher]bool
+	// The last round whose proposal was applied to db, nil if none
+	lastCommittedRound *uint32
+
+	// Proofs of nodes voting for conflicting digests in the same round
+	evidence map[string]*types.EquivocationProof // node/round/phase -> proof
+
+	// Injected Byzantine behavior, nil if the node is honest
+	behavior   fault.Behavior
+	behaviorMu sync.RWMutex
+
+	metrics *metrics.Metrics
+
+	tracer          trace.Tracer
+	shutdownTracing tracing.ShutdownFunc
+
+	// Decisions of every round, nil if not logged
+	auditLog *audit.Logger
+
+	// Wakes up the main loop when the round is finalized or the node has to move on to
+	// catchUpRound
+	wake         chan struct{}
+	catchUpRound uint32
+	// Highest round each node sent a Timeout for, node -> round
+	timeoutRounds map[uint32]uint32
+
+	clock clock.Clock
+
+	pacer *pacer
+
+	// Servers of the external and admin RPCs, and the servers of the metrics and the HTTP gateway
+	// if they are served
+	rpcServer   *grpc.Server
+	adminServer *grpc.Server
+	httpServers []*http.Server
+	// Closed by Stop to end the main loop and the watches, and by the main loop when it returns
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+
+	// Randomness beacon that seeds sortition, nil if the nodes don't run one
+	beacon *beacon.Beacon
+
+	// BLS public keys of the peers to check quorum certificates with, nil if they don't all
+	// have one
+	blsKeys []*crypto.BLSPublicKey
+	// Certificate of the last commit quorum, nil if none
+	lastCommitQC *types.QuorumCertificate
+
+	// Committed membership changes that haven't taken effect yet, in commit order
+	pendingReconfigs []*types.ScheduledReconfig
+	// Number of membership changes committed, the seq of the next one
+	reconfigSeq uint32
+	// Round to start from and the proof that seeds it, if I joined a running cluster or caught up
+	// with it from a snapshot. The proof is cleared once used.
+	joinRound uint32
+	joinProof []byte
 }
 
 func NewService(config *types.Config) *Service {
 	s := &Service{
-		Config: config,
-		reqs:   make(map[string]*types.PutReq),
-		db:     make(map[string]string),
+		Config:        config,
+		reqs:          make(map[string]*types.PutReq),
+		mempoolSpans:  make(map[string]trace.Span),
+		db:            make(map[string]string),
+		writes:        make(map[string]*commitProof),
+		committedIDs:  make(map[string]uint32),
+		watchers:      make(map[*watcher]bool),
+		evidence:      make(map[string]*types.EquivocationProof),
+		metrics:       metrics.New(),
+		wake:          make(chan struct{}, 1),
+		timeoutRounds: make(map[uint32]uint32),
+		clock:         config.Clock,
+		stop:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	if s.clock == nil {
+		s.clock = clock.Real{}
+	}
+	for key, val := range config.InitialState {
+		s.db[key] = val
+	}
+	if config.JoinFrom != "" {
+		s.join(config.JoinFrom)
+	}
+	if config.ListenAddr == "" {
+		config.ListenAddr = "0.0.0.0:9090"
 	}
+	if config.RPCListenAddr == "" {
+		config.RPCListenAddr = "0.0.0.0:8080"
+	}
+	if config.AdminListenAddr == "" {
+		config.AdminListenAddr = "127.0.0.1:7070"
+	}
+	maxTimeout := config.MaxTimeout
+	if maxTimeout == 0 && !config.Responsive {
+		// the proposal phase must end within the round
+		maxTimeout = config.RoundDuration * 3 / 4
+	}
+	s.pacer = newPacer(config.AdaptiveTimeouts, config.ProposalDuration, config.RoundDuration, config.MinTimeout, maxTimeout)
 	log.Infof("config %+v", config)
+	if spec := config.Faults[config.MyIndex()]; spec != "" {
+		b, err := fault.Parse(spec)
+		if err != nil {
+			log.Fatalf("bad fault spec for node %d: %s", config.MyIndex(), err.Error())
+		}
+		if !config.AllowFaultInjection {
+			log.Fatalf("node %d has a fault spec, but fault injection isn't allowed", config.MyIndex())
+		}
+		log.Warnf("injecting Byzantine behavior \"%s\"", spec)
+		s.behavior = b
+	}
+	tp, shutdown, err := tracing.NewProvider(config.MyIndex(), config.OTLPEndpoint, config.TraceFile)
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %s", err.Error())
+	}
+	s.tracer = tp.Tracer(tracing.TracerName)
+	s.shutdownTracing = shutdown
+	if config.HasBLSKeys() {
+		for _, peer := range config.Peers {
+			var key *crypto.BLSPublicKey
+			if peer.BLSKey != nil {
+				key = peer.BLSKey.PublicKey()
+			}
+			s.blsKeys = append(s.blsKeys, key)
+		}
+	}
+	if config.Beacon != nil {
+		if slices.ContainsFunc(config.Peers, func(p *types.Peer) bool { return p.Learner }) {
+			log.Fatal("learners aren't supported with a randomness beacon")
+		}
+		s.beacon, err = beacon.New(config.Beacon, config.BeaconSecret)
+		if err != nil {
+			log.Fatalf("failed to set up the randomness beacon: %s", err.Error())
+		}
+	}
+	if config.AuditLogFile != "" {
+		s.auditLog, err = audit.Open(config.AuditLogFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 	s.Network = network.NewNetwork(
 		config.MyIndex(),
 		config.MyKey(),
+		config.ChainHash,
 		config.Peers,
 		s.handleMessage,
+		s.metrics,
 	)
+	s.Network.OnSnapshot(s.serveSnapshot)
+	s.rpcServer = grpc.NewServer(grpc.UnaryInterceptor(s.metrics.UnaryServerInterceptor()))
+	types.RegisterExternalRPCServer(s.rpcServer, s)
+	s.adminServer = grpc.NewServer(grpc.UnaryInterceptor(s.metrics.UnaryServerInterceptor()))
+	types.RegisterAdminRPCServer(s.adminServer, s)
+	s.metrics.GaugeFunc("kv_keys", "Number of keys in the key-value store.", func() float64 {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return float64(len(s.db))
+	})
+	s.metrics.GaugeFunc("proposal_timeout_seconds", "Current timeout of the proposal phase.", func() float64 {
+		proposal, _ := s.timeouts()
+		return proposal.Seconds()
+	})
+	s.metrics.GaugeFunc("round_timeout_seconds", "Current timeout of a round in responsive mode.", func() float64 {
+		_, round := s.timeouts()
+		return round.Seconds()
+	})
+	s.metrics.GaugeFunc("failed_rounds", "Consecutive rounds that had a proposal but didn't commit.", func() float64 {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return float64(s.pacer.failures)
+	})
+	s.metrics.GaugeFunc("mempool_reqs", "Client requests waiting to be committed.", func() float64 {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return float64(len(s.reqs))
+	})
 	return s
 }
 
-// Start starts the main consensus loop and the RPC servers
-func (s *Service) Start() {
+// Start listens on the configured addresses and starts the RPC servers and the main consensus
+// loop in the background. The addresses then hold the bound ones, so that port 0 is replaced with
+// the one the OS picked.
+func (s *Service) Start() error {
+	lis, err := s.listen()
+	if err != nil {
+		return err
+	}
 	log.Infoln("starting network")
-	go s.Network.Start()
+	s.Network.Start(lis.consensus)
 
 	log.Infoln("starting external RPC")
-	go s.startRPC()
+	go serveGRPC("External server", s.rpcServer, lis.rpc)
+	go serveGRPC("Admin server", s.adminServer, lis.admin)
 
-	log.Infoln("starting consensus main loop")
+	if lis.metrics != nil {
+		svr := &http.Server{Handler: s.metrics.Handler()}
+		s.httpServers = append(s.httpServers, svr)
+		go serveHTTP("Metrics server", svr, lis.metrics)
+	}
 
-	timer := time.NewTicker(time.Until(s.roundEndTime()))
-	for {
-		<-timer.C
-		proposalPhaseEnd := time.After(s.ProposalDuration)
+	if lis.http != nil {
+		// the HTTP/JSON twin of the external RPC for clients that don't speak gRPC
+		svr := &http.Server{Handler: gateway.Handler(s)}
+		s.httpServers = append(s.httpServers, svr)
+		go serveHTTP("HTTP gateway", svr, lis.http)
+	}
 
-		s.initRound()
+	log.Infoln("starting consensus main loop")
+	go func() {
+		defer close(s.stopped)
+		s.run()
+	}()
+	return nil
+}
 
-		s.propose()
+// Stop ends the main loop and the watches, lets the RPCs in flight finish, and flushes the spans
+// and the audit log. Once ctx is done the servers are stopped without waiting. Must be called
+// after Start.
+func (s *Service) Stop(ctx context.Context) error {
+	log.Infoln("stopping")
+	s.stopOnce.Do(func() { close(s.stop) })
+	var errs []error
+	for _, svr := range s.httpServers {
+		if err := svr.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+			svr.Close()
+		}
+	}
+	utils.GracefulStop(ctx, s.rpcServer)
+	utils.GracefulStop(ctx, s.adminServer)
+	<-s.stopped
+	s.Network.Stop(ctx)
+
+	errs = append(errs, s.shutdownTracing(ctx))
+	if s.auditLog != nil {
+		errs = append(errs, s.auditLog.Close())
+	}
+	return errors.Join(errs...)
+}
 
-		// Blocks until the proposal phase has ended
-		<-proposalPhaseEnd
+// listeners of my servers, nil for the ones that aren't served
+type listeners struct {
+	consensus, rpc, admin, metrics, http net.Listener
+}
 
-		err := s.prepare()
-		if err != nil {
-			log.Error(err)
+// listen listens on the configured addresses, or takes Config.Listener for the consensus server,
+// and replaces the addresses with the bound ones
+func (s *Service) listen() (*listeners, error) {
+	l := &listeners{consensus: s.Listener}
+	servers := []struct {
+		lis  *net.Listener
+		addr *string
+	}{
+		{&l.consensus, &s.ListenAddr},
+		{&l.rpc, &s.RPCListenAddr},
+		{&l.admin, &s.AdminListenAddr},
+		{&l.metrics, &s.MetricsListenAddr},
+		{&l.http, &s.HTTPListenAddr},
+	}
+	for i, svr := range servers {
+		if *svr.lis == nil && *svr.addr != "" {
+			lis, err := net.Listen("tcp", *svr.addr)
+			if err != nil {
+				for _, opened := range servers[:i] {
+					if *opened.lis != nil {
+						(*opened.lis).Close()
+					}
+				}
+				return nil, err
+			}
+			*svr.lis = lis
+		}
+		if *svr.lis != nil {
+			*svr.addr = (*svr.lis).Addr().String()
 		}
-
-		// Refresh round timer
-		timer.Reset(time.Until(s.roundEndTime()))
 	}
+	return l, nil
+}
+
+// timeouts returns the current proposal phase and round timeouts
+func (s *Service) timeouts() (proposal, round time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pacer.timeouts()
+}
+
+func (s *Service) hasProposal() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.minProposal != nil
 }
 
-func (s *Service) initRound() {
+func (s *Service) initRound(currentRound uint32) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
-	currentRound := s.round()
+	if s.roundState != nil {
+		outcome := "skipped"
+		if applied := s.roundState.appliedProposal(); applied != nil {
+			outcome = "committed"
+			if len(applied.Reqs) == 0 {
+				outcome = "empty"
+			}
+		}
+		s.metrics.Rounds.WithLabelValues(outcome).Inc()
+		if s.roundState.minProposal != nil && !s.roundState.finalized {
+			s.pacer.fail()
+		}
+		s.roundState.endSpans()
+		if err := s.auditLog.Log(s.roundState.auditEntry(s.MyIndex())); err != nil {
+			log.Errorf("failed to write audit log: %s", err.Error())
+		}
+	}
+	s.metrics.Round.Set(float64(currentRound))
+	s.applyReconfigs(currentRound)
+	s.pruneEvidence(currentRound)
 
 	state := &roundState{
-		round:    currentRound,
-		prepares: make(map[string]map[uint32]bool),
-		commits:  make(map[string]map[uint32]bool),
+		startTime:    s.clock.Now(),
+		round:        currentRound,
+		prepares:     make(map[string]map[uint32]bool),
+		commits:      make(map[string]map[uint32]bool),
+		prepareVotes: make(map[uint32]*types.Envelope),
+		commitVotes:  make(map[uint32]*types.Envelope),
+		voteSigs:     make(map[types.Phase]map[string]map[uint32][]byte),
 	}
-	if s.roundState == nil {
-		initSeed := blake2b.Sum256([]byte("beeftea"))
+	if s.beacon != nil {
+		// Wait for the beacon value if I don't have it yet, see setBeaconSeed
+		if sig, ok := s.beacon.Value(currentRound); ok {
+			state.seed = beaconSeed(currentRound, sig)
+		}
+	} else if s.joinProof != nil {
+		state.prevProposerProof = s.joinProof
+		s.joinProof = nil
+	} else if s.roundState == nil {
+		initSeed := blake2b.Sum256(slices.Concat([]byte("beeftea"), s.ChainHash))
 		state.prevProposerProof = initSeed[:]
 	} else {
-		// Use the latest known proposer proof as PP_{r-1}
-		if s.minProposal == nil {
-			state.prevProposerProof = s.prevProposerProof
+		// Use the proof of the proposal committed in the last round as PP_{r-1}, so that all
+		// nodes that committed it agree on the seed. If nothing was committed, keep PP_{r-2}.
+		if applied := s.roundState.appliedProposal(); applied != nil {
+			state.prevProposerProof = applied.ProposerProof
 		} else {
-			state.prevProposerProof = s.minProposal.ProposerProof
+			state.prevProposerProof = s.prevProposerProof
 		}
 	}
-	state.seed = computeRoundSeed(currentRound, state.prevProposerProof)
+	if s.beacon == nil {
+		state.seed = computeRoundSeed(currentRound, state.prevProposerProof)
+	}
+	state.ctx, _ = s.tracer.Start(context.Background(), "round",
+		trace.WithAttributes(attribute.Int64("round", int64(currentRound))),
+	)
+	state.startPhase(s.tracer, "proposal")
 	s.roundState = state
-	log.Infof("new round %d", s.round())
+	s.mu.Unlock()
+	log.Infof("new round %d", currentRound)
+
+	// Only after the round state is set up can the messages buffered for this round be handled
+	s.Network.AdvanceRound(currentRound)
+
+	if s.beacon != nil {
+		s.beacon.Prune(currentRound)
+		s.sendBeaconShares(currentRound)
+	}
+}
+
+// backupProposer returns the node that proposes in the round whatever its score. It is derived
+// from the seed so that all nodes agree on it and it changes every round. Removed peers are
+// skipped, and so are learners.
+func (s *Service) backupProposer() uint32 {
+	var active []uint32
+	for i, peer := range s.Peers {
+		if peer.IsValidator() {
+			active = append(active, uint32(i))
+		}
+	}
+	h := blake2b.Sum256(slices.Concat([]byte("backup"), s.seed))
+	return active[binary.BigEndian.Uint32(h[:4])%uint32(len(active))]
+}
+
+// ProposalThreshold computes the threshold for sortition in a network of n nodes, or of a total
+// weight of n: each sub-user is selected with probability ProposalThreshold / 2^32
+func ProposalThreshold(n int) uint32 {
+	// T = f(N) such that the probability of no sub-user being selected is 0.01
+	// f(N) = 1 - e^(-4.60517/N),
+	const constant = 4.60517
+	t := 1.0 - math.Exp(-constant/float64(n))
+	return uint32(float64(math.MaxUint32) * t)
 }
 
 func computeRoundSeed(round uint32, prevPP []byte) (seed []byte) {
 	// compute s_r, the seed for round r: s_r = r | PP_{r-1}
 	// where PP_{r-1} is the proposer proof of the latest known proposer proof of the last round.
 	// Mixing r into the calculation ensures that s_r changes for every r.
-	// We use HASH("beeftea") as a PP_0.
+	// We use HASH("beeftea" | genesis hash) as a PP_0.
 	_seed := blake2b.Sum256(prevPP)
 	seed = _seed[:]
 	b := make([]byte, 4)
@@ -135,33 +539,61 @@ func (s *Service) propose() {
 	// For the ith peer
 	// proposer proof: L_{i,r} = SIGN_i(s_r)
 	// proposal score: S_{i,r} = HASH(L_{i,r})
-	proposalScore, proposerProof := crypto.VRF(s.MyKey(), s.seed)
-	if proposalScore >= s.ProposalThreshold {
+	// Nodes with sub-users selected by sortition propose if they have requests, and the backup
+	// proposer always does so that every round has a proposal. If it has no selected sub-users it
+	// loses to any other proposal. An empty mempool makes a no-op proposal, which still commits
+	// the round and moves the seed on.
+	s.mu.Lock()
+	// Without the seed I propose once the beacon value arrives
+	if s.seed == nil || s.roundState.proposed || !s.isMember() || s.isLearner() {
+		s.mu.Unlock()
 		return
 	}
-
-	if len(s.reqs) == 0 {
+	s.roundState.proposed = true
+	_, proposerProof := crypto.VRF(s.MyKey(), s.seed)
+	selected, _ := s.sortition(s.MyIndex(), proposerProof)
+	isBackup := s.MyIndex() == s.backupProposer()
+	if !isBackup && (selected == 0 || len(s.reqs) == 0) {
+		s.mu.Unlock()
 		return
 	}
+	if isBackup {
+		log.Infof("Proposing %d reqs as the backup proposer", len(s.reqs))
+	} else {
+		log.Infof("Proposing %d reqs with %d selected sub-users", len(s.reqs), selected)
+	}
 
-	log.Infof("Proposing %d reqs", len(s.reqs))
-
-	s.mu.Lock()
 	reqs := make([]*types.PutReq, 0, len(s.reqs))
+	var links []trace.Link
 	for _, req := range s.reqs {
 		reqs = append(reqs, req)
+		if link, ok := tracing.Link(req.TraceContext); ok {
+			links = append(links, link)
+		}
 	}
+	// The proposal batches requests from many traces, link to them instead of picking a parent
+	ctx, span := s.tracer.Start(s.roundState.phaseCtx(), "propose",
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("reqs", len(reqs))),
+	)
+	defer span.End()
 	proposal := &types.Proposal{
 		Reqs:          reqs,
 		ProposerProof: proposerProof,
 		ProposerIndex: s.MyIndex(),
 	}
+	proposal.ProposalTimeoutMs, proposal.RoundTimeoutMs = s.pacer.suggest()
+	// the round may move on once I unlock
+	round := s.roundState.round
+	if s.beacon != nil {
+		proposal.Beacon, _ = s.beacon.Value(round)
+	}
 	s.mu.Unlock()
 
-	msg := &types.Message{Type: &types.Message_Proposal{
+	msg := &types.Message{Round: round, Type: &types.Message_Proposal{
 		Proposal: proposal,
 	}}
-	s.Broadcast(msg)
+	s.broadcast(ctx, msg)
 }
 
 // prepare implements phase 2: select the minimal valid proposal
@@ -175,7 +607,7 @@ func (s *Service) prepare() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.minProposal == nil {
+	if s.minProposal == nil || s.isLearner() {
 		return nil
 	}
 	if s.prepared {
@@ -184,43 +616,27 @@ func (s *Service) prepare() error {
 
 	// hash the proposal
 	digest := s.minProposal.Hash()
-	key := string(digest[:8])
-	//malicious case:
-	if s.MyIndex() == 4 {
-		switch s.db["maliciousMode"] {
-		case "wrongPrepareMessage": //wrongprepare message
-			fakeDigest := []byte("abcdefg12345678")
-			log.Infof("Sending malicious prepare!!!!!! fakeDigest %x", fakeDigest)
-			pr := &types.Prepare{ProposalDigest: fakeDigest}
-			msg := &types.Message{Type: &types.Message_Prepare{Prepare: pr}}
-			s.Broadcast(msg)
-		case "fourWrongBroadcasts":
-			fakeDigest := []byte("abcdefg12345678")
-			pr := &types.Prepare{ProposalDigest: fakeDigest}
-			msg := &types.Message{Type: &types.Message_Prepare{Prepare: pr}}
-			for i := 0; i < 4; i++ {
-				log.Infof("Sending malicious prepare!!!!!! fakeDigest %x", fakeDigest)
-				s.Broadcast(msg)
-			}
-		default:
-			//normal case:
-			// broadcast Prepare message
-			pr := &types.Prepare{ProposalDigest: digest}
-			msg := &types.Message{Type: &types.Message_Prepare{Prepare: pr}}
-			s.Broadcast(msg)
-		}
-	} else {
-		pr := &types.Prepare{ProposalDigest: digest}
-		msg := &types.Message{Type: &types.Message_Prepare{Prepare: pr}}
-		s.Broadcast(msg)
-	}
+	key := string(digest)
+
+	pr := &types.Prepare{ProposalDigest: digest, BlsSig: s.signVote(types.Phase_PHASE_PREPARE, digest)}
+	msg := &types.Message{Round: s.roundState.round, Type: &types.Message_Prepare{Prepare: pr}}
+	s.roundState.startPhase(s.tracer, "prepare")
+	s.broadcast(s.roundState.phaseCtx(), msg)
 
 	if s.prepares[key] == nil {
 		s.prepares[key] = make(map[uint32]bool)
 	}
 	s.prepares[key][s.MyIndex()] = true
 	s.prepared = true
-	log.Infof("round %d: sent Prepare for digest %s", s.round(), key)
+	s.preparedAt = s.clock.Now()
+	if !s.lastProposalAt.IsZero() {
+		s.pacer.observeProposal(s.lastProposalAt.Sub(s.startTime))
+	}
+	s.metrics.PhaseDuration.WithLabelValues("proposal").Observe(s.preparedAt.Sub(s.startTime).Seconds())
+	log.Infof("round %d: sent Prepare for digest %x", s.roundState.round, digest)
+
+	// My own vote may complete the quorum if others' prepares arrived earlier
+	s.checkPrepareQuorum(digest)
 	return nil
 }
 
@@ -229,55 +645,107 @@ func (s *Service) commit(proposalDigest []byte) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if len(proposalDigest) == 0 {
-		return errors.New("empty proposal digest")
+	if err := checkDigest(proposalDigest); err != nil {
+		return err
 	}
-	key := string(proposalDigest[:8])
-	if s.committed {
+	key := string(proposalDigest)
+	if s.committed || s.isLearner() {
 		return nil
 	}
 
-	cm := &types.Commit{ProposalDigest: proposalDigest}
-	msg := &types.Message{Type: &types.Message_Commit{Commit: cm}}
-	s.Broadcast(msg)
+	cm := &types.Commit{ProposalDigest: proposalDigest, BlsSig: s.signVote(types.Phase_PHASE_COMMIT, proposalDigest)}
+	msg := &types.Message{Round: s.roundState.round, Type: &types.Message_Commit{Commit: cm}}
+	s.roundState.startPhase(s.tracer, "commit")
+	s.broadcast(s.roundState.phaseCtx(), msg)
 
 	if s.commits[key] == nil {
 		s.commits[key] = make(map[uint32]bool)
 	}
 	s.commits[key][s.MyIndex()] = true
 	s.committed = true
-	log.Infof("round %d: sent Commit for digest %s", s.round(), key)
+	s.committedAt = s.clock.Now()
+	if !s.preparedAt.IsZero() {
+		s.metrics.PhaseDuration.WithLabelValues("prepare").Observe(s.committedAt.Sub(s.preparedAt).Seconds())
+	}
+	log.Infof("round %d: sent Commit for digest %x", s.roundState.round, proposalDigest)
+
+	// My own vote may complete the quorum if others' commits arrived earlier
+	s.checkCommitQuorum(proposalDigest)
 	return nil
 }
 
-func (s *Service) commitLocal(digest []byte) {
+// commitLocal applies the proposal with the given digest among those received in state's round
+func (s *Service) commitLocal(state *roundState, digest []byte) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, proposal := range s.proposals {
+	for _, proposal := range state.proposals {
 		if bytes.Equal(proposal.Hash(), digest) {
+			behavior := s.getBehavior()
 			for _, req := range proposal.Reqs {
-				//malicious case:
-				if s.MyIndex() == 4 && s.db["maliciousMode"] == "commitWrongValue" {
-					val := "some evil value!!!"
-					log.Infof("Committing malicious value: \"%s\"", val)
-					s.db[req.Kv.Key] = val
-				} else {
-					s.db[req.Kv.Key] = req.Kv.Val
-					delete(s.reqs, req.Id)
+				// Continue the trace of the request, which was started by the Put on the proposer
+				_, span := s.tracer.Start(tracing.Extract(context.Background(), req.TraceContext), "commitLocal",
+					trace.WithLinks(trace.LinkFromContext(state.ctx)),
+					trace.WithAttributes(attribute.Int64("round", int64(state.round)), attribute.String("key", req.Kv.GetKey())),
+				)
+				if req.Reconfig != nil {
+					s.scheduleReconfig(state.round, req.Reconfig)
+				} else if kv := req.Kv; kv != nil && req.Delete {
+					delete(s.db, kv.Key)
+					s.recordWrite(state, proposal, kv.Key)
+					s.notifyWatchers(&types.WatchEvent{Kv: &types.KeyValue{Key: kv.Key}, Round: state.round, Deleted: true})
+				} else if kv != nil {
+					if behavior != nil {
+						kv = behavior.OnApply(kv)
+					}
+					s.db[kv.Key] = kv.Val
+					s.recordWrite(state, proposal, kv.Key)
+					s.notifyWatchers(&types.WatchEvent{Kv: kv, Round: state.round})
+				}
+				delete(s.reqs, req.Id)
+				s.rememberCommitted(req.Id, state.round)
+				if mempoolSpan, ok := s.mempoolSpans[req.Id]; ok {
+					mempoolSpan.End()
+					delete(s.mempoolSpans, req.Id)
 				}
+				span.End()
 			}
+			round := state.round
+			s.lastCommittedRound = &round
+			s.pacer.adopt(proposal.ProposalTimeoutMs, proposal.RoundTimeoutMs)
 			break
 		}
 
 	}
+	state.done = true
+}
+
+// broadcast sends msg to all peers, letting the injected Byzantine behavior tamper with it first.
+// The envelopes are traced as children of the span in ctx.
+func (s *Service) broadcast(ctx context.Context, msg *types.Message) {
+	behavior := s.getBehavior()
+	if behavior == nil {
+		s.Broadcast(ctx, msg)
+		return
+	}
+	for _, out := range behavior.OnSend(msg, s.NumPeers()) {
+		if out.Delay > 0 {
+			time.AfterFunc(out.Delay, func() { s.Broadcast(ctx, out.Msg, out.Indices...) })
+		} else {
+			s.Broadcast(ctx, out.Msg, out.Indices...)
+		}
+	}
 }
 
-func (s *Service) round() uint32 {
-	return uint32(time.Since(s.InitTime) / s.RoundDuration)
+// InjectBehavior makes the node act according to b from now on. A nil b makes the node honest.
+func (s *Service) InjectBehavior(b fault.Behavior) {
+	s.behaviorMu.Lock()
+	defer s.behaviorMu.Unlock()
+	s.behavior = b
 }
 
-func (s *Service) roundEndTime() time.Time {
-	elapsed := time.Duration(s.round()+1) * s.RoundDuration
-	return s.InitTime.Add(elapsed)
+func (s *Service) getBehavior() fault.Behavior {
+	s.behaviorMu.RLock()
+	defer s.behaviorMu.RUnlock()
+	return s.behavior
 }
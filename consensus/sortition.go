@@ -0,0 +1,62 @@
+package consensus
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Sortition as in Algorand: a peer of weight w runs w sub-users, each of which is selected to
+// propose with probability p = ProposalThreshold / 2^32. The number of selected sub-users j follows
+// the binomial distribution B(w, p) and is drawn from the peer's VRF output, so that it can't be
+// chosen by the peer and anyone can check it. The peer's priority is the lowest hash of its proof
+// and the index of a selected sub-user, and the proposal with the lowest priority wins. A peer of
+// twice the weight is about twice as likely to win the round.
+
+// subUsers returns how many of the weight sub-users with a VRF score of score are selected. Low
+// scores select sub-users, so that for a weight of 1 a peer is selected about when its score is
+// below the threshold.
+func subUsers(score uint32, weight uint64, threshold uint32) uint64 {
+	p := float64(threshold) / (math.MaxUint32 + 1)
+	x := 1 - float64(score)/(math.MaxUint32+1) // uniform in (0, 1], high for low scores
+	if p == 0 {
+		return 0
+	}
+	if p >= 1 {
+		return weight
+	}
+	// Walk the cumulative distribution until it passes x: j sub-users are selected if x falls
+	// in [P(X < j), P(X <= j))
+	pmf := math.Pow(1-p, float64(weight))
+	cdf := pmf
+	j := uint64(0)
+	for x >= cdf && j < weight {
+		pmf *= float64(weight-j) / float64(j+1) * p / (1 - p)
+		j++
+		cdf += pmf
+	}
+	return j
+}
+
+// priority returns the priority of a peer with the given proposer proof and number of selected
+// sub-users, the lowest wins. Peers with no selected sub-users have the lowest possible priority.
+func priority(proof []byte, selected uint64) uint32 {
+	best := uint32(math.MaxUint32)
+	for i := uint64(0); i < selected; i++ {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, i)
+		h := blake2b.Sum256(slices.Concat(proof, b))
+		best = min(best, binary.BigEndian.Uint32(h[:4]))
+	}
+	return best
+}
+
+// sortition returns the number of selected sub-users and the priority of peer idx given its
+// proposer proof for the round
+func (s *Service) sortition(idx uint32, proof []byte) (selected uint64, prio uint32) {
+	selected = subUsers(crypto.RngFromProof(proof), s.Weight(idx), s.ProposalThreshold)
+	return selected, priority(proof, selected)
+}
@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubUsers(t *testing.T) {
+	threshold := uint32(math.MaxUint32 / 10) // p = 0.1
+
+	// a peer of weight 1 is selected when its score is below the threshold
+	require.EqualValues(t, 1, subUsers(0, 1, threshold))
+	require.EqualValues(t, 1, subUsers(threshold-1, 1, threshold))
+	require.EqualValues(t, 0, subUsers(threshold+1, 1, threshold))
+	require.EqualValues(t, 0, subUsers(math.MaxUint32, 1, threshold))
+
+	require.EqualValues(t, 0, subUsers(0, 10, 0))
+	require.EqualValues(t, 10, subUsers(0, 10, math.MaxUint32))
+
+	// the number of selected sub-users follows B(w, p)
+	rng := rand.New(rand.NewSource(1))
+	const samples = 20000
+	total := uint64(0)
+	for i := 0; i < samples; i++ {
+		j := subUsers(rng.Uint32(), 50, threshold)
+		require.LessOrEqual(t, j, uint64(50))
+		total += j
+	}
+	require.InDelta(t, 5, float64(total)/samples, 0.1)
+}
+
+func TestPriority(t *testing.T) {
+	proof := []byte("proof")
+	require.EqualValues(t, math.MaxUint32, priority(proof, 0))
+	// more selected sub-users can only improve the priority
+	require.LessOrEqual(t, priority(proof, 5), priority(proof, 1))
+	require.Less(t, priority(proof, 1), uint32(math.MaxUint32))
+}
+
+func TestWeightedQuorum(t *testing.T) {
+	s := newTestService(t, 5)
+	s.Peers[0].Weight = 6 // total 10
+
+	// more than 2/3 of the weight, not of the nodes
+	require.True(t, s.isQuorum([]uint32{0, 1}))
+	require.False(t, s.isQuorum([]uint32{1, 2, 3, 4}))
+	require.True(t, s.oneHonest([]uint32{0}))
+	require.False(t, s.oneHonest([]uint32{1, 2}))
+	require.True(t, s.oneHonest([]uint32{1, 2, 3, 4}))
+
+	// Timeouts from two light nodes don't make me time out
+	s.roundState = &roundState{round: 3}
+	require.NoError(t, s.handleTimeout(3, 1))
+	require.NoError(t, s.handleTimeout(3, 2))
+	require.Zero(t, s.catchUpRound)
+	_, sent := s.timeoutRounds[0]
+	require.False(t, sent)
+
+	// a heavy node does, and its Timeout and mine make a timeout certificate
+	s = newTestService(t, 5)
+	s.Peers[1].Weight = 6
+	s.roundState = &roundState{round: 3}
+	require.NoError(t, s.handleTimeout(3, 1))
+	require.EqualValues(t, 3, s.timeoutRounds[0])
+	require.EqualValues(t, 4, s.catchUpRound)
+}
@@ -0,0 +1,107 @@
+package consensus
+
+import (
+	"time"
+)
+
+const (
+	// the timeouts are this many times the observed latencies
+	timeoutFactor = 2
+	// a committed timeout shrinks to no less than this fraction of the previous one per round
+	timeoutDecay = 2
+	// failed rounds double the timeouts at most this many times
+	maxBackoff = 6
+	// weight of a new sample in the latency moving averages
+	latencyWeight = 0.2
+
+	defaultMinTimeout = 50 * time.Millisecond
+)
+
+// pacer adapts the proposal and round timeouts to the network. The base timeouts are agreed on
+// through consensus: proposers suggest them from the latencies they observed and every node adopts
+// the values in the committed proposal, so that nodes that apply the same proposals use the same
+// timeouts. On top of that the timeouts double for every consecutive round that had a proposal
+// but didn't commit it, which nodes observe alike as long as they are in the same round.
+type pacer struct {
+	adaptive bool
+	min, max time.Duration
+
+	// base timeouts, from the last committed proposal
+	proposal, round time.Duration
+	// consecutive rounds that had a proposal but didn't commit
+	failures int
+
+	// moving averages of the time from the start of a round until the last proposal arrived
+	// and until the commit quorum was reached, 0 if nothing was observed yet
+	proposalLatency, commitLatency time.Duration
+}
+
+func newPacer(adaptive bool, proposal, round, min, max time.Duration) *pacer {
+	if min == 0 {
+		min = defaultMinTimeout
+	}
+	if max == 0 {
+		max = 8 * round
+	}
+	return &pacer{adaptive: adaptive, min: min, max: max, proposal: proposal, round: round}
+}
+
+// timeouts returns the current proposal and round timeouts, including the backoff
+func (p *pacer) timeouts() (proposal, round time.Duration) {
+	if !p.adaptive {
+		return p.proposal, p.round
+	}
+	backoff := time.Duration(1) << min(p.failures, maxBackoff)
+	return p.clamp(p.proposal * backoff), p.clamp(p.round * backoff)
+}
+
+func (p *pacer) observeProposal(latency time.Duration) {
+	p.proposalLatency = average(p.proposalLatency, latency)
+}
+
+func (p *pacer) observeCommit(latency time.Duration) {
+	p.commitLatency = average(p.commitLatency, latency)
+}
+
+// suggest returns the base timeouts to put in my proposal, in milliseconds. They follow the
+// observed latencies but shrink by at most timeoutDecay per committed round.
+func (p *pacer) suggest() (proposalMs, roundMs uint64) {
+	if !p.adaptive {
+		return 0, 0
+	}
+	proposal, round := p.proposal, p.round
+	if p.proposalLatency > 0 {
+		proposal = max(timeoutFactor*p.proposalLatency, p.proposal/timeoutDecay)
+	}
+	if p.commitLatency > 0 {
+		round = max(timeoutFactor*p.commitLatency, p.round/timeoutDecay)
+	}
+	return uint64(p.clamp(proposal).Milliseconds()), uint64(p.clamp(round).Milliseconds())
+}
+
+// adopt sets the base timeouts to those of a committed proposal and resets the backoff
+func (p *pacer) adopt(proposalMs, roundMs uint64) {
+	p.failures = 0
+	if !p.adaptive || proposalMs == 0 || roundMs == 0 {
+		return
+	}
+	// the values come from a possibly Byzantine proposer
+	p.proposal = p.clamp(time.Duration(proposalMs) * time.Millisecond)
+	p.round = p.clamp(time.Duration(roundMs) * time.Millisecond)
+}
+
+// fail backs off after a round that had a proposal but didn't commit
+func (p *pacer) fail() {
+	p.failures++
+}
+
+func (p *pacer) clamp(d time.Duration) time.Duration {
+	return min(max(d, p.min), p.max)
+}
+
+func average(avg, sample time.Duration) time.Duration {
+	if avg == 0 {
+		return sample
+	}
+	return time.Duration(latencyWeight*float64(sample) + (1-latencyWeight)*float64(avg))
+}
@@ -0,0 +1,76 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacerBackoff(t *testing.T) {
+	p := newPacer(true, 100*time.Millisecond, time.Second, 0, 10*time.Second)
+
+	p.fail()
+	p.fail()
+	proposal, round := p.timeouts()
+	require.Equal(t, 400*time.Millisecond, proposal)
+	require.Equal(t, 4*time.Second, round)
+
+	// capped at MaxTimeout
+	for i := 0; i < 10; i++ {
+		p.fail()
+	}
+	_, round = p.timeouts()
+	require.Equal(t, 10*time.Second, round)
+
+	// a commit resets the backoff
+	p.adopt(0, 0)
+	proposal, round = p.timeouts()
+	require.Equal(t, 100*time.Millisecond, proposal)
+	require.Equal(t, time.Second, round)
+}
+
+func TestPacerAdapt(t *testing.T) {
+	p := newPacer(true, 100*time.Millisecond, time.Second, 0, 10*time.Second)
+
+	// fast network: the suggested timeouts shrink by at most half per commit
+	p.observeProposal(5 * time.Millisecond)
+	p.observeCommit(60 * time.Millisecond)
+	proposalMs, roundMs := p.suggest()
+	require.EqualValues(t, 50, proposalMs)
+	require.EqualValues(t, 500, roundMs)
+	p.adopt(proposalMs, roundMs)
+	proposalMs, roundMs = p.suggest()
+	require.EqualValues(t, 50, proposalMs) // MinTimeout
+	require.EqualValues(t, 250, roundMs)
+	p.adopt(proposalMs, roundMs)
+	_, roundMs = p.suggest()
+	require.EqualValues(t, 125, roundMs)
+	p.adopt(proposalMs, roundMs)
+	_, roundMs = p.suggest()
+	require.EqualValues(t, 120, roundMs)
+
+	// slow network: the timeouts grow right away
+	p.observeCommit(3 * time.Second)
+	_, roundMs = p.suggest()
+	require.Greater(t, roundMs, uint64(500))
+
+	// timeouts from a Byzantine proposer are clamped
+	p.adopt(1, 1<<40)
+	proposal, round := p.timeouts()
+	require.Equal(t, defaultMinTimeout, proposal)
+	require.Equal(t, 10*time.Second, round)
+}
+
+func TestPacerStatic(t *testing.T) {
+	p := newPacer(false, 100*time.Millisecond, time.Second, 0, 0)
+	p.fail()
+	p.observeCommit(time.Millisecond)
+	proposalMs, roundMs := p.suggest()
+	require.Zero(t, proposalMs)
+	require.Zero(t, roundMs)
+	p.adopt(1000, 1000)
+	proposal, round := p.timeouts()
+	require.Equal(t, 100*time.Millisecond, proposal)
+	require.Equal(t, time.Second, round)
+}
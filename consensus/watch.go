@@ -0,0 +1,66 @@
+package consensus
+
+import (
+	"strings"
+
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// events a watcher can fall behind by before it is dropped
+const watchBuffer = 1024
+
+type watcher struct {
+	prefix string
+	events chan *types.WatchEvent
+}
+
+// Watch streams the writes and deletes committed from now on to the keys that start with req.Prefix. A
+// client that doesn't keep up is disconnected with ResourceExhausted rather than slowing down
+// commits, and should read the keys again before watching anew.
+func (s *Service) Watch(req *types.WatchReq, stream types.ExternalRPC_WatchServer) error {
+	w := &watcher{prefix: req.Prefix, events: make(chan *types.WatchEvent, watchBuffer)}
+	s.mu.Lock()
+	s.watchers[w] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.stop:
+			return status.Error(codes.Unavailable, "node is shutting down")
+		case e, ok := <-w.events:
+			if !ok {
+				return status.Errorf(codes.ResourceExhausted, "fell more than %d events behind", watchBuffer)
+			}
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// notifyWatchers hands a committed write or delete to the watchers of its key. Must be called
+// with s.mu held.
+func (s *Service) notifyWatchers(e *types.WatchEvent) {
+	for w := range s.watchers {
+		if !strings.HasPrefix(e.Kv.Key, w.prefix) {
+			continue
+		}
+		select {
+		case w.events <- e:
+		default:
+			log.Warnf("dropping a watcher of prefix %q that fell behind", w.prefix)
+			close(w.events)
+			delete(s.watchers, w)
+		}
+	}
+}
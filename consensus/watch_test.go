@@ -0,0 +1,30 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyWatchers(t *testing.T) {
+	s := newTestService(t, 4)
+	all := &watcher{events: make(chan *types.WatchEvent, 1)}
+	users := &watcher{prefix: "user/", events: make(chan *types.WatchEvent, 2)}
+	s.watchers[all] = true
+	s.watchers[users] = true
+
+	s.notifyWatchers(&types.WatchEvent{Kv: &types.KeyValue{Key: "user/1", Val: "a"}, Round: 3})
+	s.notifyWatchers(&types.WatchEvent{Kv: &types.KeyValue{Key: "config", Val: "b"}, Round: 4})
+	require.Len(t, users.events, 1)
+	e := <-users.events
+	require.Equal(t, "user/1", e.Kv.Key)
+	require.EqualValues(t, 3, e.Round)
+
+	// the first watcher fell behind at the second event and was dropped
+	require.NotContains(t, s.watchers, all)
+	require.Contains(t, s.watchers, users)
+	<-all.events
+	_, ok := <-all.events
+	require.False(t, ok)
+}
@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/bits"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	"github.com/cloudflare/circl/sign/bls"
+	"golang.org/x/crypto/blake2b"
+)
+
+// BLS signatures on BLS12-381 with public keys in G2 and signatures in G1, so that signatures,
+// which are sent around, are the short ones. Signatures of the same message by several keys
+// aggregate into a single signature that verifies against the sum of the keys in one pairing check.
+// Summing keys is only safe against rogue-key attacks because the keys come from the config rather
+// than from the signers themselves.
+type (
+	BLSPrivateKey = bls.PrivateKey[bls.KeyG2SigG1]
+	BLSPublicKey  = bls.PublicKey[bls.KeyG2SigG1]
+)
+
+func GenBLSKey() *BLSPrivateKey {
+	ikm := make([]byte, 32)
+	if _, err := rand.Read(ikm); err != nil {
+		panic(err)
+	}
+	return BLSKeyFromSeed(ikm)
+}
+
+// BLSKeyFromSeed derives a BLS key from seed deterministically
+func BLSKeyFromSeed(seed []byte) *BLSPrivateKey {
+	ikm := blake2b.Sum256(seed)
+	key, err := bls.KeyGen[bls.KeyG2SigG1](ikm[:], nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func BLSSign(key *BLSPrivateKey, msg []byte) []byte {
+	return bls.Sign(key, msg)
+}
+
+func BLSVerify(key *BLSPublicKey, msg []byte, sig []byte) bool {
+	return bls.Verify(key, msg, sig)
+}
+
+// AggregateBLS combines signatures into one
+func AggregateBLS(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("no signatures to aggregate")
+	}
+	return bls.Aggregate(bls.KeyG2SigG1{}, sigs)
+}
+
+// VerifyAggregateBLS checks that sig aggregates signatures of msg by all of keys
+func VerifyAggregateBLS(keys []*BLSPublicKey, msg []byte, sig []byte) bool {
+	if len(keys) == 0 {
+		return false
+	}
+	sum := new(bls12381.G2)
+	sum.SetIdentity()
+	for _, key := range keys {
+		bs, err := key.MarshalBinary()
+		if err != nil {
+			return false
+		}
+		p := new(bls12381.G2)
+		if err := p.SetBytes(bs); err != nil {
+			return false
+		}
+		sum.Add(sum, p)
+	}
+	aggKey := new(BLSPublicKey)
+	if err := aggKey.UnmarshalBinary(sum.BytesCompressed()); err != nil {
+		return false
+	}
+	return bls.Verify(aggKey, msg, sig)
+}
+
+// Bitmap is a set of node indices, bit i%8 of byte i/8 is set if node i is in it
+type Bitmap []byte
+
+func NewBitmap(n int) Bitmap {
+	return make(Bitmap, (n+7)/8)
+}
+
+func (b Bitmap) Set(i uint32) {
+	b[i/8] |= 1 << (i % 8)
+}
+
+func (b Bitmap) Has(i uint32) bool {
+	return int(i/8) < len(b) && b[i/8]&(1<<(i%8)) != 0
+}
+
+// Indices returns the nodes in the set in ascending order
+func (b Bitmap) Indices() []uint32 {
+	var res []uint32
+	for i, byt := range b {
+		for byt != 0 {
+			j := bits.TrailingZeros8(byt)
+			res = append(res, uint32(i*8+j))
+			byt &^= 1 << j
+		}
+	}
+	return res
+}
@@ -2,14 +2,19 @@ package crypto
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"golang.org/x/crypto/blake2b"
 	"math/big"
 )
 
-func VRF(key *ecdsa.PrivateKey, seed []byte) (rng uint32, proof []byte) {
+// VRF returns the sortition score of key for seed and its proof, a signature of the seed. The
+// proof isn't unique with either algorithm: verifiers can't tell how the signer picked its nonce,
+// so a Byzantine node can draw as many valid proofs as it likes.
+func VRF(key PrivateKey, seed []byte) (rng uint32, proof []byte) {
 	sig := Sign(key, seed)
 	return RngFromProof(sig), sig
 }
@@ -19,47 +24,92 @@ func RngFromProof(proof []byte) uint32 {
 	return uint32(new(big.Int).SetBytes(rng[:32]).Uint64())
 }
 
-func GenKey() *ecdsa.PrivateKey {
-	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	if err != nil {
-		panic(err)
-	}
-	return key
+// GenKey generates a P-256 key
+func GenKey() PrivateKey {
+	return GenKeyAlg(P256)
 }
 
-func Sign(key *ecdsa.PrivateKey, msg []byte) []byte {
-	hash := blake2b.Sum256(msg)
-	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
-	if err != nil {
-		panic(err)
+func GenKeyAlg(alg Algorithm) PrivateKey {
+	switch alg {
+	case P256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		return p256Key{key}
+	case Ed25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		return ed25519Key{key}
+	default:
+		panic(fmt.Sprintf("unsupported key algorithm %s", alg))
 	}
-	return sig
 }
 
-func Verify(key *ecdsa.PublicKey, msg []byte, sig []byte) bool {
-	hash := blake2b.Sum256(msg)
-	return ecdsa.VerifyASN1(key, hash[:], sig)
+func Sign(key PrivateKey, msg []byte) []byte {
+	return key.Sign(msg)
+}
+
+func Verify(key PublicKey, msg []byte, sig []byte) bool {
+	return key.Verify(msg, sig)
 }
 
-func Marshal(key *ecdsa.PrivateKey) []byte {
-	return key.D.Bytes()
+// Marshal encodes key as its algorithm tag followed by its secret
+func Marshal(key PrivateKey) []byte {
+	return append([]byte{byte(key.Algorithm())}, key.Bytes()...)
 }
 
-func UnmarshalHex(hx string) (key *ecdsa.PrivateKey) {
+func UnmarshalHex(hx string) (PrivateKey, error) {
 	bs, err := hex.DecodeString(hx)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("invalid hex key: %s", err.Error())
 	}
 	return Unmarshal(bs)
 }
 
-func Unmarshal(bs []byte) (key *ecdsa.PrivateKey) {
-	if len(bs) != 32 {
-		panic("bad key length")
+// Unmarshal decodes a key encoded by Marshal. 32 bytes without a tag are read as the D value of a
+// P-256 key, which is how keys used to be encoded.
+func Unmarshal(bs []byte) (PrivateKey, error) {
+	if len(bs) == 32 {
+		return p256FromBytes(bs)
+	}
+	if len(bs) == 0 {
+		return nil, fmt.Errorf("empty key")
 	}
-	key = new(ecdsa.PrivateKey)
-	key.Curve = elliptic.P256()
-	key.D = new(big.Int).SetBytes(bs)
-	key.PublicKey.X, key.PublicKey.Y = key.Curve.ScalarBaseMult(bs)
-	return key
+	switch Algorithm(bs[0]) {
+	case P256:
+		return p256FromBytes(bs[1:])
+	case Ed25519:
+		return ed25519FromBytes(bs[1:])
+	default:
+		return nil, fmt.Errorf("unknown key algorithm %s", Algorithm(bs[0]))
+	}
+}
+
+// MarshalPublic encodes key as its algorithm tag followed by the key
+func MarshalPublic(key PublicKey) []byte {
+	return append([]byte{byte(key.Algorithm())}, key.Bytes()...)
+}
+
+func UnmarshalPublic(bs []byte) (PublicKey, error) {
+	if len(bs) == 0 {
+		return nil, fmt.Errorf("empty public key")
+	}
+	switch Algorithm(bs[0]) {
+	case P256:
+		return p256PublicFromBytes(bs[1:])
+	case Ed25519:
+		return ed25519PublicFromBytes(bs[1:])
+	default:
+		return nil, fmt.Errorf("unknown key algorithm %s", Algorithm(bs[0]))
+	}
+}
+
+// NodeID is a short name for the holder of key: the hex of the first 20 bytes of the hash of the
+// encoded key
+func NodeID(key PublicKey) string {
+	h := blake2b.Sum256(MarshalPublic(key))
+	return hex.EncodeToString(h[:20])
 }
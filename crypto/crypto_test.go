@@ -1,30 +1,105 @@
 package crypto
 
 import (
-	"crypto/ecdsa"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
 func TestSignAndVerify(t *testing.T) {
-	key := GenKey()
-	msg := []byte("hello world")
-	sig := Sign(key, msg)
-	pass := Verify(&key.PublicKey, msg, sig)
-	require.True(t, pass)
+	for _, alg := range []Algorithm{P256, Ed25519} {
+		key := GenKeyAlg(alg)
+		msg := []byte("hello world")
+		sig := Sign(key, msg)
+		require.True(t, Verify(key.PublicKey(), msg, sig))
+		require.False(t, Verify(key.PublicKey(), []byte("hello"), sig))
+		require.False(t, Verify(GenKeyAlg(alg).PublicKey(), msg, sig))
+		require.Equal(t, alg, key.PublicKey().Algorithm())
+	}
+
+	// the score is derived from the proof, which verifies as a signature of the seed
+	for _, alg := range []Algorithm{P256, Ed25519} {
+		key := GenKeyAlg(alg)
+		rng, proof := VRF(key, []byte("seed"))
+		require.Equal(t, RngFromProof(proof), rng)
+		require.True(t, Verify(key.PublicKey(), []byte("seed"), proof))
+	}
 }
 
 func TestSerde(t *testing.T) {
+	for _, alg := range []Algorithm{P256, Ed25519} {
+		key := GenKeyAlg(alg)
+		msg := []byte("hello world")
+		sig := Sign(key, msg)
+
+		bs := Marshal(key)
+		require.Len(t, bs, 33)
+		key, err := Unmarshal(bs)
+		require.NoError(t, err)
+		require.Equal(t, alg, key.Algorithm())
+		require.True(t, Verify(key.PublicKey(), msg, sig))
+
+		pub, err := UnmarshalPublic(MarshalPublic(key.PublicKey()))
+		require.NoError(t, err)
+		require.True(t, Verify(pub, msg, sig))
+		require.Equal(t, NodeID(key.PublicKey()), NodeID(pub))
+		require.Len(t, NodeID(pub), 40)
+	}
+
+	// keys used to be the bare D value of a P-256 key
 	key := GenKey()
+	legacy := key.Bytes()
+	require.Len(t, legacy, 32)
+	decoded, err := Unmarshal(legacy)
+	require.NoError(t, err)
+	require.Equal(t, Marshal(key), Marshal(decoded))
+
+	for _, bad := range [][]byte{nil, append([]byte{9}, legacy...), legacy[:20], make([]byte, 32)} {
+		_, err := Unmarshal(bad)
+		require.Error(t, err)
+	}
+	_, err = UnmarshalHex("not hex")
+	require.Error(t, err)
+	_, err = UnmarshalPublic([]byte{byte(P256), 1, 2, 3})
+	require.Error(t, err)
+
+	alg, err := ParseAlgorithm("ed25519")
+	require.NoError(t, err)
+	require.Equal(t, Ed25519, alg)
+	_, err = ParseAlgorithm("rsa")
+	require.Error(t, err)
+}
+
+func TestBLSAggregate(t *testing.T) {
 	msg := []byte("hello world")
-	sig := Sign(key, msg)
-	pass := Verify(&key.PublicKey, msg, sig)
-	require.True(t, pass)
+	var keys []*BLSPublicKey
+	var sigs [][]byte
+	for i := 0; i < 4; i++ {
+		key := GenBLSKey()
+		sig := BLSSign(key, msg)
+		require.True(t, BLSVerify(key.PublicKey(), msg, sig))
+		keys = append(keys, key.PublicKey())
+		sigs = append(sigs, sig)
+	}
+	agg, err := AggregateBLS(sigs)
+	require.NoError(t, err)
+	require.True(t, VerifyAggregateBLS(keys, msg, agg))
+	require.False(t, VerifyAggregateBLS(keys[:3], msg, agg))
+	require.False(t, VerifyAggregateBLS(keys, []byte("hello"), agg))
+	_, err = AggregateBLS(nil)
+	require.Error(t, err)
 
-	bs := Marshal(key)
-	key = &ecdsa.PrivateKey{}
-	key = Unmarshal(bs)
+	// keys derived from the same seed are the same
+	require.True(t, BLSKeyFromSeed([]byte("a")).Equal(BLSKeyFromSeed([]byte("a"))))
+}
 
-	pass = Verify(&key.PublicKey, msg, sig)
-	require.True(t, pass)
+func TestBitmap(t *testing.T) {
+	b := NewBitmap(10)
+	require.Len(t, b, 2)
+	b.Set(0)
+	b.Set(9)
+	b.Set(3)
+	require.True(t, b.Has(9))
+	require.False(t, b.Has(1))
+	require.False(t, b.Has(100))
+	require.Equal(t, []uint32{0, 3, 9}, b.Indices())
 }
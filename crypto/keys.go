@@ -0,0 +1,166 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Algorithm tags the signature scheme of a key. The values match types.KeyAlgorithm, which
+// envelopes carry.
+type Algorithm uint8
+
+const (
+	// ECDSA on P-256 over the BLAKE2b-256 hash of the message, ASN.1 signatures
+	P256 Algorithm = iota
+	// Ed25519, whose signatures are faster to verify
+	Ed25519
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case P256:
+		return "p256"
+	case Ed25519:
+		return "ed25519"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(a))
+	}
+}
+
+// ParseAlgorithm returns the algorithm named s as returned by Algorithm.String
+func ParseAlgorithm(s string) (Algorithm, error) {
+	for _, alg := range []Algorithm{P256, Ed25519} {
+		if alg.String() == s {
+			return alg, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key algorithm %q", s)
+}
+
+type PrivateKey interface {
+	Algorithm() Algorithm
+	PublicKey() PublicKey
+	Sign(msg []byte) []byte
+	// Bytes returns the secret, without the algorithm tag
+	Bytes() []byte
+}
+
+type PublicKey interface {
+	Algorithm() Algorithm
+	Verify(msg, sig []byte) bool
+	// Bytes returns the key, without the algorithm tag
+	Bytes() []byte
+}
+
+type p256Key struct {
+	key *ecdsa.PrivateKey
+}
+
+func (k p256Key) Algorithm() Algorithm { return P256 }
+
+func (k p256Key) PublicKey() PublicKey { return p256PublicKey{&k.key.PublicKey} }
+
+func (k p256Key) Sign(msg []byte) []byte {
+	hash := blake2b.Sum256(msg)
+	sig, err := ecdsa.SignASN1(rand.Reader, k.key, hash[:])
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+func (k p256Key) Bytes() []byte {
+	return k.key.D.FillBytes(make([]byte, 32))
+}
+
+type p256PublicKey struct {
+	key *ecdsa.PublicKey
+}
+
+func (k p256PublicKey) Algorithm() Algorithm { return P256 }
+
+func (k p256PublicKey) Verify(msg, sig []byte) bool {
+	hash := blake2b.Sum256(msg)
+	return ecdsa.VerifyASN1(k.key, hash[:], sig)
+}
+
+// Bytes returns the compressed point
+func (k p256PublicKey) Bytes() []byte {
+	return elliptic.MarshalCompressed(elliptic.P256(), k.key.X, k.key.Y)
+}
+
+func p256PublicFromBytes(bs []byte) (PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), bs)
+	if x == nil {
+		return nil, fmt.Errorf("invalid p256 public key")
+	}
+	return p256PublicKey{&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, nil
+}
+
+func p256FromBytes(bs []byte) (PrivateKey, error) {
+	if len(bs) != 32 {
+		return nil, fmt.Errorf("bad p256 key length %d", len(bs))
+	}
+	key := new(ecdsa.PrivateKey)
+	key.Curve = elliptic.P256()
+	key.D = new(big.Int).SetBytes(bs)
+	if key.D.Sign() == 0 || key.D.Cmp(key.Curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("p256 key out of range")
+	}
+	key.PublicKey.X, key.PublicKey.Y = key.Curve.ScalarBaseMult(bs)
+	return p256Key{key}, nil
+}
+
+type ed25519Key struct {
+	key ed25519.PrivateKey
+}
+
+func (k ed25519Key) Algorithm() Algorithm { return Ed25519 }
+
+func (k ed25519Key) PublicKey() PublicKey {
+	return ed25519PublicKey{k.key.Public().(ed25519.PublicKey)}
+}
+
+func (k ed25519Key) Sign(msg []byte) []byte {
+	return ed25519.Sign(k.key, msg)
+}
+
+// Bytes returns the 32-byte seed of the key
+func (k ed25519Key) Bytes() []byte {
+	return k.key.Seed()
+}
+
+type ed25519PublicKey struct {
+	key ed25519.PublicKey
+}
+
+func (k ed25519PublicKey) Algorithm() Algorithm { return Ed25519 }
+
+func (k ed25519PublicKey) Verify(msg, sig []byte) bool {
+	return ed25519.Verify(k.key, msg, sig)
+}
+
+func (k ed25519PublicKey) Bytes() []byte {
+	return k.key
+}
+
+func ed25519PublicFromBytes(bs []byte) (PublicKey, error) {
+	if len(bs) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("bad ed25519 public key length %d", len(bs))
+	}
+	return ed25519PublicKey{slices.Clone(bs)}, nil
+}
+
+func ed25519FromBytes(bs []byte) (PrivateKey, error) {
+	if len(bs) != ed25519.SeedSize {
+		return nil, fmt.Errorf("bad ed25519 key length %d", len(bs))
+	}
+	return ed25519Key{ed25519.NewKeyFromSeed(bs)}, nil
+}
@@ -0,0 +1,244 @@
+// Package fault implements Byzantine behaviors that can be injected into a node to test how the
+// rest of the cluster copes with them.
+package fault
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/patrickmao1/beeftea/types"
+)
+
+// Behavior alters what a node sends and what it writes to its store. A nil Behavior is honest.
+type Behavior interface {
+	// OnSend is called with every message the node is about to broadcast and returns what to
+	// send instead. Returning nothing withholds the message.
+	OnSend(msg *types.Message, numPeers int) []Outgoing
+	// OnApply is called with every key-value pair the node is about to write to its store and
+	// returns the pair to write instead.
+	OnApply(kv *types.KeyValue) *types.KeyValue
+}
+
+// Outgoing is a message to be broadcast on behalf of a Behavior
+type Outgoing struct {
+	Msg *types.Message
+	// Send the message after this long
+	Delay time.Duration
+	// Only send the message to these peers. Empty means all peers.
+	Indices []int
+}
+
+// Honest sends and applies everything unchanged. Embed it to only override one hook.
+type Honest struct{}
+
+func (Honest) OnSend(msg *types.Message, _ int) []Outgoing {
+	return []Outgoing{{Msg: msg}}
+}
+
+func (Honest) OnApply(kv *types.KeyValue) *types.KeyValue {
+	return kv
+}
+
+// Equivocate sends the real vote to the first half of the peers and a vote for a made-up digest
+// followed by the real vote to the other half, hoping to split the honest nodes.
+type Equivocate struct{ Honest }
+
+func (Equivocate) OnSend(msg *types.Message, numPeers int) []Outgoing {
+	if !isVote(msg) {
+		return []Outgoing{{Msg: msg}}
+	}
+	var first, second []int
+	for i := 0; i < numPeers; i++ {
+		if i < numPeers/2 {
+			first = append(first, i)
+		} else {
+			second = append(second, i)
+		}
+	}
+	return []Outgoing{
+		{Msg: msg, Indices: first},
+		{Msg: withDigest(msg, randomDigest()), Indices: second},
+		{Msg: msg, Indices: second},
+	}
+}
+
+// Withhold never sends any Prepare or Commit
+type Withhold struct{ Honest }
+
+func (Withhold) OnSend(msg *types.Message, _ int) []Outgoing {
+	if isVote(msg) {
+		return nil
+	}
+	return []Outgoing{{Msg: msg}}
+}
+
+// Delay holds back every message for a while before sending it
+type Delay struct {
+	Honest
+	Duration time.Duration
+}
+
+func (d Delay) OnSend(msg *types.Message, _ int) []Outgoing {
+	return []Outgoing{{Msg: msg, Delay: d.Duration}}
+}
+
+// GarbageDigest votes for a random digest instead of the real one. With Repeat > 1 the vote is
+// sent several times in an attempt to make it count more than once.
+type GarbageDigest struct {
+	Honest
+	Repeat int
+}
+
+func (g GarbageDigest) OnSend(msg *types.Message, _ int) []Outgoing {
+	if !isVote(msg) {
+		return []Outgoing{{Msg: msg}}
+	}
+	fake := withDigest(msg, randomDigest())
+	out := []Outgoing{{Msg: fake}}
+	for i := 1; i < g.Repeat; i++ {
+		out = append(out, Outgoing{Msg: fake})
+	}
+	return out
+}
+
+// CorruptState follows the protocol until the very end, then writes a wrong value to its store
+type CorruptState struct {
+	Honest
+	Value string
+}
+
+func (c CorruptState) OnApply(kv *types.KeyValue) *types.KeyValue {
+	return &types.KeyValue{Key: kv.Key, Val: c.Value}
+}
+
+// Chain applies several behaviors one after another
+type Chain []Behavior
+
+func (c Chain) OnSend(msg *types.Message, numPeers int) []Outgoing {
+	out := []Outgoing{{Msg: msg}}
+	for _, b := range c {
+		var next []Outgoing
+		for _, o := range out {
+			for _, o2 := range b.OnSend(o.Msg, numPeers) {
+				o2.Delay += o.Delay
+				if len(o2.Indices) == 0 {
+					o2.Indices = o.Indices
+				} else if len(o.Indices) > 0 {
+					o2.Indices = intersect(o.Indices, o2.Indices)
+					if len(o2.Indices) == 0 {
+						continue
+					}
+				}
+				next = append(next, o2)
+			}
+		}
+		out = next
+	}
+	return out
+}
+
+func (c Chain) OnApply(kv *types.KeyValue) *types.KeyValue {
+	for _, b := range c {
+		kv = b.OnApply(kv)
+	}
+	return kv
+}
+
+// Parse builds a Behavior from a comma separated list of faults, e.g. "delay=500ms,withhold".
+// Supported faults are:
+//
+//	equivocate           vote for different digests towards different peers
+//	withhold             never send Prepare or Commit
+//	delay=<duration>     delay all outgoing messages
+//	garbage-digest[=<n>] vote for a random digest, sent n times
+//	corrupt-state[=<v>]  write v instead of the committed values
+//
+// An empty spec or "honest" returns nil.
+func Parse(spec string) (Behavior, error) {
+	var chain Chain
+	for _, part := range strings.Split(spec, ",") {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "", "honest":
+			continue
+		case "equivocate":
+			chain = append(chain, Equivocate{})
+		case "withhold":
+			chain = append(chain, Withhold{})
+		case "delay":
+			d, err := time.ParseDuration(arg)
+			if err != nil {
+				return nil, fmt.Errorf("bad delay %q: %s", arg, err.Error())
+			}
+			chain = append(chain, Delay{Duration: d})
+		case "garbage-digest":
+			repeat := 1
+			if hasArg {
+				_, err := fmt.Sscanf(arg, "%d", &repeat)
+				if err != nil || repeat < 1 {
+					return nil, fmt.Errorf("bad garbage-digest repeat %q", arg)
+				}
+			}
+			chain = append(chain, GarbageDigest{Repeat: repeat})
+		case "corrupt-state":
+			val := "some evil value!!!"
+			if hasArg {
+				val = arg
+			}
+			chain = append(chain, CorruptState{Value: val})
+		default:
+			return nil, fmt.Errorf("unknown fault %q", name)
+		}
+	}
+	switch len(chain) {
+	case 0:
+		return nil, nil
+	case 1:
+		return chain[0], nil
+	default:
+		return chain, nil
+	}
+}
+
+func isVote(msg *types.Message) bool {
+	switch msg.Type.(type) {
+	case *types.Message_Prepare, *types.Message_Commit:
+		return true
+	default:
+		return false
+	}
+}
+
+// withDigest returns a copy of the vote msg for a different digest
+func withDigest(msg *types.Message, digest []byte) *types.Message {
+	fake := proto.Clone(msg).(*types.Message)
+	switch fake.Type.(type) {
+	case *types.Message_Prepare:
+		fake.GetPrepare().ProposalDigest = digest
+	case *types.Message_Commit:
+		fake.GetCommit().ProposalDigest = digest
+	}
+	return fake
+}
+
+func randomDigest() []byte {
+	digest := make([]byte, types.DigestLen)
+	_, _ = rand.Read(digest)
+	return digest
+}
+
+func intersect(a, b []int) []int {
+	var res []int
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				res = append(res, x)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,69 @@
+package fault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func vote(digest string) *types.Message {
+	return &types.Message{Round: 1, Type: &types.Message_Prepare{
+		Prepare: &types.Prepare{ProposalDigest: []byte(digest)},
+	}}
+}
+
+func TestParse(t *testing.T) {
+	b, err := Parse("")
+	require.NoError(t, err)
+	require.Nil(t, b)
+
+	b, err = Parse("delay=1s")
+	require.NoError(t, err)
+	require.Equal(t, Delay{Duration: time.Second}, b)
+
+	b, err = Parse("equivocate, corrupt-state=x")
+	require.NoError(t, err)
+	require.Equal(t, Chain{Equivocate{}, CorruptState{Value: "x"}}, b)
+
+	_, err = Parse("delay=soon")
+	require.Error(t, err)
+	_, err = Parse("garbage-digest=0")
+	require.Error(t, err)
+	_, err = Parse("explode")
+	require.Error(t, err)
+}
+
+func TestEquivocate(t *testing.T) {
+	msg := vote("real")
+	out := Equivocate{}.OnSend(msg, 5)
+	require.Len(t, out, 3)
+	require.Equal(t, []int{0, 1}, out[0].Indices)
+	require.Equal(t, msg, out[0].Msg)
+	require.Equal(t, []int{2, 3, 4}, out[1].Indices)
+	require.NotEqual(t, msg.GetPrepare().ProposalDigest, out[1].Msg.GetPrepare().ProposalDigest)
+	require.Len(t, out[1].Msg.GetPrepare().ProposalDigest, types.DigestLen)
+	// the original message is untouched
+	require.EqualValues(t, "real", msg.GetPrepare().ProposalDigest)
+
+	// proposals are sent as is
+	proposal := &types.Message{Type: &types.Message_Proposal{Proposal: &types.Proposal{}}}
+	require.Equal(t, []Outgoing{{Msg: proposal}}, Equivocate{}.OnSend(proposal, 5))
+}
+
+func TestChain(t *testing.T) {
+	b, err := Parse("equivocate,delay=1s,withhold")
+	require.NoError(t, err)
+	require.Empty(t, b.OnSend(vote("real"), 5))
+
+	b, err = Parse("equivocate,delay=1s,corrupt-state=x")
+	require.NoError(t, err)
+	out := b.OnSend(vote("real"), 5)
+	require.Len(t, out, 3)
+	for _, o := range out {
+		require.Equal(t, time.Second, o.Delay)
+	}
+	require.Equal(t, []int{0, 1}, out[0].Indices)
+	require.Equal(t, &types.KeyValue{Key: "k", Val: "x"}, b.OnApply(&types.KeyValue{Key: "k", Val: "v"}))
+}
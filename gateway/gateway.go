@@ -0,0 +1,217 @@
+// Package gateway serves the external RPC of a node as HTTP/JSON for the clients that can't speak
+// gRPC. The requests go to the same handlers as the gRPC ones, and their errors come back with
+// the HTTP status of their gRPC code along with the code itself:
+//
+//	PUT    /v1/kv/{key}               {"id": "...", "val": "..."}  -> {"id": "..."}
+//	GET    /v1/kv/{key}[?prove=true]                              -> types.GetRes
+//	DELETE /v1/kv/{key}?id=...                                    -> {"id": "..."}
+//	GET    /v1/kv?prefix=...&limit=...                            -> types.ScanRes
+//	GET    /v1/watch?prefix=...                                   -> server-sent types.WatchEvent
+//
+// Writes must carry an id. Messages are encoded with protojson, so fields are in lowerCamelCase
+// and bytes in base64.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// PutBody is the body of a PUT
+type PutBody struct {
+	// request id, which makes retries of a committed request have no effect. It is required.
+	ID  string `json:"id"`
+	Val string `json:"val"`
+}
+
+// Error is the body of the responses of failed requests
+type Error struct {
+	// gRPC status code, see google.golang.org/grpc/codes
+	Code    codes.Code `json:"code"`
+	Message string     `json:"message"`
+}
+
+// the gateway can't tell a retry from a new request, so it leaves choosing the id to the client
+var errMissingID = status.Error(codes.InvalidArgument, "missing id")
+
+// Handler returns the HTTP handler of srv's external RPC
+func Handler(srv types.ExternalRPCServer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/kv/{key...}", func(w http.ResponseWriter, r *http.Request) {
+		var body PutBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid body: %s", err.Error()))
+			return
+		}
+		if body.ID == "" {
+			writeError(w, errMissingID)
+			return
+		}
+		res, err := srv.Put(r.Context(), &types.PutReq{Id: body.ID, Kv: &types.KeyValue{Key: r.PathValue("key"), Val: body.Val}})
+		write(w, res, err)
+	})
+	mux.HandleFunc("GET /v1/kv/{key...}", func(w http.ResponseWriter, r *http.Request) {
+		prove, err := boolParam(r, "prove")
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		res, err := srv.Get(r.Context(), &types.GetReq{Key: r.PathValue("key"), Prove: prove})
+		write(w, res, err)
+	})
+	mux.HandleFunc("DELETE /v1/kv/{key...}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			writeError(w, errMissingID)
+			return
+		}
+		res, err := srv.Delete(r.Context(), &types.DeleteReq{Id: id, Key: r.PathValue("key")})
+		write(w, res, err)
+	})
+	mux.HandleFunc("GET /v1/kv", func(w http.ResponseWriter, r *http.Request) {
+		req := &types.ScanReq{Prefix: r.URL.Query().Get("prefix")}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			n, err := strconv.ParseUint(limit, 10, 32)
+			if err != nil {
+				writeError(w, status.Errorf(codes.InvalidArgument, "invalid limit: %s", err.Error()))
+				return
+			}
+			req.Limit = uint32(n)
+		}
+		res, err := srv.Scan(r.Context(), req)
+		write(w, res, err)
+	})
+	mux.HandleFunc("GET /v1/watch", func(w http.ResponseWriter, r *http.Request) {
+		watch(srv, w, r)
+	})
+	return mux
+}
+
+// watch streams the events as server-sent events, one "data" line of JSON per event. When the
+// watch ends with an error, it is sent as an "error" event holding an Error.
+func watch(srv types.ExternalRPCServer, w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, status.Error(codes.Unimplemented, "streaming isn't supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream := &watchStream{ctx: r.Context(), send: func(e *types.WatchEvent) error {
+		bs, err := protojson.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", bs); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}}
+	err := srv.Watch(&types.WatchReq{Prefix: r.URL.Query().Get("prefix")}, stream)
+	if err == nil || r.Context().Err() != nil {
+		return
+	}
+	bs, _ := json.Marshal(toError(err))
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", bs)
+	flusher.Flush()
+}
+
+// watchStream hands the events of a Watch to send
+type watchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*types.WatchEvent) error
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(e *types.WatchEvent) error {
+	return s.send(e)
+}
+
+func boolParam(r *http.Request, name string) (bool, error) {
+	val := r.URL.Query().Get(name)
+	if val == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "invalid %s: %s", name, err.Error())
+	}
+	return b, nil
+}
+
+func write(w http.ResponseWriter, res proto.Message, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	bs, err := protojson.Marshal(res)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bs)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e := toError(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatus(e.Code))
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		log.Errorf("failed to write error response: %s", err.Error())
+	}
+}
+
+func toError(err error) *Error {
+	st := status.Convert(err)
+	return &Error{Code: st.Code(), Message: st.Message()}
+}
+
+// HTTPStatus returns the HTTP status of the responses that fail with a gRPC code, as in
+// https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
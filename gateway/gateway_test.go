@@ -0,0 +1,170 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// fakeServer keeps the puts in a map, and fails the requests of key "learner" like a learner would
+type fakeServer struct {
+	types.UnimplementedExternalRPCServer
+	kvs    map[string]string
+	events []*types.WatchEvent
+}
+
+func (s *fakeServer) Put(_ context.Context, req *types.PutReq) (*types.PutRes, error) {
+	if req.Kv.Key == "learner" {
+		return nil, status.Error(codes.FailedPrecondition, "learners don't take requests")
+	}
+	s.kvs[req.Kv.Key] = req.Kv.Val
+	return &types.PutRes{Id: req.Id}, nil
+}
+
+func (s *fakeServer) Get(_ context.Context, req *types.GetReq) (*types.GetRes, error) {
+	res := &types.GetRes{Kv: &types.KeyValue{Key: req.Key, Val: s.kvs[req.Key]}}
+	if req.Prove {
+		res.Round = 7
+	}
+	return res, nil
+}
+
+func (s *fakeServer) Delete(_ context.Context, req *types.DeleteReq) (*types.PutRes, error) {
+	delete(s.kvs, req.Key)
+	return &types.PutRes{Id: req.Id}, nil
+}
+
+func (s *fakeServer) Scan(_ context.Context, req *types.ScanReq) (*types.ScanRes, error) {
+	res := &types.ScanRes{}
+	for key, val := range s.kvs {
+		if strings.HasPrefix(key, req.Prefix) {
+			res.Kvs = append(res.Kvs, &types.KeyValue{Key: key, Val: val})
+		}
+	}
+	res.Truncated = req.Limit > 0 && len(res.Kvs) > int(req.Limit)
+	return res, nil
+}
+
+func (s *fakeServer) Watch(req *types.WatchReq, stream grpc.ServerStreamingServer[types.WatchEvent]) error {
+	for _, e := range s.events {
+		if err := stream.Send(e); err != nil {
+			return err
+		}
+	}
+	return status.Error(codes.Unavailable, "node is shutting down")
+}
+
+func TestHandler(t *testing.T) {
+	srv := &fakeServer{kvs: make(map[string]string)}
+	ts := httptest.NewServer(Handler(srv))
+	defer ts.Close()
+
+	do := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		bs, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(bs)
+	}
+
+	// keys may hold slashes
+	code, body := do("PUT", "/v1/kv/a/1", `{"id": "req1", "val": "x"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `{"id": "req1"}`, body)
+	require.Equal(t, "x", srv.kvs["a/1"])
+	do("PUT", "/v1/kv/b", `{"id": "req2", "val": "y"}`)
+
+	code, body = do("GET", "/v1/kv/a/1?prove=true", "")
+	require.Equal(t, http.StatusOK, code)
+	res := &types.GetRes{}
+	require.NoError(t, protojson.Unmarshal([]byte(body), res))
+	require.Equal(t, "x", res.Kv.Val)
+	require.EqualValues(t, 7, res.Round)
+
+	code, body = do("GET", "/v1/kv?prefix=a/&limit=5", "")
+	require.Equal(t, http.StatusOK, code)
+	scan := &types.ScanRes{}
+	require.NoError(t, protojson.Unmarshal([]byte(body), scan))
+	require.Len(t, scan.Kvs, 1)
+	require.Equal(t, "a/1", scan.Kvs[0].Key)
+
+	code, _ = do("DELETE", "/v1/kv/a/1?id=req3", "")
+	require.Equal(t, http.StatusOK, code)
+	require.NotContains(t, srv.kvs, "a/1")
+
+	// errors carry their gRPC code
+	requireError := func(code int, body string, want codes.Code) {
+		t.Helper()
+		require.Equal(t, HTTPStatus(want), code)
+		var e Error
+		require.NoError(t, json.Unmarshal([]byte(body), &e))
+		require.Equal(t, want, e.Code)
+		require.NotEmpty(t, e.Message)
+	}
+	code, body = do("PUT", "/v1/kv/learner", `{"id": "req4", "val": "z"}`)
+	requireError(code, body, codes.FailedPrecondition)
+	code, body = do("PUT", "/v1/kv/c", `not json`)
+	requireError(code, body, codes.InvalidArgument)
+	code, body = do("PUT", "/v1/kv/c", `{"val": "z"}`)
+	requireError(code, body, codes.InvalidArgument)
+	code, body = do("DELETE", "/v1/kv/b", "")
+	requireError(code, body, codes.InvalidArgument)
+	require.NotContains(t, srv.kvs, "c")
+	require.Equal(t, "y", srv.kvs["b"])
+	code, body = do("GET", "/v1/kv?limit=-1", "")
+	requireError(code, body, codes.InvalidArgument)
+	code, body = do("GET", "/v1/kv/a?prove=maybe", "")
+	requireError(code, body, codes.InvalidArgument)
+}
+
+func TestWatch(t *testing.T) {
+	srv := &fakeServer{events: []*types.WatchEvent{
+		{Kv: &types.KeyValue{Key: "a/1", Val: "x"}, Round: 3},
+		{Kv: &types.KeyValue{Key: "a/1"}, Round: 4, Deleted: true},
+	}}
+	ts := httptest.NewServer(Handler(srv))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/v1/watch?prefix=a/")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	// the events come as "data" lines, then the error that ended the watch as an "error" event
+	var events []*types.WatchEvent
+	var event string
+	var e Error
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: ") && event == "error":
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+		case strings.HasPrefix(line, "data: "):
+			we := &types.WatchEvent{}
+			require.NoError(t, protojson.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), we))
+			events = append(events, we)
+		}
+	}
+	require.Len(t, events, 2)
+	require.EqualValues(t, 3, events[0].Round)
+	require.True(t, events[1].Deleted)
+	require.Equal(t, codes.Unavailable, e.Code)
+}
@@ -0,0 +1,150 @@
+// Package genesis defines the genesis file of a cluster: the settings all nodes must agree on,
+// from the round durations to the peer set, as opposed to the per-node settings like the
+// listen addresses.
+package genesis
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+type Doc struct {
+	ChainID string `json:"chain_id"`
+	// Nodes started earlier wait until then to start their first round
+	GenesisTime time.Time `json:"genesis_time"`
+
+	RoundDuration     Duration `json:"round_duration"`
+	ProposalDuration  Duration `json:"proposal_duration"`
+	ProposalThreshold uint32   `json:"proposal_threshold"`
+	Responsive        bool     `json:"responsive,omitempty"`
+	AdaptiveTimeouts  bool     `json:"adaptive_timeouts,omitempty"`
+
+	Peers []*types.PeerEntry `json:"peers"`
+	// Public keys of the randomness beacon, nil if the nodes don't run one
+	Beacon *beacon.Public `json:"beacon,omitempty"`
+	// Hex of the crypto.MarshalPublic key that signs membership changes, which can't be made
+	// without it
+	OperatorKey string `json:"operator_key,omitempty"`
+
+	// Contents of the key-value store before the first round
+	InitialState map[string]string `json:"initial_state,omitempty"`
+}
+
+// Hash identifies the network: it is the hash of the JSON encoding of the document, which is
+// canonical as the fields are written in order and map keys are sorted
+func (d *Doc) Hash() []byte {
+	bs, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	h := blake2b.Sum256(slices.Concat([]byte("beeftea genesis"), bs))
+	return h[:]
+}
+
+// Duration is a time.Duration written as a string like "4s" in JSON
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(bs []byte) error {
+	var s string
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Validate checks that the genesis describes a cluster that can run
+func (d *Doc) Validate() error {
+	if d.ChainID == "" {
+		return fmt.Errorf("empty chain id")
+	}
+	if !slices.ContainsFunc(d.Peers, func(p *types.PeerEntry) bool { return !p.Learner }) {
+		return fmt.Errorf("no validators")
+	}
+	if d.RoundDuration <= 0 || d.ProposalDuration <= 0 || d.ProposalDuration >= d.RoundDuration {
+		return fmt.Errorf("invalid durations: round %s, proposal %s",
+			time.Duration(d.RoundDuration), time.Duration(d.ProposalDuration))
+	}
+	if d.Beacon != nil && slices.ContainsFunc(d.Peers, func(p *types.PeerEntry) bool { return p.Learner }) {
+		return fmt.Errorf("learners aren't supported with a beacon")
+	}
+	if d.Beacon != nil && len(d.Beacon.Shares) != len(d.Peers) {
+		return fmt.Errorf("%d beacon key shares for %d peers", len(d.Beacon.Shares), len(d.Peers))
+	}
+	return nil
+}
+
+// Config returns the cluster-wide part of a node's config
+func (d *Doc) Config() (*types.Config, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	config := &types.Config{
+		RoundDuration:     time.Duration(d.RoundDuration),
+		ProposalDuration:  time.Duration(d.ProposalDuration),
+		ProposalThreshold: d.ProposalThreshold,
+		Responsive:        d.Responsive,
+		AdaptiveTimeouts:  d.AdaptiveTimeouts,
+		Beacon:            d.Beacon,
+		ChainID:           d.ChainID,
+		ChainHash:         d.Hash(),
+		InitialState:      d.InitialState,
+	}
+	if d.OperatorKey != "" {
+		bs, err := hex.DecodeString(d.OperatorKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operator key: %s", err.Error())
+		}
+		if config.OperatorKey, err = crypto.UnmarshalPublic(bs); err != nil {
+			return nil, fmt.Errorf("invalid operator key: %s", err.Error())
+		}
+	}
+	for _, entry := range d.Peers {
+		peer, err := entry.Peer()
+		if err != nil {
+			return nil, err
+		}
+		config.Peers = append(config.Peers, peer)
+	}
+	return config, nil
+}
+
+func (d *Doc) Save(path string) error {
+	bs, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bs, '\n'), 0644)
+}
+
+func Load(path string) (*Doc, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d := new(Doc)
+	if err := json.Unmarshal(bs, d); err != nil {
+		return nil, fmt.Errorf("invalid genesis %s: %s", path, err.Error())
+	}
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid genesis %s: %s", path, err.Error())
+	}
+	return d, nil
+}
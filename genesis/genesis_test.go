@@ -0,0 +1,43 @@
+package genesis
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	doc := &Doc{
+		ChainID:          "test",
+		GenesisTime:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		RoundDuration:    Duration(time.Second),
+		ProposalDuration: Duration(300 * time.Millisecond),
+		Peers:            []*types.PeerEntry{types.NewPeerEntry("127.0.0.1:9091", crypto.GenKey().PublicKey(), 0)},
+		InitialState:     map[string]string{"b": "2", "a": "1"},
+	}
+	path := filepath.Join(t.TempDir(), "genesis.json")
+	require.NoError(t, doc.Save(path))
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, doc.Hash(), loaded.Hash())
+
+	config, err := loaded.Config()
+	require.NoError(t, err)
+	require.Equal(t, doc.Hash(), config.ChainHash)
+	require.Equal(t, "1", config.InitialState["a"])
+
+	// any change makes another network
+	loaded.InitialState["a"] = "3"
+	require.NotEqual(t, doc.Hash(), loaded.Hash())
+	loaded.InitialState["a"] = "1"
+	loaded.ChainID = "test2"
+	require.NotEqual(t, doc.Hash(), loaded.Hash())
+
+	// learners don't make a cluster on their own
+	doc.Peers[0].Learner = true
+	require.ErrorContains(t, doc.Validate(), "no validators")
+}
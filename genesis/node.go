@@ -0,0 +1,152 @@
+package genesis
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/keystore"
+	"github.com/patrickmao1/beeftea/types"
+)
+
+// NodeConfig is the config file of a node: where its genesis and keystore are, which peer of the
+// genesis it is and its local settings. Relative paths are relative to the directory of the file.
+type NodeConfig struct {
+	Genesis  string `json:"genesis"`
+	Keystore string `json:"keystore"`
+	// File whose first line is the passphrase of the keystore, $BEEFTEA_PASSPHRASE if empty
+	PassphraseFile string `json:"passphrase_file,omitempty"`
+	Index          uint32 `json:"index"`
+	// Consensus address (listen_addr) of a running node to join the cluster from instead of starting at genesis,
+	// once a Reconfigure added my key. Index is then ignored.
+	JoinFrom string `json:"join_from,omitempty"`
+	// Secret share of the randomness beacon, hex
+	BeaconSecret string `json:"beacon_secret,omitempty"`
+
+	ListenAddr        string `json:"listen_addr,omitempty"`
+	RPCListenAddr     string `json:"rpc_listen_addr,omitempty"`
+	AdminListenAddr   string `json:"admin_listen_addr,omitempty"`
+	MetricsListenAddr string `json:"metrics_listen_addr,omitempty"`
+	HTTPListenAddr    string `json:"http_listen_addr,omitempty"`
+	AuditLogFile      string `json:"audit_log_file,omitempty"`
+	TraceFile         string `json:"trace_file,omitempty"`
+	OTLPEndpoint      string `json:"otlp_endpoint,omitempty"`
+	// Lets the admin server make the node Byzantine, see types.Config.AllowFaultInjection
+	AllowFaultInjection bool `json:"allow_fault_injection,omitempty"`
+}
+
+func (c *NodeConfig) Save(path string) error {
+	bs, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bs, '\n'), 0600)
+}
+
+// LoadNode reads the config file of a node along with its genesis and keystore and returns the
+// node's config
+func LoadNode(path string) (*types.Config, *Doc, error) {
+	nc, err := readNodeConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	rel := relativeTo(path)
+	doc, err := Load(rel(nc.Genesis))
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := doc.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	if nc.JoinFrom == "" && int(nc.Index) >= len(config.Peers) {
+		return nil, nil, fmt.Errorf("index %d out of the %d peers of the genesis", nc.Index, len(config.Peers))
+	}
+	passphrase, err := keystore.ReadPassphrase(rel(nc.PassphraseFile), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := keystore.Load(rel(nc.Keystore), passphrase)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open keystore %s: %s", rel(nc.Keystore), err.Error())
+	}
+	if nc.JoinFrom != "" {
+		if config.Beacon != nil {
+			return nil, nil, fmt.Errorf("nodes can't join a cluster that runs a beacon")
+		}
+		// the peers are those of the node I join from
+		config.JoinFrom = nc.JoinFrom
+		config.Peers = []*types.Peer{{Key: key}}
+		config.SetMyIndex(0)
+	} else {
+		me := config.Peers[nc.Index]
+		if !equalKeys(me.PublicKey, key.PublicKey()) {
+			return nil, nil, fmt.Errorf("the keystore holds key %s, but peer %d of the genesis is %s",
+				crypto.NodeID(key.PublicKey()), nc.Index, crypto.NodeID(me.PublicKey))
+		}
+		me.Key = key
+		config.SetMyIndex(nc.Index)
+	}
+
+	if nc.BeaconSecret != "" {
+		if config.BeaconSecret, err = hex.DecodeString(nc.BeaconSecret); err != nil {
+			return nil, nil, fmt.Errorf("invalid beacon secret: %s", err.Error())
+		}
+	}
+	if config.Beacon != nil && config.BeaconSecret == nil {
+		return nil, nil, fmt.Errorf("the genesis has a beacon but the node has no beacon secret")
+	}
+	config.ListenAddr = nc.ListenAddr
+	config.RPCListenAddr = nc.RPCListenAddr
+	config.AdminListenAddr = nc.AdminListenAddr
+	config.MetricsListenAddr = nc.MetricsListenAddr
+	config.HTTPListenAddr = nc.HTTPListenAddr
+	config.AuditLogFile = rel(nc.AuditLogFile)
+	config.TraceFile = rel(nc.TraceFile)
+	config.OTLPEndpoint = nc.OTLPEndpoint
+	config.AllowFaultInjection = nc.AllowFaultInjection
+	return config, doc, nil
+}
+
+// LoadClusterDoc reads the genesis that the node config file at path points to, or the genesis
+// itself if path is one, without opening the keystore. It is what the clients of a cluster need.
+func LoadClusterDoc(path string) (*Doc, error) {
+	nc, err := readNodeConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if nc.Genesis == "" {
+		return Load(path)
+	}
+	return Load(relativeTo(path)(nc.Genesis))
+}
+
+func readNodeConfig(path string) (*NodeConfig, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	nc := new(NodeConfig)
+	if err := json.Unmarshal(bs, nc); err != nil {
+		return nil, fmt.Errorf("invalid node config %s: %s", path, err.Error())
+	}
+	return nc, nil
+}
+
+// relativeTo resolves the paths of the config file at path relative to its directory
+func relativeTo(path string) func(string) string {
+	dir := filepath.Dir(path)
+	return func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+}
+
+func equalKeys(a, b crypto.PublicKey) bool {
+	return string(crypto.MarshalPublic(a)) == string(crypto.MarshalPublic(b))
+}
@@ -0,0 +1,183 @@
+// Package keystore stores a node's private key in a file encrypted with a passphrase: the key
+// is sealed with AES-256-GCM under a key derived from the passphrase with scrypt. The algorithm
+// and the public key are stored in the clear so that they can be shown without the passphrase,
+// and are authenticated along with the ciphertext.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"golang.org/x/crypto/scrypt"
+)
+
+const version = 1
+
+// scrypt parameters of new keystores, the recommended ones for interactive logins as of 2017
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrPassphrase is returned when a keystore doesn't open with the passphrase
+var ErrPassphrase = errors.New("wrong passphrase or corrupted keystore")
+
+// File is the JSON content of a keystore file
+type File struct {
+	Version    int       `json:"version"`
+	Algorithm  string    `json:"algorithm"`
+	PublicKey  string    `json:"public_key"` // hex of crypto.MarshalPublic
+	KDF        KDFParams `json:"kdf"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"` // hex of the sealed crypto.Marshal of the key
+}
+
+type KDFParams struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// Encrypt seals key with passphrase
+func Encrypt(key crypto.PrivateKey, passphrase []byte) (*File, error) {
+	kdf := KDFParams{Name: "scrypt", N: scryptN, R: scryptR, P: scryptP}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	kdf.Salt = hex.EncodeToString(salt)
+	f := &File{
+		Version:   version,
+		Algorithm: key.Algorithm().String(),
+		PublicKey: hex.EncodeToString(crypto.MarshalPublic(key.PublicKey())),
+		KDF:       kdf,
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	f.Nonce = hex.EncodeToString(nonce)
+	f.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, crypto.Marshal(key), f.header()))
+	return f, nil
+}
+
+// Decrypt opens the keystore with passphrase
+func (f *File) Decrypt(passphrase []byte) (crypto.PrivateKey, error) {
+	if f.Version != version {
+		return nil, fmt.Errorf("unsupported keystore version %d", f.Version)
+	}
+	aead, err := f.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(f.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	ciphertext, err := hex.DecodeString(f.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %s", err.Error())
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, f.header())
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	key, err := crypto.Unmarshal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(crypto.MarshalPublic(key.PublicKey())) != f.PublicKey {
+		return nil, fmt.Errorf("the key doesn't match the public key of the keystore")
+	}
+	return key, nil
+}
+
+// Public returns the public key of the keystore, which doesn't need the passphrase
+func (f *File) Public() (crypto.PublicKey, error) {
+	bs, err := hex.DecodeString(f.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %s", err.Error())
+	}
+	return crypto.UnmarshalPublic(bs)
+}
+
+// header is the part of the keystore stored in the clear, authenticated as additional data
+func (f *File) header() []byte {
+	return fmt.Appendf(nil, "beeftea keystore %d %s %s", f.Version, f.Algorithm, f.PublicKey)
+}
+
+func (f *File) aead(passphrase []byte) (cipher.AEAD, error) {
+	if f.KDF.Name != "scrypt" {
+		return nil, fmt.Errorf("unsupported kdf %q", f.KDF.Name)
+	}
+	salt, err := hex.DecodeString(f.KDF.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %s", err.Error())
+	}
+	key, err := scrypt.Key(passphrase, salt, f.KDF.N, f.KDF.R, f.KDF.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kdf params: %s", err.Error())
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Save encrypts key with passphrase into a new file at path. It fails if the file exists.
+func Save(path string, key crypto.PrivateKey, passphrase []byte) error {
+	f, err := Encrypt(key, passphrase)
+	if err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(append(bs, '\n')); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Read reads the keystore at path without decrypting it
+func Read(path string) (*File, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := new(File)
+	if err := json.Unmarshal(bs, f); err != nil {
+		return nil, fmt.Errorf("invalid keystore %s: %s", path, err.Error())
+	}
+	return f, nil
+}
+
+// Load reads and decrypts the keystore at path
+func Load(path string, passphrase []byte) (crypto.PrivateKey, error) {
+	f, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.Decrypt(passphrase)
+}
@@ -0,0 +1,60 @@
+package keystore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "node.key")
+	key := crypto.GenKeyAlg(crypto.Ed25519)
+	require.NoError(t, Save(path, key, []byte("hunter2")))
+	require.Error(t, Save(path, key, []byte("hunter2")), "must not overwrite a keystore")
+
+	loaded, err := Load(path, []byte("hunter2"))
+	require.NoError(t, err)
+	require.Equal(t, crypto.Marshal(key), crypto.Marshal(loaded))
+
+	_, err = Load(path, []byte("hunter3"))
+	require.ErrorIs(t, err, ErrPassphrase)
+
+	// the public key is readable without the passphrase, but can't be swapped
+	f, err := Read(path)
+	require.NoError(t, err)
+	pub, err := f.Public()
+	require.NoError(t, err)
+	require.Equal(t, crypto.NodeID(key.PublicKey()), crypto.NodeID(pub))
+	other, err := Encrypt(crypto.GenKeyAlg(crypto.Ed25519), []byte("hunter2"))
+	require.NoError(t, err)
+	f.PublicKey = other.PublicKey
+	_, err = f.Decrypt([]byte("hunter2"))
+	require.ErrorIs(t, err, ErrPassphrase)
+}
+
+func TestReadPassphrase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(file, []byte("from file\r\nignored\n"), 0600))
+	t.Setenv(PassphraseEnv, "from env")
+
+	// a file given explicitly wins over the environment, which wins over the prompt
+	p, err := ReadPassphrase(file, strings.NewReader("from stdin\n"))
+	require.NoError(t, err)
+	require.Equal(t, "from file", string(p))
+	p, err = ReadPassphrase("", strings.NewReader("from stdin\n"))
+	require.NoError(t, err)
+	require.Equal(t, "from env", string(p))
+
+	t.Setenv(PassphraseEnv, "")
+	p, err = ReadPassphrase("", strings.NewReader("from stdin\n"))
+	require.NoError(t, err)
+	require.Equal(t, "from stdin", string(p))
+	_, err = ReadPassphrase("", nil)
+	require.Error(t, err)
+	_, err = ReadPassphrase("", strings.NewReader("\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package keystore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// PassphraseEnv is the environment variable ReadPassphrase falls back to
+const PassphraseEnv = "BEEFTEA_PASSPHRASE"
+
+// ReadPassphrase returns the first line of file if one is given, else $BEEFTEA_PASSPHRASE. If
+// neither is set, it prompts for the passphrase on stdin, or fails if stdin is nil.
+func ReadPassphrase(file string, stdin io.Reader) ([]byte, error) {
+	in := stdin
+	switch {
+	case file != "":
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	case os.Getenv(PassphraseEnv) != "":
+		return []byte(os.Getenv(PassphraseEnv)), nil
+	case stdin == nil:
+		return nil, fmt.Errorf("no passphrase file and $%s isn't set", PassphraseEnv)
+	default:
+		fmt.Fprint(os.Stderr, "passphrase: ")
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("failed to read the passphrase: %s", err.Error())
+	}
+	passphrase := strings.TrimRight(line, "\r\n")
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	return []byte(passphrase), nil
+}
@@ -0,0 +1,140 @@
+// Package metrics exports a node's consensus, network and store telemetry in the Prometheus format
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "beeftea"
+
+// Metrics holds the collectors of one node. Each node has its own registry so that several nodes
+// can run in the same process.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	Round             prometheus.Gauge
+	PhaseDuration     *prometheus.HistogramVec // phase -> time spent in the phase
+	QuorumLatency     *prometheus.HistogramVec // phase -> time from round start to quorum
+	ProposalsReceived prometheus.Counter
+	ProposalsRejected *prometheus.CounterVec // reason -> count
+	Rounds            *prometheus.CounterVec // outcome (committed, empty or skipped) -> count
+	MessagesSent      *prometheus.CounterVec // message type -> count
+	SendErrors        *prometheus.CounterVec // peer -> count
+	StateSyncs        *prometheus.CounterVec // outcome (synced or failed) -> count
+	RPCDuration       *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		Round: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "round",
+			Help:      "The round the node is in.",
+		}),
+		PhaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "phase_duration_seconds",
+			Help:      "Time spent in each consensus phase.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"phase"}),
+		QuorumLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "quorum_latency_seconds",
+			Help:      "Time from the start of the round until the prepare or commit quorum is reached.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"phase"}),
+		ProposalsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "proposals_received_total",
+			Help:      "Proposals received from peers, including rejected ones.",
+		}),
+		ProposalsRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "proposals_rejected_total",
+			Help:      "Proposals rejected, by reason.",
+		}, []string{"reason"}),
+		Rounds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rounds_total",
+			Help:      "Finished rounds, by whether a proposal with requests or a no-op proposal was committed, or nothing was.",
+		}, []string{"outcome"}),
+		MessagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_sent_total",
+			Help:      "Messages broadcast by the node, by type.",
+		}, []string{"type"}),
+		SendErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "send_errors_total",
+			Help:      "Messages that failed to be sent, by peer.",
+		}, []string{"peer"}),
+		StateSyncs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "state_syncs_total",
+			Help:      "Times the node took the state of its peers to catch up with them, by whether they agreed on one.",
+		}, []string{"outcome"}),
+		RPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Latency of the external RPCs.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+	}
+	m.Registry.MustRegister(
+		m.Round,
+		m.PhaseDuration,
+		m.QuorumLatency,
+		m.ProposalsReceived,
+		m.ProposalsRejected,
+		m.Rounds,
+		m.MessagesSent,
+		m.SendErrors,
+		m.StateSyncs,
+		m.RPCDuration,
+	)
+	return m
+}
+
+// GaugeFunc exports a value that is computed every time the metrics are scraped
+func (m *Metrics) GaugeFunc(name, help string, f func() float64) {
+	m.Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, f))
+}
+
+// CounterFunc exports a counter that is read every time the metrics are scraped
+func (m *Metrics) CounterFunc(name, help string, f func() float64) {
+	m.Registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, f))
+}
+
+// UnaryServerInterceptor records the latency of every RPC handled by a gRPC server
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		code := status.Code(err).String()
+		m.RPCDuration.WithLabelValues(info.FullMethod, code).Observe(time.Since(start).Seconds())
+		return res, err
+	}
+}
+
+// Handler serves the metrics at /metrics
+func (m *Metrics) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+	return mux
+}
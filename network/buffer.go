@@ -0,0 +1,118 @@
+package network
+
+import (
+	"slices"
+
+	"github.com/patrickmao1/beeftea/types"
+)
+
+const (
+	// max number of messages a single peer can have waiting in the buffer
+	defaultMaxPerPeer = 128
+	// messages for rounds further ahead than this are dropped on arrival
+	defaultMaxRoundsAhead = 16
+)
+
+// BufferStats is a snapshot of the future-message buffer counters
+type BufferStats struct {
+	Buffered int    // messages currently waiting for their round
+	Released uint64 // messages handed to the handler when their round started
+	Dropped  uint64 // messages rejected on arrival (stale, too far ahead or over the per-peer cap)
+	Expired  uint64 // buffered messages whose round was skipped over
+}
+
+// futureBuffer holds messages for rounds the node hasn't reached yet. Messages are
+// indexed by round and released all at once when the node advances to that round.
+// It is not safe for concurrent use; the owner must serialize access.
+type futureBuffer struct {
+	started        bool
+	round          uint32
+	maxPerPeer     int
+	maxRoundsAhead uint32
+
+	msgs    map[uint32][]*types.Envelope // round -> envelopes
+	perPeer map[uint32]int               // node index -> number of buffered envelopes
+	seen    map[string]bool              // envelope hash -> buffered
+
+	stats BufferStats
+}
+
+func newFutureBuffer(maxPerPeer int, maxRoundsAhead uint32) *futureBuffer {
+	return &futureBuffer{
+		maxPerPeer:     maxPerPeer,
+		maxRoundsAhead: maxRoundsAhead,
+		msgs:           make(map[uint32][]*types.Envelope),
+		perPeer:        make(map[uint32]int),
+		seen:           make(map[string]bool),
+	}
+}
+
+// add buffers e if it belongs to a future round. It returns false if the envelope
+// should not be buffered, either because it can be handled right away (ready) or
+// because it was dropped.
+func (b *futureBuffer) add(e *types.Envelope) (buffered, ready bool) {
+	round := e.Msg.GetRound()
+	if b.started {
+		if round == b.round {
+			return false, true
+		}
+		if round < b.round || round-b.round > b.maxRoundsAhead {
+			b.stats.Dropped++
+			return false, false
+		}
+	}
+	if b.perPeer[e.NodeIndex] >= b.maxPerPeer {
+		b.stats.Dropped++
+		return false, false
+	}
+	id := string(e.Hash())
+	if b.seen[id] {
+		return false, false
+	}
+	b.seen[id] = true
+	b.msgs[round] = append(b.msgs[round], e)
+	b.perPeer[e.NodeIndex]++
+	b.stats.Buffered++
+	return true, false
+}
+
+// advance moves the buffer to round and returns the messages buffered for it.
+// Messages buffered for rounds before round are discarded.
+func (b *futureBuffer) advance(round uint32) []*types.Envelope {
+	b.started = true
+	b.round = round
+	for r, envelopes := range b.msgs {
+		if r >= round {
+			continue
+		}
+		b.stats.Expired += uint64(len(envelopes))
+		b.remove(r)
+	}
+	released := b.msgs[round]
+	b.stats.Released += uint64(len(released))
+	b.remove(round)
+	return released
+}
+
+// senders returns the distinct nodes that sent messages for round
+func (b *futureBuffer) senders(round uint32) []uint32 {
+	var nodes []uint32
+	for _, e := range b.msgs[round] {
+		if !slices.Contains(nodes, e.NodeIndex) {
+			nodes = append(nodes, e.NodeIndex)
+		}
+	}
+	return nodes
+}
+
+func (b *futureBuffer) remove(round uint32) {
+	for _, e := range b.msgs[round] {
+		b.perPeer[e.NodeIndex]--
+		if b.perPeer[e.NodeIndex] == 0 {
+			delete(b.perPeer, e.NodeIndex)
+		}
+		delete(b.seen, string(e.Hash()))
+		b.stats.Buffered--
+	}
+	delete(b.msgs, round)
+}
@@ -0,0 +1,102 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func envelope(nodeIdx, round uint32, digest string) *types.Envelope {
+	return &types.Envelope{
+		NodeIndex: nodeIdx,
+		Msg: &types.Message{Round: round, Type: &types.Message_Prepare{
+			Prepare: &types.Prepare{ProposalDigest: []byte(digest)},
+		}},
+	}
+}
+
+func TestFutureBufferRelease(t *testing.T) {
+	b := newFutureBuffer(10, 5)
+	b.advance(10)
+
+	buffered, ready := b.add(envelope(1, 10, "a"))
+	require.False(t, buffered)
+	require.True(t, ready)
+
+	buffered, _ = b.add(envelope(1, 11, "b"))
+	require.True(t, buffered)
+	buffered, _ = b.add(envelope(2, 12, "c"))
+	require.True(t, buffered)
+	// duplicates are ignored
+	buffered, _ = b.add(envelope(2, 12, "c"))
+	require.False(t, buffered)
+	require.Equal(t, 2, b.stats.Buffered)
+
+	released := b.advance(11)
+	require.Len(t, released, 1)
+	require.EqualValues(t, "b", released[0].Msg.GetPrepare().ProposalDigest)
+
+	released = b.advance(12)
+	require.Len(t, released, 1)
+	require.EqualValues(t, 2, released[0].NodeIndex)
+	require.Equal(t, BufferStats{Buffered: 0, Released: 2}, b.stats)
+}
+
+func TestFutureBufferDropAndExpire(t *testing.T) {
+	b := newFutureBuffer(2, 5)
+	b.advance(10)
+
+	// stale and too far ahead
+	buffered, ready := b.add(envelope(1, 9, "a"))
+	require.False(t, buffered || ready)
+	buffered, ready = b.add(envelope(1, 16, "a"))
+	require.False(t, buffered || ready)
+	require.EqualValues(t, 2, b.stats.Dropped)
+
+	// per-peer cap
+	for i, digest := range []string{"a", "b", "c"} {
+		buffered, _ = b.add(envelope(1, 12, digest))
+		require.Equal(t, i < 2, buffered)
+	}
+	require.EqualValues(t, 3, b.stats.Dropped)
+	// other peers are unaffected
+	buffered, _ = b.add(envelope(2, 11, "a"))
+	require.True(t, buffered)
+
+	// skipping over round 11 and 12 expires their messages
+	released := b.advance(13)
+	require.Empty(t, released)
+	require.EqualValues(t, 3, b.stats.Expired)
+	require.Equal(t, 0, b.stats.Buffered)
+	require.Empty(t, b.perPeer)
+
+	// peer 1 can buffer again after its messages expired
+	buffered, _ = b.add(envelope(1, 14, "a"))
+	require.True(t, buffered)
+}
+
+func TestFutureBufferBeforeStart(t *testing.T) {
+	b := newFutureBuffer(10, 5)
+
+	// before the first round starts everything is buffered regardless of round
+	buffered, _ := b.add(envelope(1, 100, "a"))
+	require.True(t, buffered)
+	buffered, _ = b.add(envelope(1, 3, "b"))
+	require.True(t, buffered)
+
+	released := b.advance(100)
+	require.Len(t, released, 1)
+	require.EqualValues(t, 1, b.stats.Expired)
+}
+
+func TestFutureBufferSenders(t *testing.T) {
+	b := newFutureBuffer(10, 5)
+	b.advance(1)
+	b.add(envelope(1, 3, "a"))
+	b.add(envelope(1, 3, "b"))
+	require.Equal(t, []uint32{1}, b.senders(3))
+	b.add(envelope(2, 3, "a"))
+	require.Equal(t, []uint32{1, 2}, b.senders(3))
+	require.Empty(t, b.senders(2))
+}
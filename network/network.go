@@ -2,119 +2,361 @@ package network
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"github.com/golang/protobuf/proto"
 	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/metrics"
+	"github.com/patrickmao1/beeftea/tracing"
 	"github.com/patrickmao1/beeftea/types"
+	"github.com/patrickmao1/beeftea/utils"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"net"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 )
 
 type Network struct {
 	idx       uint32
-	key       *ecdsa.PrivateKey
+	key       crypto.PrivateKey
+	chainHash []byte
 	handleMsg HandleMsgFunc
 	peers     []*types.Peer
 	clients   []types.ConsensusRPCClient
+	conns     []*grpc.ClientConn
+	metrics   *metrics.Metrics
+	verifier  *verifier
+	server    *grpc.Server
 
 	mu sync.Mutex
 
-	deferred map[string]*types.Envelope
+	// messages from rounds this node hasn't reached yet
+	future *futureBuffer
+	// called when enough nodes, as told by aheadEnough, have sent messages for a future round,
+	// nil if not set
+	onAhead     func(round uint32)
+	aheadEnough func(nodes []uint32) bool
+	// serves GetSnapshot, nil if not set
+	onSnapshot SnapshotFunc
 }
 
-type HandleMsgFunc func(nodeIdx uint32, m *types.Message) (shouldDefer bool)
+// SnapshotFunc returns the state a node needs to join the cluster, see ConsensusRPC.GetSnapshot
+type SnapshotFunc func(ctx context.Context, req *types.GetSnapshotReq) (*types.Snapshot, error)
+
+type HandleMsgFunc func(e *types.Envelope)
+
+// connectParams caps the backoff between reconnections to a second, instead of gRPC's two
+// minutes, so that peers that restart or join are sent to again within a round
+var connectParams = grpc.ConnectParams{
+	Backoff: backoff.Config{
+		BaseDelay:  100 * time.Millisecond,
+		Multiplier: backoff.DefaultConfig.Multiplier,
+		Jitter:     backoff.DefaultConfig.Jitter,
+		MaxDelay:   time.Second,
+	},
+	MinConnectTimeout: time.Second,
+}
 
 func NewNetwork(
 	myIndex uint32,
-	key *ecdsa.PrivateKey,
+	key crypto.PrivateKey,
+	chainHash []byte,
 	peers []*types.Peer,
 	handleMsg HandleMsgFunc,
+	m *metrics.Metrics,
 ) *Network {
 	n := &Network{
 		idx:       myIndex,
 		key:       key,
+		chainHash: chainHash,
 		handleMsg: handleMsg,
 		peers:     peers,
-		deferred:  make(map[string]*types.Envelope),
+		future:    newFutureBuffer(defaultMaxPerPeer, defaultMaxRoundsAhead),
+		metrics:   m,
+		verifier:  newVerifier(peers, chainHash, 0),
+		server:    grpc.NewServer(),
 	}
+	types.RegisterConsensusRPCServer(n.server, n)
+	m.GaugeFunc("future_buffer_messages", "Messages waiting for their round to start.", func() float64 {
+		return float64(n.FutureBufferStats().Buffered)
+	})
+	m.CounterFunc("future_buffer_dropped_total", "Messages rejected by the future-message buffer.", func() float64 {
+		return float64(n.FutureBufferStats().Dropped)
+	})
+	m.CounterFunc("future_buffer_expired_total", "Buffered messages whose round was skipped.", func() float64 {
+		return float64(n.FutureBufferStats().Expired)
+	})
+	m.CounterFunc("signature_verifications_total", "Envelope signatures checked.", func() float64 {
+		return float64(n.verifier.getStats().Verified)
+	})
+	m.CounterFunc("signature_verification_failures_total", "Envelope signatures that didn't verify.", func() float64 {
+		return float64(n.verifier.getStats().Failed)
+	})
+	m.CounterFunc("signature_cache_hits_total", "Envelopes whose signature was already verified.", func() float64 {
+		return float64(n.verifier.getStats().CacheHits)
+	})
 	return n
 }
 
-func (n *Network) Start() {
+// Start serves the consensus RPC on lis and connects to the peers
+func (n *Network) Start(lis net.Listener) {
 	log.Info("starting network, my peer index: ", n.idx)
-	go n.startRPC()
-	go n.dialPeers()
-	n.processDeferred()
+	go n.serve(lis)
+	n.dialPeers()
+}
+
+// Stop stops serving once the messages in flight are handled, or right away when ctx is done,
+// and closes the connections to the peers and stops the signature workers
+func (n *Network) Stop(ctx context.Context) {
+	utils.GracefulStop(ctx, n.server)
+	n.verifier.close()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, cc := range n.conns {
+		if cc != nil {
+			cc.Close()
+		}
+	}
 }
 
-// Broadcast sends the msg to all nodes in the network asynchronously.
+// Broadcast sends the msg to all nodes in the network asynchronously. Learners don't send
+// anything.
 // NOTE: this function returns immediately without waiting for the other nodes to respond
-func (n *Network) Broadcast(msg *types.Message, indices ...int) {
+func (n *Network) Broadcast(ctx context.Context, msg *types.Message, indices ...int) {
+	n.mu.Lock()
+	learner := n.peers[n.idx].Learner
+	n.mu.Unlock()
+	if learner {
+		return
+	}
 	log.Info("broadcasting message: ", msg)
-	n.doBroadcast(msg, indices...)
+	n.metrics.MessagesSent.WithLabelValues(msg.TypeName()).Inc()
+	n.doBroadcast(ctx, msg, indices...)
+}
+
+// Snapshots asks the other validators for their state with req, see ConsensusRPC.GetSnapshot, and
+// returns the snapshots that came back before ctx is done, peer index -> snapshot
+func (n *Network) Snapshots(ctx context.Context, req *types.GetSnapshotReq) map[uint32]*types.Snapshot {
+	n.mu.Lock()
+	clients := make(map[uint32]types.ConsensusRPCClient)
+	for i, client := range n.clients {
+		if uint32(i) != n.idx && client != nil && n.peers[i].IsValidator() {
+			clients[uint32(i)] = client
+		}
+	}
+	n.mu.Unlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	snaps := make(map[uint32]*types.Snapshot)
+	for i, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snap, err := client.GetSnapshot(ctx, req)
+			if err != nil {
+				log.Warnf("failed to get a snapshot from peer %d: %s", i, err.Error())
+				return
+			}
+			mu.Lock()
+			snaps[i] = snap
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return snaps
 }
 
 func (n *Network) dialPeers() {
-	for i, peer := range n.peers {
-		dialOpt := grpc.WithTransportCredentials(insecure.NewCredentials())
-		cc, err := grpc.NewClient(peer.URL, dialOpt)
-		if err != nil {
-			log.Errorf("failed to dial peer %d: %s", i, err.Error())
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.clients = make([]types.ConsensusRPCClient, len(n.peers))
+	n.conns = make([]*grpc.ClientConn, len(n.peers))
+	for i := range n.peers {
+		n.dial(i)
+	}
+}
+
+// dial connects to peer i unless it was removed. Must be called with n.mu held.
+func (n *Network) dial(i int) {
+	peer := n.peers[i]
+	if peer.Removed {
+		return
+	}
+	cc, err := grpc.NewClient(peer.URL,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(connectParams),
+	)
+	if err != nil {
+		log.Errorf("failed to dial peer %d: %s", i, err.Error())
+		return
+	}
+	n.clients[i] = types.NewConsensusRPCClient(cc)
+	n.conns[i] = cc
+}
+
+// SetPeers changes the peer set after a reconfiguration: new peers are dialed, and the
+// connections to removed peers and to peers that moved to another address are closed. From now
+// on the signatures are checked against the keys of peers, and removed peers' are rejected.
+func (n *Network) SetPeers(peers []*types.Peer) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	old := n.peers
+	n.peers = peers
+	n.verifier.setPeers(peers)
+	if n.clients == nil {
+		// not started yet, Start dials the new peers
+		return
+	}
+	n.clients = slices.Grow(n.clients, len(peers)-len(n.clients))[:len(peers)]
+	n.conns = slices.Grow(n.conns, len(peers)-len(n.conns))[:len(peers)]
+	for i, peer := range peers {
+		if i < len(old) && old[i].URL == peer.URL && old[i].Removed == peer.Removed {
 			continue
 		}
-		n.clients = append(n.clients, types.NewConsensusRPCClient(cc))
+		if n.conns[i] != nil {
+			n.conns[i].Close()
+		}
+		n.clients[i], n.conns[i] = nil, nil
+		if !peer.Removed {
+			log.Infof("dialing peer %d at %s", i, peer.URL)
+		}
+		n.dial(i)
 	}
 }
 
-func (n *Network) processDeferred() {
-	ticker := time.NewTicker(500 * time.Millisecond)
-	for {
-		<-ticker.C
-		n.mu.Lock()
-		newRetries := make(map[string]*types.Envelope)
-		for id, e := range n.deferred {
-			shouldDefer := n.handleMsg(e.NodeIndex, e.Msg)
-			if shouldDefer {
-				newRetries[id] = e
-			}
+// NumPeers returns the number of peer slots, removed peers included
+func (n *Network) NumPeers() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.peers)
+}
+
+// PeerStates returns the state of the connection to each peer. Peers that haven't been dialed
+// are reported as Shutdown.
+func (n *Network) PeerStates() []connectivity.State {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	states := make([]connectivity.State, len(n.peers))
+	for i := range states {
+		states[i] = connectivity.Shutdown
+		if i < len(n.conns) && n.conns[i] != nil {
+			states[i] = n.conns[i].GetState()
 		}
-		n.deferred = newRetries
-		n.mu.Unlock()
+	}
+	return states
+}
+
+// AdvanceRound tells the network that the node has moved to round. Buffered messages
+// for that round are handed to the message handler and those for skipped rounds are
+// discarded. From now on, messages for earlier rounds are dropped on arrival.
+func (n *Network) AdvanceRound(round uint32) {
+	n.mu.Lock()
+	released := n.future.advance(round)
+	n.mu.Unlock()
+	if len(released) > 0 {
+		log.Infof("releasing %d buffered messages for round %d", len(released), round)
+	}
+	for _, e := range released {
+		go n.handleMsg(e)
 	}
 }
 
+// OnRoundAhead makes the network call f with a future round once the nodes that sent the buffered
+// messages for it are enough, so that a node that fell behind can catch up
+func (n *Network) OnRoundAhead(enough func(nodes []uint32) bool, f func(round uint32)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.aheadEnough = enough
+	n.onAhead = f
+}
+
+// OnSnapshot makes the network serve the snapshots of GetSnapshot with f
+func (n *Network) OnSnapshot(f SnapshotFunc) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.onSnapshot = f
+}
+
+// FutureBufferStats returns the counters of the future-message buffer
+func (n *Network) FutureBufferStats() BufferStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.future.stats
+}
+
 func (n *Network) ingest(e *types.Envelope) {
-	go func() {
-		shouldDefer := n.handleMsg(e.NodeIndex, e.Msg)
-		if shouldDefer {
-			n.mu.Lock()
-			n.deferred[string(e.Hash())] = e
-			n.mu.Unlock()
-		}
-	}()
+	// Timeouts are what moves the nodes to the next round, and beacon shares are sent ahead of
+	// their round, so they are handled whatever their round
+	switch e.Msg.GetType().(type) {
+	case *types.Message_Timeout, *types.Message_BeaconShare:
+		go n.handleMsg(e)
+		return
+	}
+	n.mu.Lock()
+	buffered, ready := n.future.add(e)
+	onAhead, aheadEnough := n.onAhead, n.aheadEnough
+	var senders []uint32
+	if buffered && onAhead != nil {
+		senders = n.future.senders(e.Msg.GetRound())
+	}
+	n.mu.Unlock()
+	if buffered {
+		log.Debugf("buffered msg from peer %d for round %d", e.NodeIndex, e.Msg.GetRound())
+	}
+	// the callbacks are called without the lock, as they take the node's
+	if senders != nil && aheadEnough(senders) {
+		onAhead(e.Msg.GetRound())
+	}
+	if ready {
+		go n.handleMsg(e)
+	}
 }
 
-func (n *Network) doBroadcast(msg *types.Message, indices ...int) {
+func (n *Network) doBroadcast(ctx context.Context, msg *types.Message, indices ...int) {
+	// Continue the trace of the caller so that the receivers' spans become its children
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(tracing.TracerName)
+	ctx, span := tracer.Start(ctx, "Broadcast "+msg.TypeName(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.Int64("round", int64(msg.Round))),
+	)
+	defer span.End()
+
 	envelope := &types.Envelope{
-		Msg:       msg,
-		NodeIndex: n.idx,
-		Sig:       n.sign(msg),
+		Msg:          msg,
+		NodeIndex:    n.idx,
+		Sig:          n.sign(msg),
+		SigAlg:       types.KeyAlgorithm(n.key.Algorithm()),
+		TraceContext: tracing.Inject(ctx),
 	}
+	n.mu.Lock()
 	if len(indices) == 0 {
-		indices = []int{0, 1, 2, 3, 4}
+		for i, peer := range n.peers {
+			if !peer.Removed {
+				indices = append(indices, i)
+			}
+		}
 	}
+	clients := n.clients
+	n.mu.Unlock()
 	for _, idx := range indices {
-		client := n.clients[idx]
-		if idx > 4 {
-			panic("idx > 4")
+		if idx >= len(clients) || clients[idx] == nil {
+			log.Errorf("failed to send to peer %d: not connected", idx)
+			n.metrics.SendErrors.WithLabelValues(strconv.Itoa(idx)).Inc()
+			continue
 		}
+		client := clients[idx]
 		go func() {
 			_, err := client.Send(context.Background(), envelope)
 			if err != nil {
 				log.Errorf("failed to send to peer %d: %s", idx, err.Error())
+				n.metrics.SendErrors.WithLabelValues(strconv.Itoa(idx)).Inc()
 			}
 		}()
 	}
@@ -126,5 +368,23 @@ func (n *Network) sign(msg proto.Message) []byte {
 	if err != nil {
 		panic(err)
 	}
-	return crypto.Sign(n.key, bs)
+	return crypto.Sign(n.key, signedBytes(n.chainHash, bs))
+}
+
+// VerifyEnvelopes checks that each of es is signed by the peer it claims to be from. The
+// signatures are checked in parallel on the verification workers, and envelopes that were already
+// verified aren't checked again.
+func (n *Network) VerifyEnvelopes(es ...*types.Envelope) error {
+	return n.verifier.verify(context.Background(), es...)
+}
+
+// VerifierStats returns the counters of the signature verifier
+func (n *Network) VerifierStats() VerifierStats {
+	return n.verifier.getStats()
+}
+
+// signedBytes returns what the signature of an envelope covers: the hash of the genesis of the
+// network, so that envelopes can't be replayed on another network, and the message
+func signedBytes(chainHash, msg []byte) []byte {
+	return slices.Concat(chainHash, msg)
 }
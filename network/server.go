@@ -2,43 +2,41 @@ package network
 
 import (
 	"context"
-	"github.com/golang/protobuf/proto"
-	"github.com/patrickmao1/beeftea/crypto"
 	"github.com/patrickmao1/beeftea/types"
 	log "github.com/sirupsen/logrus"
-	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net"
 )
 
-func (n *Network) startRPC() {
-	svr := grpc.NewServer()
-	lis, err := net.Listen("tcp", "0.0.0.0:9090")
+func (n *Network) serve(lis net.Listener) {
+	log.Infof("Consensus server listening on %s", lis.Addr())
+	err := n.server.Serve(lis)
 	if err != nil {
-		log.Fatal(err)
-	}
-	log.Infof("Consensus server listening on 0.0.0.0:9090")
-	types.RegisterConsensusRPCServer(svr, n)
-	err = svr.Serve(lis)
-	if err != nil {
-		log.Fatal(err)
+		log.Errorf("consensus server failed: %s", err.Error())
 	}
 }
 
 // Send handles incoming call to the Send gRPC
-func (n *Network) Send(_ context.Context, envelope *types.Envelope) (*types.Empty, error) {
-	bs, err := proto.Marshal(envelope.Msg)
+func (n *Network) Send(ctx context.Context, envelope *types.Envelope) (*types.Empty, error) {
+	err := n.verifier.verify(ctx, envelope)
 	if err != nil {
-		log.Errorf("failed to marshal msg: %s", err.Error())
+		log.Error(err)
 		return nil, err
 	}
 
-	pubkey := n.peers[envelope.NodeIndex].Key.PublicKey
-	pass := crypto.Verify(&pubkey, bs, envelope.Sig)
-	if !pass {
-		log.Errorf("failed to verify signature for msg from peer %d", envelope.NodeIndex)
-	}
-
 	n.ingest(envelope)
 
 	return &types.Empty{}, nil
 }
+
+// GetSnapshot handles incoming call to the GetSnapshot gRPC
+func (n *Network) GetSnapshot(ctx context.Context, req *types.GetSnapshotReq) (*types.Snapshot, error) {
+	n.mu.Lock()
+	f := n.onSnapshot
+	n.mu.Unlock()
+	if f == nil {
+		return nil, status.Error(codes.Unavailable, "not serving snapshots")
+	}
+	return f(ctx, req)
+}
@@ -0,0 +1,215 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// max number of envelopes waiting for a worker, senders block beyond that
+	verifyQueueLen = 256
+	// number of verified envelopes remembered
+	verifiedCacheLen = 4096
+)
+
+// VerifierStats is a snapshot of the signature verifier counters
+type VerifierStats struct {
+	Verified  uint64 // signatures checked by the workers
+	Failed    uint64 // signatures that didn't verify
+	CacheHits uint64 // envelopes that were already verified
+}
+
+// verifier checks envelope signatures on a fixed number of workers so that a burst of messages
+// doesn't run as many ECDSA verifications as there are gRPC handlers, and remembers the envelopes
+// it verified so that duplicates, e.g. votes forwarded in equivocation proofs, are checked once
+type verifier struct {
+	chainHash []byte
+	jobs      chan verifyJob
+	// held to send jobs, and by close to close jobs once nobody sends
+	jobsMu sync.RWMutex
+	closed bool
+
+	mu       sync.Mutex
+	peers    []*types.Peer
+	verified map[[32]byte]bool
+	order    [][32]byte // keys of verified, oldest first
+	stats    VerifierStats
+}
+
+type verifyJob struct {
+	key     [32]byte
+	nodeIdx uint32
+	pubkey  crypto.PublicKey
+	bs      []byte // the signed message
+	sig     []byte
+	res     chan<- error
+}
+
+func newVerifier(peers []*types.Peer, chainHash []byte, workers int) *verifier {
+	v := &verifier{
+		peers:     peers,
+		chainHash: chainHash,
+		jobs:      make(chan verifyJob, verifyQueueLen),
+		verified:  make(map[[32]byte]bool),
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	for range workers {
+		go v.work()
+	}
+	return v
+}
+
+// verify checks the signatures of es and returns the first error
+func (v *verifier) verify(ctx context.Context, es ...*types.Envelope) error {
+	results := make(chan error, len(es))
+	pending := 0
+	for _, e := range es {
+		job, err := v.newJob(e)
+		if err != nil {
+			return err
+		}
+		if v.isVerified(job.key) {
+			continue
+		}
+		job.res = results
+		if err := v.send(ctx, job); err != nil {
+			return err
+		}
+		pending++
+	}
+	var firstErr error
+	for range pending {
+		select {
+		case err := <-results:
+			if firstErr == nil {
+				firstErr = err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return firstErr
+}
+
+func (v *verifier) send(ctx context.Context, job verifyJob) error {
+	v.jobsMu.RLock()
+	defer v.jobsMu.RUnlock()
+	if v.closed {
+		return errors.New("verifier is closed")
+	}
+	select {
+	case v.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops the workers once they have verified the queued envelopes
+func (v *verifier) close() {
+	v.jobsMu.Lock()
+	defer v.jobsMu.Unlock()
+	if !v.closed {
+		v.closed = true
+		close(v.jobs)
+	}
+}
+
+// setPeers makes the verifier check the signatures against the keys of peers. The cache is
+// cleared as it doesn't cover the keys, which may have changed.
+func (v *verifier) setPeers(peers []*types.Peer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.peers = peers
+	v.verified = make(map[[32]byte]bool)
+	v.order = nil
+}
+
+func (v *verifier) newJob(e *types.Envelope) (verifyJob, error) {
+	v.mu.Lock()
+	peers := v.peers
+	v.mu.Unlock()
+	if int(e.NodeIndex) >= len(peers) {
+		return verifyJob{}, fmt.Errorf("unknown peer index %d", e.NodeIndex)
+	}
+	if peers[e.NodeIndex].Removed {
+		return verifyJob{}, fmt.Errorf("peer %d was removed", e.NodeIndex)
+	}
+	if peers[e.NodeIndex].Learner {
+		return verifyJob{}, fmt.Errorf("peer %d is a learner", e.NodeIndex)
+	}
+	pubkey := peers[e.NodeIndex].PubKey()
+	if types.KeyAlgorithm(pubkey.Algorithm()) != e.SigAlg {
+		return verifyJob{}, fmt.Errorf("%s signature from peer %d, whose key is %s",
+			crypto.Algorithm(e.SigAlg), e.NodeIndex, pubkey.Algorithm())
+	}
+	bs, err := proto.Marshal(e.Msg)
+	if err != nil {
+		return verifyJob{}, fmt.Errorf("failed to marshal msg: %s", err.Error())
+	}
+	bs = signedBytes(v.chainHash, bs)
+	// the key covers everything the signature check depends on, so that a valid signature can't
+	// be replayed from the cache with another message or sender
+	key := blake2b.Sum256(slices.Concat(binary.BigEndian.AppendUint32(nil, e.NodeIndex), e.Sig, bs))
+	return verifyJob{key: key, nodeIdx: e.NodeIndex, pubkey: pubkey, bs: bs, sig: e.Sig}, nil
+}
+
+func (v *verifier) work() {
+	for job := range v.jobs {
+		ok := crypto.Verify(job.pubkey, job.bs, job.sig)
+		v.mu.Lock()
+		v.stats.Verified++
+		if ok {
+			v.remember(job.key)
+		} else {
+			v.stats.Failed++
+		}
+		v.mu.Unlock()
+		if !ok {
+			job.res <- fmt.Errorf("failed to verify signature for msg from peer %d", job.nodeIdx)
+			continue
+		}
+		job.res <- nil
+	}
+}
+
+func (v *verifier) isVerified(key [32]byte) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.verified[key] {
+		v.stats.CacheHits++
+		return true
+	}
+	return false
+}
+
+// Must be called with v.mu held.
+func (v *verifier) remember(key [32]byte) {
+	if v.verified[key] {
+		return
+	}
+	v.verified[key] = true
+	v.order = append(v.order, key)
+	if len(v.order) > verifiedCacheLen {
+		delete(v.verified, v.order[0])
+		v.order = v.order[1:]
+	}
+}
+
+func (v *verifier) getStats() VerifierStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.stats
+}
@@ -0,0 +1,95 @@
+package network
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier(t *testing.T) {
+	peers := []*types.Peer{{Key: crypto.GenKey()}, {Key: crypto.GenKey()}}
+	v := newVerifier(peers, nil, 2)
+	signed := func(nodeIdx uint32, digest string) *types.Envelope {
+		e := envelope(nodeIdx, 3, digest)
+		bs, err := proto.Marshal(e.Msg)
+		require.NoError(t, err)
+		e.Sig = crypto.Sign(peers[nodeIdx].Key, bs)
+		return e
+	}
+	ctx := context.Background()
+
+	a, b := signed(0, "a"), signed(1, "b")
+	require.NoError(t, v.verify(ctx, a, b))
+	require.Equal(t, VerifierStats{Verified: 2}, v.getStats())
+
+	// duplicates aren't verified again
+	require.NoError(t, v.verify(ctx, a))
+	require.NoError(t, v.verify(ctx, proto.Clone(b).(*types.Envelope)))
+	require.EqualValues(t, 2, v.getStats().Verified)
+	require.EqualValues(t, 2, v.getStats().CacheHits)
+
+	// but a cached signature doesn't vouch for another message or sender
+	forged := envelope(0, 3, "c")
+	forged.Sig = a.Sig
+	require.EqualError(t, v.verify(ctx, b, forged), "failed to verify signature for msg from peer 0")
+	forged = envelope(1, 3, "a")
+	forged.Sig = a.Sig
+	require.Error(t, v.verify(ctx, forged))
+	require.EqualValues(t, 2, v.getStats().Failed)
+
+	forged = envelope(2, 3, "a")
+	require.EqualError(t, v.verify(ctx, forged), "unknown peer index 2")
+
+	// the signature algorithm must be the one of the sender's key
+	forged = proto.Clone(a).(*types.Envelope)
+	forged.SigAlg = types.KeyAlgorithm_KEY_ALGORITHM_ED25519
+	require.EqualError(t, v.verify(ctx, forged), "ed25519 signature from peer 0, whose key is p256")
+}
+
+func TestVerifierChainHash(t *testing.T) {
+	key := crypto.GenKey()
+	peers := []*types.Peer{{Key: key}}
+	e := envelope(0, 3, "a")
+	bs, err := proto.Marshal(e.Msg)
+	require.NoError(t, err)
+	e.Sig = crypto.Sign(key, signedBytes([]byte("chain a"), bs))
+
+	require.NoError(t, newVerifier(peers, []byte("chain a"), 1).verify(context.Background(), e))
+	require.Error(t, newVerifier(peers, []byte("chain b"), 1).verify(context.Background(), e))
+	require.Error(t, newVerifier(peers, nil, 1).verify(context.Background(), e))
+}
+
+func TestVerifierCacheEviction(t *testing.T) {
+	v := newVerifier(nil, nil, 1)
+	for i := range verifiedCacheLen + 1 {
+		v.remember([32]byte{byte(i), byte(i >> 8)})
+	}
+	require.Len(t, v.verified, verifiedCacheLen)
+	require.False(t, v.isVerified([32]byte{}))
+	require.True(t, v.isVerified([32]byte{1}))
+}
+
+func TestVerifierClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	key := crypto.GenKey()
+	v := newVerifier([]*types.Peer{{Key: key}}, nil, 8)
+	v.close()
+	v.close()
+	// not require.Eventually, whose own goroutines would be counted
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; {
+		require.True(t, time.Now().Before(deadline), "the workers didn't return")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	e := envelope(0, 3, "a")
+	bs, err := proto.Marshal(e.Msg)
+	require.NoError(t, err)
+	e.Sig = crypto.Sign(key, bs)
+	require.EqualError(t, v.verify(context.Background(), e), "verifier is closed")
+}
@@ -0,0 +1,37 @@
+// Package ports holds the host ports of the servers of testnet nodes, so that clients can find a
+// testnet without depending on the packages that generate it.
+package ports
+
+import "fmt"
+
+// Host ports of node i are these plus i+1, so that the external servers of a 5-node testnet are
+// at localhost:8081-8085 like the ones of compose.yaml. The admin servers are only reachable from
+// the host.
+const (
+	Consensus = 9090
+	RPC       = 8080
+	Admin     = 7070
+	Metrics   = 2110
+	HTTP      = 8180
+)
+
+// MaxNodes is the most nodes a testnet has, so that the ports of different servers don't overlap
+const MaxNodes = HTTP - RPC
+
+// AdminURLs returns the addresses of the admin servers of the nodes as seen from the host
+func AdminURLs(nodes int) []string {
+	return urls(Admin, nodes)
+}
+
+// ClientURLs returns the addresses of the external servers of the nodes as seen from the host
+func ClientURLs(nodes int) []string {
+	return urls(RPC, nodes)
+}
+
+func urls(base, nodes int) []string {
+	var urls []string
+	for i := range nodes {
+		urls = append(urls, fmt.Sprintf("localhost:%d", base+i+1))
+	}
+	return urls
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorts(t *testing.T) {
+	taken := make(map[int]bool)
+	for _, base := range []int{Consensus, RPC, Admin, Metrics, HTTP} {
+		for i := range MaxNodes {
+			require.False(t, taken[base+i+1], "port %d is taken twice", base+i+1)
+			taken[base+i+1] = true
+		}
+	}
+	require.Equal(t, []string{"localhost:8081", "localhost:8082"}, ClientURLs(2))
+	require.Equal(t, []string{"localhost:7071"}, AdminURLs(1))
+}
@@ -0,0 +1,218 @@
+// Package testnet generates everything a local cluster needs to run: a key and a config file per
+// node, the genesis file with the operator key that signs membership changes (operator.key), and a docker compose file or a script that runs the nodes as local
+// processes.
+package testnet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/consensus"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/genesis"
+	"github.com/patrickmao1/beeftea/keystore"
+	"github.com/patrickmao1/beeftea/testnet/ports"
+	"github.com/patrickmao1/beeftea/types"
+)
+
+const (
+	Local  = "local"
+	Docker = "docker"
+)
+
+type Options struct {
+	Nodes int
+	// Learner nodes to run after the validators, see types.Peer.Learner
+	Learners int
+	Out      string
+	// Local runs the nodes as processes on 127.0.0.1, Docker as containers on a bridge network
+	Mode      string
+	Algorithm crypto.Algorithm
+	ChainID   string
+	// Rounds start that long after the files are generated
+	StartDelay       time.Duration
+	RoundDuration    time.Duration
+	ProposalDuration time.Duration
+	Responsive       bool
+	Beacon           bool
+	// Lets the admin servers make the nodes Byzantine, see types.Config.AllowFaultInjection
+	FaultInjection bool
+	// Contents of the key-value store before the first round
+	InitialState map[string]string
+	// Path of the beeftea repository, where the nodes are built from
+	Repo string
+}
+
+// Generate writes the testnet to opts.Out, which must not exist yet
+func Generate(opts Options) error {
+	if opts.Nodes < 1 || opts.Learners < 0 || opts.Nodes+opts.Learners > ports.MaxNodes {
+		return fmt.Errorf("invalid number of nodes %d and learners %d", opts.Nodes, opts.Learners)
+	}
+	if opts.Beacon && opts.Learners > 0 {
+		return fmt.Errorf("learners aren't supported with a beacon")
+	}
+	if opts.Mode != Local && opts.Mode != Docker {
+		return fmt.Errorf("unknown mode %q", opts.Mode)
+	}
+	repo, err := filepath.Abs(opts.Repo)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(opts.Out, 0755); err != nil {
+		return err
+	}
+
+	passphrase := make([]byte, 16)
+	if _, err := rand.Read(passphrase); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(opts.Out, "passphrase"), []byte(hex.EncodeToString(passphrase)+"\n"), 0600); err != nil {
+		return err
+	}
+
+	doc := &genesis.Doc{
+		ChainID:           opts.ChainID,
+		GenesisTime:       time.Now().Add(opts.StartDelay).UTC().Truncate(time.Second),
+		RoundDuration:     genesis.Duration(opts.RoundDuration),
+		ProposalDuration:  genesis.Duration(opts.ProposalDuration),
+		ProposalThreshold: consensus.ProposalThreshold(opts.Nodes),
+		Responsive:        opts.Responsive,
+		InitialState:      opts.InitialState,
+	}
+	operator := crypto.GenKeyAlg(opts.Algorithm)
+	if err := keystore.Save(filepath.Join(opts.Out, "operator.key"), operator, []byte(hex.EncodeToString(passphrase))); err != nil {
+		return err
+	}
+	doc.OperatorKey = hex.EncodeToString(crypto.MarshalPublic(operator.PublicKey()))
+	var beaconSecrets [][]byte
+	if opts.Beacon {
+		if doc.Beacon, beaconSecrets, err = beacon.Deal(rand.Reader, opts.Nodes, beacon.Threshold(opts.Nodes)); err != nil {
+			return err
+		}
+	}
+
+	total := opts.Nodes + opts.Learners
+	for i := range total {
+		dir := filepath.Join(opts.Out, nodeName(i))
+		if err := os.Mkdir(dir, 0755); err != nil {
+			return err
+		}
+		key := crypto.GenKeyAlg(opts.Algorithm)
+		if err := keystore.Save(filepath.Join(dir, "node.key"), key, []byte(hex.EncodeToString(passphrase))); err != nil {
+			return err
+		}
+		entry := types.NewPeerEntry(peerURL(opts.Mode, i), key.PublicKey(), 0)
+		entry.Learner = i >= opts.Nodes
+		entry.RPCURL = fmt.Sprintf("localhost:%d", ports.RPC+i+1)
+		doc.Peers = append(doc.Peers, entry)
+
+		nc := &genesis.NodeConfig{
+			Genesis:        "../genesis.json",
+			Keystore:       "node.key",
+			PassphraseFile: "../passphrase",
+			Index:          uint32(i),
+			AuditLogFile:   "audit.log",
+
+			AllowFaultInjection: opts.FaultInjection,
+		}
+		if opts.Mode == Local {
+			nc.ListenAddr = fmt.Sprintf("127.0.0.1:%d", ports.Consensus+i+1)
+			nc.RPCListenAddr = fmt.Sprintf("127.0.0.1:%d", ports.RPC+i+1)
+			nc.AdminListenAddr = fmt.Sprintf("127.0.0.1:%d", ports.Admin+i+1)
+			nc.MetricsListenAddr = fmt.Sprintf("127.0.0.1:%d", ports.Metrics+i+1)
+			nc.HTTPListenAddr = fmt.Sprintf("127.0.0.1:%d", ports.HTTP+i+1)
+		} else {
+			// published on the host's loopback only, see composeFile
+			nc.AdminListenAddr = "0.0.0.0:7070"
+			nc.MetricsListenAddr = "0.0.0.0:2112"
+			nc.HTTPListenAddr = "0.0.0.0:8180"
+		}
+		if beaconSecrets != nil {
+			nc.BeaconSecret = hex.EncodeToString(beaconSecrets[i])
+		}
+		if err := nc.Save(filepath.Join(dir, "config.json")); err != nil {
+			return err
+		}
+	}
+	if err := doc.Save(filepath.Join(opts.Out, "genesis.json")); err != nil {
+		return err
+	}
+
+	if opts.Mode == Docker {
+		return os.WriteFile(filepath.Join(opts.Out, "compose.yaml"), []byte(composeFile(total, repo)), 0644)
+	}
+	return os.WriteFile(filepath.Join(opts.Out, "run.sh"), []byte(runScript(total, repo)), 0755)
+}
+
+func nodeName(i int) string {
+	return fmt.Sprintf("node%d", i+1)
+}
+
+func peerURL(mode string, i int) string {
+	if mode == Docker {
+		return fmt.Sprintf("%s:9090", dockerIP(i))
+	}
+	return fmt.Sprintf("127.0.0.1:%d", ports.Consensus+i+1)
+}
+
+func dockerIP(i int) string {
+	return fmt.Sprintf("172.16.0.%d", i+1)
+}
+
+func composeFile(nodes int, repo string) string {
+	var b strings.Builder
+	b.WriteString("services:\n")
+	for i := range nodes {
+		name := nodeName(i)
+		fmt.Fprintf(&b, `  %[1]s:
+    build: %[2]s
+    command: ["sh", "-c", "./node -config /app/testnet/%[1]s/config.json 2>&1 | tee /app/testnet/%[1]s/app.log"]
+    volumes:
+      - .:/app/testnet
+    ports:
+      - "%[3]d:8080"
+      - "127.0.0.1:%[7]d:7070"
+      - "%[4]d:2112"
+      - "%[6]d:8180"
+    networks:
+      testnet:
+        ipv4_address: %[5]s
+
+`, name, repo, ports.RPC+i+1, ports.Metrics+i+1, dockerIP(i), ports.HTTP+i+1, ports.Admin+i+1)
+	}
+	b.WriteString(`networks:
+  testnet:
+    driver: bridge
+    ipam:
+      config:
+        - subnet: 172.16.0.0/16
+          gateway: 172.16.0.254
+`)
+	return b.String()
+}
+
+func runScript(nodes int, repo string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `#!/bin/sh
+# Runs the nodes of the testnet as local processes until interrupted. Set $BEEFTEA to use an
+# existing binary instead of building one from %[1]s.
+set -e
+cd "$(dirname "$0")"
+if [ -z "$BEEFTEA" ]; then
+	(cd %[1]q && go build -o "$OLDPWD/beeftea" ./cmd/beeftea)
+	BEEFTEA=./beeftea
+fi
+trap 'kill 0' INT TERM
+`, repo)
+	for i := range nodes {
+		fmt.Fprintf(&b, "\"$BEEFTEA\" -config %[1]s/config.json > %[1]s/app.log 2>&1 &\n", nodeName(i))
+	}
+	fmt.Fprintf(&b, "echo \"%d nodes running, external servers at %s\"\nwait\n", nodes, strings.Join(ports.ClientURLs(nodes), ","))
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package testnet
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/genesis"
+	"github.com/patrickmao1/beeftea/keystore"
+	"github.com/patrickmao1/beeftea/testnet/ports"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "testnet")
+	opts := Options{
+		Nodes:            4,
+		Out:              out,
+		Mode:             Local,
+		Algorithm:        crypto.Ed25519,
+		ChainID:          "test",
+		RoundDuration:    2 * time.Second,
+		ProposalDuration: 500 * time.Millisecond,
+		Beacon:           true,
+		Repo:             ".",
+	}
+	require.NoError(t, Generate(opts))
+	require.Error(t, Generate(opts), "must not overwrite a testnet")
+
+	// every node loads its config, which agrees with the others' but for the key and local settings
+	var listenAddrs []string
+	for i := range opts.Nodes {
+		config, doc, err := genesis.LoadNode(filepath.Join(out, nodeName(i), "config.json"))
+		require.NoError(t, err)
+		require.Equal(t, "test", doc.ChainID)
+		require.EqualValues(t, i, config.MyIndex())
+		require.Len(t, config.Peers, 4)
+		require.Equal(t, crypto.Ed25519, config.MyKey().Algorithm())
+		require.Equal(t, crypto.NodeID(config.Peers[i].PublicKey), crypto.NodeID(config.MyKey().PublicKey()))
+		require.Equal(t, config.Peers[i].URL, config.ListenAddr)
+		require.Equal(t, ports.AdminURLs(4)[i], "localhost"+strings.TrimPrefix(config.AdminListenAddr, "127.0.0.1"))
+		require.False(t, config.AllowFaultInjection)
+		require.NotNil(t, config.BeaconSecret)
+		require.Equal(t, 2*time.Second, config.RoundDuration)
+		listenAddrs = append(listenAddrs, config.ListenAddr)
+	}
+	require.Len(t, listenAddrs, 4)
+
+	// the operator keystore opens with the same passphrase and signs membership changes
+	passphrase, err := os.ReadFile(filepath.Join(out, "passphrase"))
+	require.NoError(t, err)
+	operator, err := keystore.Load(filepath.Join(out, "operator.key"), passphrase[:len(passphrase)-1])
+	require.NoError(t, err)
+	config, _, err := genesis.LoadNode(filepath.Join(out, nodeName(0), "config.json"))
+	require.NoError(t, err)
+	rc := &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE, Index: 3}
+	rc.Sign(operator, config.ChainHash)
+	require.NoError(t, rc.VerifyOperator(config.OperatorKey, config.ChainHash))
+	require.NotEqual(t, listenAddrs[0], listenAddrs[1])
+
+	script, err := os.ReadFile(filepath.Join(out, "run.sh"))
+	require.NoError(t, err)
+	require.Contains(t, string(script), "-config node4/config.json")
+
+	opts.Out = filepath.Join(t.TempDir(), "docker")
+	opts.Mode = Docker
+	require.NoError(t, Generate(opts))
+	compose, err := os.ReadFile(filepath.Join(opts.Out, "compose.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(compose), "ipv4_address: 172.16.0.4")
+	require.Contains(t, string(compose), `"8084:8080"`)
+	require.Contains(t, string(compose), `"8184:8180"`)
+	require.Contains(t, string(compose), `"127.0.0.1:7074:7070"`, "admin servers must only be published on the host's loopback")
+
+	// learners come after the validators
+	opts.Out = filepath.Join(t.TempDir(), "learners")
+	opts.Learners = 1
+	require.ErrorContains(t, Generate(opts), "beacon")
+	opts.Beacon = false
+	require.NoError(t, Generate(opts))
+	config, _, err = genesis.LoadNode(filepath.Join(opts.Out, nodeName(4), "config.json"))
+	require.NoError(t, err)
+	require.Len(t, config.Peers, 5)
+	require.True(t, config.Peers[4].Learner)
+	require.False(t, config.Peers[3].Learner)
+
+	// clients find the nodes from the same config file
+	doc, err := genesis.LoadClusterDoc(filepath.Join(opts.Out, nodeName(4), "config.json"))
+	require.NoError(t, err)
+	require.Equal(t, "localhost:8085", doc.Peers[4].RPCURL)
+	doc, err = genesis.LoadClusterDoc(filepath.Join(opts.Out, "genesis.json"))
+	require.NoError(t, err)
+	require.Len(t, doc.Peers, 5)
+}
+
+func TestTooManyNodes(t *testing.T) {
+	opts := Options{Nodes: ports.MaxNodes, Learners: 1, Out: filepath.Join(t.TempDir(), "testnet"), Mode: Local, Repo: "."}
+	require.ErrorContains(t, Generate(opts), "invalid number of nodes")
+}
+
+func TestLoadNodeWrongKey(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "testnet")
+	opts := Options{Nodes: 2, Out: out, Mode: Local, ChainID: "test", RoundDuration: time.Second,
+		ProposalDuration: 100 * time.Millisecond, Repo: "."}
+	require.NoError(t, Generate(opts))
+	require.NoError(t, os.Rename(filepath.Join(out, "node2", "node.key"), filepath.Join(out, "node1", "other.key")))
+	require.NoError(t, os.Remove(filepath.Join(out, "node1", "node.key")))
+	require.NoError(t, os.Rename(filepath.Join(out, "node1", "other.key"), filepath.Join(out, "node1", "node.key")))
+	_, _, err := genesis.LoadNode(filepath.Join(out, "node1", "config.json"))
+	require.ErrorContains(t, err, "but peer 0 of the genesis is")
+}
@@ -0,0 +1,51 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminStatus(t *testing.T) {
+	c := New(t, 5)
+	admin := c.Admin(t, 0)
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"}, 1)
+	res, err := admin.Status(context.Background(), &types.StatusReq{})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, res.NodeIndex)
+	require.Nil(t, res.LastCommittedRound)
+	require.Len(t, res.Peers, 5)
+
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	res, err = admin.Status(context.Background(), &types.StatusReq{})
+	require.NoError(t, err)
+	require.NotNil(t, res.LastCommittedRound)
+	require.LessOrEqual(t, res.GetLastCommittedRound(), res.Round)
+	require.Len(t, res.Seed, 36)
+	for _, peer := range res.Peers {
+		require.Equal(t, "READY", peer.State, "peer %d", peer.Index)
+	}
+}
+
+func TestAdminServer(t *testing.T) {
+	c := New(t, 1, func(_ int, config *types.Config) {
+		config.AllowFaultInjection = false
+	})
+	ctx := context.Background()
+
+	// the admin server isn't served next to the external RPC
+	_, err := types.NewAdminRPCClient(c.Client(t, 0)).Status(ctx, &types.StatusReq{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = c.Admin(t, 0).Status(ctx, &types.StatusReq{})
+	require.NoError(t, err)
+
+	// nodes can't be made Byzantine unless they allow it
+	_, err = c.Admin(t, 0).SetBehavior(ctx, &types.SetBehaviorReq{Spec: "withhold"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/audit"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func readAuditLogs(t *testing.T, path string, nodes []int) []*audit.Round {
+	var rounds []*audit.Round
+	for _, i := range nodes {
+		rs, err := audit.ReadFile(fmt.Sprintf("%s.%d", path, i))
+		require.NoError(t, err)
+		rounds = append(rounds, rs...)
+	}
+	return rounds
+}
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit")
+	c := New(t, 5, WithAuditLog(path))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	// the round is logged when the next one starts
+	time.Sleep(c.Configs[0].RoundDuration)
+
+	rounds := readAuditLogs(t, path, []int{0, 1, 2, 3, 4})
+	applied := make(map[uint32]int) // round -> nodes that applied a proposal
+	for _, r := range rounds {
+		if r.Applied != "" {
+			require.Equal(t, r.Applied, r.MinProposal)
+			require.NotEmpty(t, r.Proposals)
+			applied[r.Round]++
+		}
+	}
+	require.NotEmpty(t, applied)
+	for _, d := range audit.Compare(rounds) {
+		require.NotEqual(t, audit.Safety, d.Severity, d.String())
+	}
+}
+
+func TestAuditLogEquivocation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit")
+	c := New(t, 5, WithAuditLog(path), WithFaults(map[uint32]string{4: "equivocate"}))
+
+	// node 4's votes only diverge if the honest nodes of the second half receive the made-up one
+	// first, so keep putting until it happened in some round
+	var equivocations []audit.Divergence
+	for i := 0; len(equivocations) == 0; i++ {
+		require.Less(t, i, 10, "the audit logs never showed node 4 equivocating")
+		val := fmt.Sprint(i)
+		c.Put(t, val, &types.KeyValue{Key: "hello", Val: val}, honest...)
+		c.RequireValue(t, "hello", val, 15*time.Second, honest...)
+		// the round is logged when the next one starts
+		time.Sleep(c.Configs[0].RoundDuration)
+
+		// the honest nodes applied the same proposals but saw different votes from node 4
+		equivocations = nil
+		for _, d := range audit.Compare(readAuditLogs(t, path, honest)) {
+			require.False(t, d.Kind == "applied" && d.Severity == audit.Safety, d.String())
+			if d.Kind == "equivocation" {
+				equivocations = append(equivocations, d)
+			}
+		}
+	}
+	for _, d := range equivocations {
+		require.Contains(t, d.Detail, "node 4 sent a")
+	}
+}
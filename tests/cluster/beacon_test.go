@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeacon(t *testing.T) {
+	c := New(t, 5, WithBeacon(t), WithFaults(map[uint32]string{4: "withhold"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	// all nodes recover the same verifiable value for a round
+	res, err := types.NewExternalRPCClient(c.Client(t, 0)).GetBeacon(context.Background(), &types.GetBeaconReq{})
+	require.NoError(t, err)
+	require.NoError(t, beacon.Verify(c.Configs[0].Beacon.GroupKey, res.Round, res.Signature))
+	require.Equal(t, beacon.Randomness(res.Signature), res.Randomness)
+	for i := 1; i < len(c.Nodes); i++ {
+		other, err := types.NewExternalRPCClient(c.Client(t, i)).GetBeacon(context.Background(), &types.GetBeaconReq{Round: &res.Round})
+		require.NoError(t, err)
+		require.Equal(t, res.Signature, other.Signature)
+	}
+
+	// and seed sortition with it
+	admin := c.Admin(t, 2)
+	client := types.NewExternalRPCClient(c.Client(t, 2))
+	require.Eventually(t, func() bool {
+		status, err := admin.Status(context.Background(), &types.StatusReq{})
+		require.NoError(t, err)
+		res, err := client.GetBeacon(context.Background(), &types.GetBeaconReq{Round: &status.Round})
+		return err == nil && bytes.Equal(status.Seed[4:], res.Randomness)
+	}, 5*time.Second, 100*time.Millisecond)
+
+	far := res.Round + 10000
+	_, err = client.GetBeacon(context.Background(), &types.GetBeaconReq{Round: &far})
+	require.Error(t, err)
+}
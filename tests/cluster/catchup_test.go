@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/fault"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+// cutOff sends nothing to the given peers
+type cutOff struct {
+	fault.Honest
+	peers []int
+}
+
+func (b cutOff) OnSend(msg *types.Message, numPeers int) []fault.Outgoing {
+	var to []int
+	for i := range numPeers {
+		if !slices.Contains(b.peers, i) {
+			to = append(to, i)
+		}
+	}
+	return []fault.Outgoing{{Msg: msg, Indices: to}}
+}
+
+func TestCatchUp(t *testing.T) {
+	c := New(t, 4)
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	// node 3 hears nothing while the others commit a few rounds without it
+	for i := range 3 {
+		c.Nodes[i].InjectBehavior(cutOff{peers: []int{3}})
+	}
+	c.Put(t, "2", &types.KeyValue{Key: "missed", Val: "yes"}, 0, 1, 2)
+	c.Put(t, "3", &types.KeyValue{Key: "hello"}, 0, 1, 2)
+	c.RequireValue(t, "missed", "yes", 15*time.Second, 0, 1, 2)
+	c.RequireValue(t, "hello", "", 15*time.Second, 0, 1, 2)
+	time.Sleep(3 * c.Configs[0].RoundDuration)
+	require.Equal(t, "", c.Get(t, 3, "missed"))
+
+	// once it hears from them again it takes their state instead of skipping the rounds it missed
+	for i := range 3 {
+		c.Nodes[i].InjectBehavior(nil)
+	}
+	c.RequireValue(t, "missed", "yes", 15*time.Second, 3)
+	require.Equal(t, "", c.Get(t, 3, "hello"))
+	require.GreaterOrEqual(t, metricValue(t, c.Metrics(t, 3), `beeftea_state_syncs_total{outcome="synced"}`), 1.0)
+
+	// and keeps up from there
+	c.Put(t, "4", &types.KeyValue{Key: "after", Val: "yes"})
+	c.RequireValue(t, "after", "yes", 15*time.Second)
+}
@@ -0,0 +1,132 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/client"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client of the cluster whose connection to node 0 goes nowhere
+func newClient(t *testing.T, c *Cluster, verified bool) *client.Client {
+	config := client.Config{Verified: verified, ChainHash: c.Configs[0].ChainHash, Timeout: 15 * time.Second, AttemptTimeout: 500 * time.Millisecond}
+	for i, peer := range c.Configs[0].Peers {
+		n := &client.Node{Addr: c.Configs[i].RPCListenAddr, Learner: peer.Learner, BLSKey: peer.BLSKey.PublicKey()}
+		if i == 0 {
+			n.Addr = freeAddr(t)
+		}
+		config.Nodes = append(config.Nodes, n)
+	}
+	cl, err := client.New(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { cl.Close() })
+	return cl
+}
+
+func TestClient(t *testing.T) {
+	c := New(t, 5, WithBLS(), WithLearners(4))
+	admin := c.Admin(t, 3)
+	_, err := admin.SetBehavior(context.Background(), &types.SetBehaviorReq{Spec: "corrupt-state=evil"})
+	require.NoError(t, err)
+	cl := newClient(t, c, false)
+	verified := newClient(t, c, true)
+	ctx := context.Background()
+
+	// the writes go around node 0 and the reads outvote node 3
+	_, err = cl.Put(ctx, "hello", "world")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		val, err := cl.Get(ctx, "hello")
+		require.NoError(t, err)
+		return val == "world"
+	}, 15*time.Second, 100*time.Millisecond)
+
+	// whichever node a verified read starts at, node 3's proofs don't check out
+	for range 10 {
+		res, err := verified.GetVerified(ctx, "hello")
+		require.NoError(t, err)
+		require.Equal(t, "world", res.Kv.Val)
+		require.NotZero(t, res.Round)
+	}
+	_, err = verified.GetVerified(ctx, "never written")
+	require.ErrorIs(t, err, client.ErrNoProof)
+	val, err := verified.Get(ctx, "never written")
+	require.NoError(t, err)
+	require.Equal(t, "", val)
+
+	// a request is committed once whatever node it is retried on
+	req := &types.PutReq{Id: "once", Kv: &types.KeyValue{Key: "counter", Val: "1"}}
+	require.NoError(t, cl.Submit(ctx, req))
+	c.RequireValue(t, "counter", "1", 15*time.Second, 1, 2, 4)
+	req.Kv.Val = "2"
+	require.NoError(t, cl.Submit(ctx, req))
+	c.Put(t, "after", &types.KeyValue{Key: "after", Val: "yes"}, 1, 2)
+	c.RequireValue(t, "after", "yes", 15*time.Second, 1, 2, 4)
+	require.Equal(t, "1", c.Get(t, 1, "counter"))
+}
+
+func TestClientDeleteScanWatch(t *testing.T) {
+	c := New(t, 4, WithBLS())
+	cl := newClient(t, c, true)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	events := make(chan *types.WatchEvent, 10)
+	go cl.Watch(ctx, "user/", func(e *types.WatchEvent) error {
+		events <- e
+		return nil
+	})
+	// the watch starts once the client reached a node
+	time.Sleep(time.Second)
+
+	for _, kv := range [][2]string{{"user/1", "a"}, {"user/2", "b"}, {"config", "c"}} {
+		_, err := cl.Put(ctx, kv[0], kv[1])
+		require.NoError(t, err)
+	}
+	c.RequireValue(t, "user/1", "a", 15*time.Second)
+	c.RequireValue(t, "user/2", "b", 15*time.Second)
+	c.RequireValue(t, "config", "c", 15*time.Second)
+	_, err := cl.Delete(ctx, "user/1")
+	require.NoError(t, err)
+	c.RequireValue(t, "user/1", "", 15*time.Second)
+
+	res, err := cl.Scan(ctx, "user/", 0)
+	require.NoError(t, err)
+	require.Len(t, res.Kvs, 1)
+	require.Equal(t, "user/2", res.Kvs[0].Key)
+	res, err = cl.Scan(ctx, "", 1)
+	require.NoError(t, err)
+	require.Equal(t, "config", res.Kvs[0].Key)
+	require.True(t, res.Truncated)
+
+	// the delete is proven like a write
+	proven, err := cl.GetVerified(ctx, "user/1")
+	require.NoError(t, err)
+	require.Equal(t, "", proven.Kv.Val)
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case e := <-events:
+			got = append(got, fmt.Sprintf("%s=%s deleted=%t", e.Kv.Key, e.Kv.Val, e.Deleted))
+		case <-ctx.Done():
+			t.Fatalf("only got events %v", got)
+		}
+	}
+	require.ElementsMatch(t, []string{"user/1=a deleted=false", "user/2=b deleted=false", "user/1= deleted=true"}, got)
+}
+
+func TestClientDeadline(t *testing.T) {
+	cl, err := client.NewFromAddrs([]string{freeAddr(t), freeAddr(t)})
+	require.NoError(t, err)
+	defer cl.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = cl.Put(ctx, "hello", "world")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = cl.Get(ctx, "hello")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/clock"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+// requireSameRound waits until all nodes are in rounds at most one apart
+func requireSameRound(t *testing.T, c *Cluster) {
+	require.Eventually(t, func() bool {
+		var rounds []uint32
+		for _, node := range c.Nodes {
+			res, err := node.Status(context.Background(), &types.StatusReq{})
+			require.NoError(t, err)
+			rounds = append(rounds, res.Round)
+		}
+		return slices.Max(rounds)-slices.Min(rounds) <= 1
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestClockOffset(t *testing.T) {
+	// rounds don't depend on what time the nodes think it is
+	c := New(t, 5, WithClocks(map[int]clock.Clock{
+		0: clock.Skewed{Offset: time.Hour},
+		3: clock.Skewed{Offset: -25 * time.Minute},
+	}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	requireSameRound(t, c)
+}
+
+func TestClockRate(t *testing.T) {
+	// node 4's timers are 3 times slower and node 3's twice faster, the Timeouts of the others
+	// keep them in step
+	c := New(t, 5, WithClocks(map[int]clock.Clock{
+		3: clock.Skewed{Rate: 0.5},
+		4: clock.Skewed{Rate: 3},
+	}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	requireSameRound(t, c)
+	// still in step a few rounds later
+	time.Sleep(3 * c.Configs[0].RoundDuration)
+	requireSameRound(t, c)
+	c.Put(t, "2", &types.KeyValue{Key: "hello", Val: "again"})
+	c.RequireValue(t, "hello", "again", 15*time.Second)
+}
+
+func TestClockRateResponsive(t *testing.T) {
+	c := New(t, 5, WithResponsive(), WithClocks(map[int]clock.Clock{
+		3: clock.Skewed{Rate: 0.5},
+		4: clock.Skewed{Rate: 3},
+	}))
+
+	for _, val := range []string{"a", "b", "c"} {
+		c.Put(t, val, &types.KeyValue{Key: "hello", Val: val})
+		c.RequireValue(t, "hello", val, 15*time.Second)
+	}
+	requireSameRound(t, c)
+}
@@ -0,0 +1,402 @@
+// Package cluster runs a cluster of beeftea nodes inside the test process, so that tests can script
+// scenarios (e.g. Byzantine nodes) without docker.
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/clock"
+	"github.com/patrickmao1/beeftea/consensus"
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/genesis"
+	"github.com/patrickmao1/beeftea/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type Cluster struct {
+	Nodes   []*consensus.Service
+	Configs []*types.Config
+	// Signs the membership changes, see Sign
+	Operator crypto.PrivateKey
+
+	stopped map[int]bool
+}
+
+// Option modifies the config of node i before it starts
+type Option func(i int, config *types.Config)
+
+// WithFaults injects Byzantine behaviors from the start, node index -> fault spec
+func WithFaults(faults map[uint32]string) Option {
+	return func(_ int, config *types.Config) {
+		config.Faults = faults
+	}
+}
+
+// WithDurations overrides the default round and proposal phase durations
+func WithDurations(round, proposal time.Duration) Option {
+	return func(_ int, config *types.Config) {
+		config.RoundDuration = round
+		config.ProposalDuration = proposal
+	}
+}
+
+// WithProposalThreshold overrides the VRF score below which nodes propose
+func WithProposalThreshold(threshold uint32) Option {
+	return func(_ int, config *types.Config) {
+		config.ProposalThreshold = threshold
+	}
+}
+
+// WithWeights gives the nodes the given weights, see types.Peer.Weight, and sets the proposal
+// threshold for their total
+func WithWeights(weights ...uint64) Option {
+	return func(_ int, config *types.Config) {
+		peers := make([]*types.Peer, len(config.Peers))
+		total := 0
+		for i, peer := range config.Peers {
+			weighted := *peer
+			weighted.Weight = weights[i]
+			peers[i] = &weighted
+			total += int(weights[i])
+		}
+		config.Peers = peers
+		config.ProposalThreshold = consensus.ProposalThreshold(total)
+	}
+}
+
+// WithLearners makes the given nodes learners, see types.Peer.Learner
+func WithLearners(nodes ...int) Option {
+	return func(_ int, config *types.Config) {
+		peers := make([]*types.Peer, len(config.Peers))
+		for i, peer := range config.Peers {
+			learner := *peer
+			learner.Learner = slices.Contains(nodes, i)
+			peers[i] = &learner
+		}
+		config.Peers = peers
+	}
+}
+
+// WithBeacon runs a randomness beacon to seed sortition, see package beacon
+func WithBeacon(t testing.TB) Option {
+	var keys *beacon.Public
+	var secrets [][]byte
+	return func(i int, config *types.Config) {
+		if keys == nil {
+			var err error
+			keys, secrets, err = beacon.Deal(rand.Reader, len(config.Peers), beacon.Threshold(len(config.Peers)))
+			require.NoError(t, err)
+		}
+		config.Beacon = keys
+		config.BeaconSecret = secrets[i]
+	}
+}
+
+// WithKeyAlgorithms gives peer i a key of algs[i%len(algs)]
+func WithKeyAlgorithms(algs ...crypto.Algorithm) Option {
+	var keys []crypto.PrivateKey
+	return func(_ int, config *types.Config) {
+		if keys == nil {
+			for i := range config.Peers {
+				keys = append(keys, crypto.GenKeyAlg(algs[i%len(algs)]))
+			}
+		}
+		peers := make([]*types.Peer, len(config.Peers))
+		for i, peer := range config.Peers {
+			withKey := *peer
+			withKey.Key = keys[i]
+			peers[i] = &withKey
+		}
+		config.Peers = peers
+	}
+}
+
+// WithGenesis makes the nodes run the network of doc, starting from its initial state
+func WithGenesis(doc *genesis.Doc) Option {
+	return func(_ int, config *types.Config) {
+		config.ChainID = doc.ChainID
+		config.ChainHash = doc.Hash()
+		config.InitialState = doc.InitialState
+	}
+}
+
+// WithBLS gives the nodes BLS keys so that they build quorum certificates
+func WithBLS() Option {
+	var keys []*crypto.BLSPrivateKey
+	return func(_ int, config *types.Config) {
+		if keys == nil {
+			for range config.Peers {
+				keys = append(keys, crypto.GenBLSKey())
+			}
+		}
+		peers := make([]*types.Peer, len(config.Peers))
+		for i, peer := range config.Peers {
+			withKey := *peer
+			withKey.BLSKey = keys[i]
+			peers[i] = &withKey
+		}
+		config.Peers = peers
+	}
+}
+
+// WithResponsive runs the nodes in responsive mode, see types.Config.Responsive
+func WithResponsive() Option {
+	return func(_ int, config *types.Config) {
+		config.Responsive = true
+	}
+}
+
+// WithAdaptiveTimeouts lets the nodes adapt their timeouts, see types.Config.AdaptiveTimeouts
+func WithAdaptiveTimeouts() Option {
+	return func(_ int, config *types.Config) {
+		config.AdaptiveTimeouts = true
+	}
+}
+
+// WithTraceFile exports the spans of node i as JSON lines to path.i
+func WithTraceFile(path string) Option {
+	return func(i int, config *types.Config) {
+		config.TraceFile = fmt.Sprintf("%s.%d", path, i)
+	}
+}
+
+// WithAuditLog writes the audit log of node i to path.i
+func WithAuditLog(path string) Option {
+	return func(i int, config *types.Config) {
+		config.AuditLogFile = fmt.Sprintf("%s.%d", path, i)
+	}
+}
+
+// WithHTTP serves the HTTP/JSON gateway of every node, see package gateway
+func WithHTTP() Option {
+	return func(_ int, config *types.Config) {
+		config.HTTPListenAddr = anyPort
+	}
+}
+
+// WithClocks gives the given nodes their own clocks, node index -> clock
+func WithClocks(clocks map[int]clock.Clock) Option {
+	return func(i int, config *types.Config) {
+		config.Clock = clocks[i]
+	}
+}
+
+// listen addresses on a port picked by the OS, see types.Config.ListenAddr
+const anyPort = "127.0.0.1:0"
+
+// New starts n nodes listening on free localhost ports, which are stopped when the test ends
+func New(t testing.TB, n int, opts ...Option) *Cluster {
+	log.SetLevel(log.WarnLevel)
+
+	base := &types.Config{
+		RoundDuration:     time.Second,
+		ProposalDuration:  300 * time.Millisecond,
+		ProposalThreshold: consensus.ProposalThreshold(n),
+		// the tests script Byzantine nodes
+		AllowFaultInjection: true,
+	}
+	operator := crypto.GenKey()
+	base.OperatorKey = operator.PublicKey()
+	// the peers have to know each other's consensus address before they start
+	var listeners []net.Listener
+	for i := 0; i < n; i++ {
+		lis := Listen(t)
+		listeners = append(listeners, lis)
+		base.Peers = append(base.Peers, &types.Peer{URL: lis.Addr().String(), Key: crypto.GenKey()})
+	}
+
+	c := &Cluster{Operator: operator, stopped: make(map[int]bool)}
+	t.Cleanup(func() { c.Stop(t) })
+	for i := 0; i < n; i++ {
+		config := *base
+		config.SetMyIndex(uint32(i))
+		config.Listener = listeners[i]
+		config.RPCListenAddr = anyPort
+		config.AdminListenAddr = anyPort
+		config.MetricsListenAddr = anyPort
+		for _, opt := range opts {
+			opt(i, &config)
+		}
+		c.start(t, &config)
+	}
+	return c
+}
+
+// Listen listens on a free localhost port, for the consensus server of a node that will join,
+// see Join
+func Listen(t testing.TB) net.Listener {
+	lis, err := net.Listen("tcp", anyPort)
+	require.NoError(t, err)
+	return lis
+}
+
+// Join starts a node with key that joins the running cluster from node 0 and returns its index
+// in Nodes. A Reconfigure must have added it at the address of lis, see types.Config.JoinFrom.
+func (c *Cluster) Join(t testing.TB, key crypto.PrivateKey, lis net.Listener) int {
+	config := *c.Configs[0]
+	config.Peers = []*types.Peer{{URL: lis.Addr().String(), Key: key}}
+	config.SetMyIndex(0)
+	config.JoinFrom = c.Configs[0].ListenAddr
+	config.Listener = lis
+	config.RPCListenAddr = anyPort
+	config.AdminListenAddr = anyPort
+	config.MetricsListenAddr = anyPort
+	config.HTTPListenAddr = ""
+	config.Faults = nil
+	config.AuditLogFile = ""
+	config.TraceFile = ""
+	c.start(t, &config)
+	return len(c.Nodes) - 1
+}
+
+func (c *Cluster) start(t testing.TB, config *types.Config) {
+	node := consensus.NewService(config)
+	require.NoError(t, node.Start())
+	c.Nodes = append(c.Nodes, node)
+	c.Configs = append(c.Configs, config)
+}
+
+// Stop stops the given nodes, or all nodes if none are given, unless they were stopped already
+func (c *Cluster) Stop(t testing.TB, nodes ...int) {
+	for _, i := range c.indices(nodes) {
+		if c.stopped[i] {
+			continue
+		}
+		c.stopped[i] = true
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		require.NoError(t, c.Nodes[i].Stop(ctx))
+		cancel()
+	}
+}
+
+// RequirePeers waits until all given nodes (or all nodes if none are given) have n peer slots,
+// removed ones included, of which the removed ones are the given ones
+func (c *Cluster) RequirePeers(t testing.TB, n int, removed []uint32, timeout time.Duration, nodes ...int) {
+	require.Eventually(t, func() bool {
+		for _, i := range c.indices(nodes) {
+			status, err := c.Nodes[i].Status(context.Background(), &types.StatusReq{})
+			require.NoError(t, err)
+			if len(status.Peers) != n {
+				return false
+			}
+			for _, peer := range status.Peers {
+				if peer.Removed != slices.Contains(removed, peer.Index) {
+					return false
+				}
+			}
+		}
+		return true
+	}, timeout, 100*time.Millisecond, "nodes %v never had %d peers with %v removed", c.indices(nodes), n, removed)
+}
+
+// Sign signs rc with the operator key as the next membership change of node 0's cluster
+func (c *Cluster) Sign(t testing.TB, rc *types.Reconfig) *types.Reconfig {
+	status, err := c.Nodes[0].Status(context.Background(), &types.StatusReq{})
+	require.NoError(t, err)
+	rc.Seq = status.ReconfigSeq
+	rc.Sign(c.Operator, status.ChainHash)
+	return rc
+}
+
+// Client connects to the external RPC server of node i
+func (c *Cluster) Client(t testing.TB, i int) *grpc.ClientConn {
+	cc, err := grpc.NewClient(c.Configs[i].RPCListenAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+// Admin connects to the admin server of node i
+func (c *Cluster) Admin(t testing.TB, i int) types.AdminRPCClient {
+	cc, err := grpc.NewClient(c.Configs[i].AdminListenAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return types.NewAdminRPCClient(cc)
+}
+
+// Put submits kv to the given nodes, or all nodes if none are given
+func (c *Cluster) Put(t testing.TB, id string, kv *types.KeyValue, nodes ...int) {
+	for _, i := range c.indices(nodes) {
+		_, err := c.Nodes[i].Put(context.Background(), &types.PutReq{Id: id, Kv: kv})
+		require.NoError(t, err)
+	}
+}
+
+// Get reads key from node i
+func (c *Cluster) Get(t testing.TB, i int, key string) string {
+	res, err := c.Nodes[i].Get(context.Background(), &types.GetReq{Key: key})
+	require.NoError(t, err)
+	return res.Kv.Val
+}
+
+// RequireValue waits until all given nodes (or all nodes if none are given) have val for key
+func (c *Cluster) RequireValue(t testing.TB, key, val string, timeout time.Duration, nodes ...int) {
+	require.Eventually(t, func() bool {
+		for _, i := range c.indices(nodes) {
+			if c.Get(t, i, key) != val {
+				return false
+			}
+		}
+		return true
+	}, timeout, 100*time.Millisecond, "nodes %v never agreed on %s=%s", c.indices(nodes), key, val)
+}
+
+// QuorumGet reads key from all nodes and returns the value that f+1 of them agree on
+func (c *Cluster) QuorumGet(t testing.TB, key string) (string, error) {
+	counts := make(map[string]int)
+	for i := range c.Nodes {
+		counts[c.Get(t, i, key)]++
+	}
+	maxVal, maxCount := "", 0
+	for val, count := range counts {
+		if count > maxCount {
+			maxVal, maxCount = val, count
+		}
+	}
+	f := (len(c.Nodes) - 1) / 3
+	if maxCount < f+1 {
+		return "", fmt.Errorf("no value for key %s has reached quorum: %v", key, counts)
+	}
+	return maxVal, nil
+}
+
+// Metrics scrapes the metrics of node i
+func (c *Cluster) Metrics(t testing.TB, i int) string {
+	res, err := http.Get("http://" + c.Configs[i].MetricsListenAddr + "/metrics")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func (c *Cluster) indices(nodes []int) []int {
+	if len(nodes) > 0 {
+		return nodes
+	}
+	all := make([]int, len(c.Nodes))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// freeAddr returns an address that nothing listens on, for clients of nodes that are down
+func freeAddr(t testing.TB) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	return lis.Addr().String()
+}
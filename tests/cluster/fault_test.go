@@ -0,0 +1,109 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/fault"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+var honest = []int{0, 1, 2, 3}
+
+func TestEquivocatingNode(t *testing.T) {
+	c := New(t, 5, WithFaults(map[uint32]string{4: "equivocate"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"}, honest...)
+	c.RequireValue(t, "hello", "world", 15*time.Second, honest...)
+
+	// the nodes that received both of node 4's votes have proof of it
+	require.Eventually(t, func() bool {
+		for _, i := range honest {
+			res, err := c.Nodes[i].ListEvidence(context.Background(), &types.ListEvidenceReq{})
+			require.NoError(t, err)
+			if len(res.Proofs) > 0 {
+				require.EqualValues(t, 4, res.Proofs[0].First.NodeIndex)
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestWithholdingNode(t *testing.T) {
+	c := New(t, 5, WithFaults(map[uint32]string{4: "withhold"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"}, honest...)
+	c.RequireValue(t, "hello", "world", 15*time.Second, honest...)
+}
+
+func TestGarbageDigestNode(t *testing.T) {
+	c := New(t, 5, WithFaults(map[uint32]string{4: "garbage-digest=4"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"}, honest...)
+	c.RequireValue(t, "hello", "world", 15*time.Second, honest...)
+}
+
+func TestCorruptStateNode(t *testing.T) {
+	c := New(t, 5)
+	// turn node 4 malicious through the admin RPC
+	admin := c.Admin(t, 4)
+	_, err := admin.SetBehavior(context.Background(), &types.SetBehaviorReq{Spec: "corrupt-state=evil"})
+	require.NoError(t, err)
+	_, err = admin.SetBehavior(context.Background(), &types.SetBehaviorReq{Spec: "not-a-fault"})
+	require.Error(t, err)
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second, honest...)
+	c.RequireValue(t, "hello", "evil", 5*time.Second, 4)
+
+	val, err := c.QuorumGet(t, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "world", val)
+}
+
+func TestWithholdingMajorityStalls(t *testing.T) {
+	// three withholding nodes leave the other two two votes short of a quorum
+	c := New(t, 5, WithFaults(map[uint32]string{2: "withhold", 3: "withhold", 4: "withhold"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	time.Sleep(5 * time.Second)
+	for i := range c.Nodes {
+		require.Empty(t, c.Get(t, i, "hello"))
+	}
+
+	// one turning honest again isn't enough, a quorum holds more than 2/3 of the weight
+	c.Nodes[2].InjectBehavior(nil)
+	time.Sleep(3 * time.Second)
+	require.Empty(t, c.Get(t, 0, "hello"))
+
+	// the cluster recovers once two of them do
+	c.Nodes[3].InjectBehavior(nil)
+	c.RequireValue(t, "hello", "world", 15*time.Second, 0, 1, 2, 3)
+}
+
+// untyped sends a message without a type along with each of its messages
+type untyped struct{ fault.Honest }
+
+func (untyped) OnSend(msg *types.Message, _ int) []fault.Outgoing {
+	return []fault.Outgoing{{Msg: &types.Message{Round: msg.Round}}, {Msg: msg}}
+}
+
+func TestUntypedMessages(t *testing.T) {
+	c := New(t, 5)
+	c.Nodes[4].InjectBehavior(untyped{})
+
+	// the others drop them and carry on
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+}
+
+func TestDelayedNode(t *testing.T) {
+	c := New(t, 5)
+	c.Nodes[4].InjectBehavior(fault.Delay{Duration: 200 * time.Millisecond})
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+}
@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/gateway"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestGateway(t *testing.T) {
+	c := New(t, 5, WithLearners(4), WithHTTP())
+	url := func(i int, path string) string {
+		return "http://" + c.Configs[i].HTTPListenAddr + path
+	}
+	do := func(method, url, body string) (int, string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		bs, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(bs)
+	}
+
+	watch, err := http.Get(url(4, "/v1/watch?prefix=user/"))
+	require.NoError(t, err)
+	defer watch.Body.Close()
+	events := bufio.NewScanner(watch.Body)
+
+	for i := range 4 {
+		code, body := do("PUT", url(i, "/v1/kv/user/1"), `{"id": "1", "val": "a"}`)
+		require.Equal(t, http.StatusOK, code, body)
+		do("PUT", url(i, "/v1/kv/config"), `{"id": "2", "val": "b"}`)
+	}
+	c.RequireValue(t, "user/1", "a", 15*time.Second)
+	c.RequireValue(t, "config", "b", 15*time.Second)
+
+	code, body := do("GET", url(4, "/v1/kv/user/1"), "")
+	require.Equal(t, http.StatusOK, code)
+	res := &types.GetRes{}
+	require.NoError(t, protojson.Unmarshal([]byte(body), res))
+	require.Equal(t, "a", res.Kv.Val)
+
+	code, body = do("GET", url(1, "/v1/kv?prefix=user/"), "")
+	require.Equal(t, http.StatusOK, code)
+	scan := &types.ScanRes{}
+	require.NoError(t, protojson.Unmarshal([]byte(body), scan))
+	require.Len(t, scan.Kvs, 1)
+
+	for i := range 4 {
+		code, body = do("DELETE", url(i, "/v1/kv/user/1?id=3"), "")
+		require.Equal(t, http.StatusOK, code, body)
+	}
+	c.RequireValue(t, "user/1", "", 15*time.Second)
+
+	for _, deleted := range []bool{false, true} {
+		var line string
+		for line == "" {
+			require.True(t, events.Scan())
+			line = events.Text()
+		}
+		e := &types.WatchEvent{}
+		require.NoError(t, protojson.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), e))
+		require.Equal(t, "user/1", e.Kv.Key)
+		require.Equal(t, deleted, e.Deleted)
+	}
+
+	// learners refuse writes as they do over gRPC
+	code, body = do("PUT", url(4, "/v1/kv/k"), `{"id": "4", "val": "v"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	var e gateway.Error
+	require.NoError(t, json.Unmarshal([]byte(body), &e))
+	require.Equal(t, codes.FailedPrecondition, e.Code)
+}
@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/genesis"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenesis(t *testing.T) {
+	doc := &genesis.Doc{ChainID: "test", InitialState: map[string]string{"greeting": "hello"}}
+	other := &genesis.Doc{ChainID: "other"}
+	// node 3 runs another network, so its messages and the others' are rejected both ways
+	c := New(t, 4, WithGenesis(doc), func(i int, config *types.Config) {
+		if i == 3 {
+			WithGenesis(other)(i, config)
+		}
+	})
+
+	for i := range 3 {
+		require.Equal(t, "hello", c.Get(t, i, "greeting"))
+	}
+	require.Equal(t, "", c.Get(t, 3, "greeting"))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second, 0, 1, 2)
+	require.Equal(t, "", c.Get(t, 3, "hello"))
+
+	status, err := c.Admin(t, 0).Status(context.Background(), &types.StatusReq{})
+	require.NoError(t, err)
+	require.Equal(t, "test", status.ChainId)
+	require.Equal(t, doc.Hash(), status.ChainHash)
+}
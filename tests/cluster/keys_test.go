@@ -0,0 +1,18 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+)
+
+func TestMixedKeyAlgorithms(t *testing.T) {
+	c := New(t, 4, WithKeyAlgorithms(crypto.Ed25519, crypto.P256))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	c.Put(t, "2", &types.KeyValue{Key: "foo", Val: "bar"})
+	c.RequireValue(t, "foo", "bar", 15*time.Second)
+}
@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLearner(t *testing.T) {
+	c := New(t, 5, WithLearners(4))
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	watch, err := types.NewExternalRPCClient(c.Client(t, 4)).Watch(ctx, &types.WatchReq{Prefix: "user/"})
+	require.NoError(t, err)
+
+	c.Put(t, "1", &types.KeyValue{Key: "config", Val: "a"}, 0, 1, 2, 3)
+	c.Put(t, "2", &types.KeyValue{Key: "user/1", Val: "b"}, 0, 1, 2, 3)
+	c.RequireValue(t, "user/1", "b", 15*time.Second)
+	require.Equal(t, "a", c.Get(t, 4, "config"))
+
+	e, err := watch.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "user/1", e.Kv.Key)
+	require.Equal(t, "b", e.Kv.Val)
+
+	// the learner only listens
+	require.NotContains(t, c.Metrics(t, 4), "beeftea_messages_sent_total{")
+	require.Contains(t, c.Metrics(t, 0), "beeftea_messages_sent_total{")
+	_, err = c.Nodes[4].Put(ctx, &types.PutReq{Id: "3", Kv: &types.KeyValue{Key: "k", Val: "v"}})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
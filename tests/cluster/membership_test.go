@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReconfigure(t *testing.T) {
+	c := New(t, 4)
+	admin := c.Admin(t, 0)
+	ctx := context.Background()
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	// a new machine joins, taking the state of the others
+	key := crypto.GenKeyAlg(crypto.Ed25519)
+	lis := Listen(t)
+	add := &types.Reconfig{
+		Op:        types.ReconfigOp_RECONFIG_OP_ADD,
+		Url:       lis.Addr().String(),
+		PublicKey: crypto.MarshalPublic(key.PublicKey()),
+	}
+	_, err := admin.Reconfigure(ctx, &types.ReconfigureReq{Id: "add", Reconfig: add})
+	require.Equal(t, codes.InvalidArgument, status.Code(err), "changes must be signed by the operator")
+	_, err = admin.Reconfigure(ctx, &types.ReconfigureReq{Id: "add", Reconfig: c.Sign(t, add)})
+	require.NoError(t, err)
+	c.RequirePeers(t, 5, nil, 30*time.Second)
+	joiner := c.Join(t, key, lis)
+	require.Equal(t, 4, joiner)
+	require.Equal(t, "world", c.Get(t, joiner, "hello"))
+
+	c.Put(t, "2", &types.KeyValue{Key: "joined", Val: "yes"})
+	c.RequireValue(t, "joined", "yes", 30*time.Second)
+
+	// node 1 is removed, the others go on without it
+	_, err = admin.Reconfigure(ctx, &types.ReconfigureReq{Id: "remove", Reconfig: c.Sign(t, &types.Reconfig{
+		Op:    types.ReconfigOp_RECONFIG_OP_REMOVE,
+		Index: 1,
+	})})
+	require.NoError(t, err)
+	c.RequirePeers(t, 5, []uint32{1}, 30*time.Second, 0, 2, 3, 4)
+	c.Put(t, "3", &types.KeyValue{Key: "removed", Val: "yes"}, 0, 2, 3, 4)
+	c.RequireValue(t, "removed", "yes", 30*time.Second, 0, 2, 3, 4)
+	require.Equal(t, "", c.Get(t, 1, "removed"))
+
+	// membership changes don't go through Put, and must apply
+	_, err = c.Nodes[0].Put(ctx, &types.PutReq{Id: "4", Reconfig: &types.Reconfig{Op: types.ReconfigOp_RECONFIG_OP_REMOVE}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = admin.Reconfigure(ctx, &types.ReconfigureReq{Id: "5", Reconfig: c.Sign(t, &types.Reconfig{
+		Op:    types.ReconfigOp_RECONFIG_OP_REMOVE,
+		Index: 1,
+	})})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
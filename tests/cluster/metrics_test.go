@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	c := New(t, 5)
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	// the committed round is counted when the next one starts
+	require.Eventually(t, func() bool {
+		return metricValue(t, c.Metrics(t, 0), `beeftea_rounds_total{outcome="committed"}`) >= 1
+	}, 5*time.Second, 100*time.Millisecond)
+	_, err := types.NewExternalRPCClient(c.Client(t, 0)).Get(context.Background(), &types.GetReq{Key: "hello"})
+	require.NoError(t, err)
+
+	body := c.Metrics(t, 0)
+	for _, line := range []string{
+		`beeftea_kv_keys 1`,
+		`beeftea_mempool_reqs 0`,
+		`beeftea_future_buffer_messages 0`,
+		`beeftea_quorum_latency_seconds_count{phase="commit"}`,
+		`beeftea_phase_duration_seconds_count{phase="proposal"}`,
+		`beeftea_rpc_duration_seconds_count{code="OK",method="/beeftea.ExternalRPC/Get"} 1`,
+	} {
+		require.Contains(t, body, line)
+	}
+}
+
+// metricValue returns the value of the sample with the given name and labels, 0 if there is none
+func metricValue(t *testing.T, body, sample string) float64 {
+	for _, line := range strings.Split(body, "\n") {
+		if val, ok := strings.CutPrefix(line, sample+" "); ok {
+			v, err := strconv.ParseFloat(val, 64)
+			require.NoError(t, err)
+			return v
+		}
+	}
+	return 0
+}
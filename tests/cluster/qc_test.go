@@ -0,0 +1,37 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/crypto"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorumCertificate(t *testing.T) {
+	c := New(t, 4, WithBLS())
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	admin := c.Admin(t, 1)
+	var qc *types.QuorumCertificate
+	require.Eventually(t, func() bool {
+		status, err := admin.Status(context.Background(), &types.StatusReq{})
+		require.NoError(t, err)
+		qc = status.LastCommitQc
+		return qc != nil
+	}, 5*time.Second, 100*time.Millisecond)
+	require.Equal(t, types.Phase_PHASE_COMMIT, qc.Phase)
+
+	// the certificate verifies against the public keys of the signers from the config
+	signers := crypto.Bitmap(qc.Signers).Indices()
+	require.GreaterOrEqual(t, len(signers), 3)
+	var keys []*crypto.BLSPublicKey
+	for _, signer := range signers {
+		keys = append(keys, c.Configs[1].Peers[signer].BLSKey.PublicKey())
+	}
+	require.True(t, crypto.VerifyAggregateBLS(keys, qc.SignedMsg(c.Configs[1].ChainHash), qc.AggSig))
+}
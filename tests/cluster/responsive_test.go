@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponsive(t *testing.T) {
+	c := New(t, 5, WithResponsive(), WithDurations(2*time.Second, 100*time.Millisecond))
+
+	// each commit takes far less than a round timeout
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		val := fmt.Sprint(i)
+		c.Put(t, val, &types.KeyValue{Key: "hello", Val: val})
+		c.RequireValue(t, "hello", val, 10*time.Second)
+	}
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestResponsiveWithholdingNode(t *testing.T) {
+	c := New(t, 5, WithResponsive(), WithFaults(map[uint32]string{4: "withhold"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"}, honest...)
+	c.RequireValue(t, "hello", "world", 15*time.Second, honest...)
+}
+
+// BenchmarkCommits measures how many batches the cluster commits per second when a client waits
+// for each request to be applied before sending the next one
+func BenchmarkCommits(b *testing.B) {
+	for _, mode := range []struct {
+		name string
+		opts []Option
+	}{
+		{"fixed-rounds", nil},
+		{"responsive", []Option{WithResponsive()}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			c := New(b, 5, mode.opts...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				val := fmt.Sprint(i)
+				c.Put(b, val, &types.KeyValue{Key: "bench", Val: val})
+				require.Eventually(b, func() bool {
+					for j := range c.Nodes {
+						if c.Get(b, j, "bench") != val {
+							return false
+						}
+					}
+					return true
+				}, time.Minute, 5*time.Millisecond)
+			}
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "commits/s")
+		})
+	}
+}
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	c := New(t, 5, WithResponsive(), WithAdaptiveTimeouts(), WithDurations(2*time.Second, 300*time.Millisecond))
+
+	for i := 0; i < 5; i++ {
+		val := fmt.Sprint(i)
+		c.Put(t, val, &types.KeyValue{Key: "hello", Val: val})
+		c.RequireValue(t, "hello", val, 10*time.Second)
+	}
+
+	// the nodes adopted the same, shorter round timeout from the committed proposals
+	timeout := regexp.MustCompile(`(?m)^beeftea_round_timeout_seconds (\S+)$`)
+	require.Eventually(t, func() bool {
+		var timeouts []string
+		for i := range c.Nodes {
+			timeouts = append(timeouts, timeout.FindStringSubmatch(c.Metrics(t, i))[1])
+		}
+		seconds, err := strconv.ParseFloat(timeouts[0], 64)
+		require.NoError(t, err)
+		return seconds < 2 && slices.Equal(timeouts, slices.Repeat(timeouts[:1], len(timeouts)))
+	}, 5*time.Second, 100*time.Millisecond)
+}
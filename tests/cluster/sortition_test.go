@@ -0,0 +1,51 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/fault"
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupProposer(t *testing.T) {
+	// no node is ever below the threshold, only the backup proposers propose
+	c := New(t, 5, WithProposalThreshold(0))
+
+	for _, val := range []string{"a", "b"} {
+		c.Put(t, val, &types.KeyValue{Key: "hello", Val: val})
+		c.RequireValue(t, "hello", val, 15*time.Second)
+	}
+}
+
+func TestEmptyRounds(t *testing.T) {
+	c := New(t, 5, WithResponsive())
+
+	// idle rounds commit no-op proposals
+	require.Eventually(t, func() bool {
+		return strings.Contains(c.Metrics(t, 0), `beeftea_rounds_total{outcome="empty"}`)
+	}, 10*time.Second, 100*time.Millisecond)
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+}
+
+func TestWeightedVotes(t *testing.T) {
+	// nodes 0 to 2 hold 5/7 of the weight, a quorum without the two withholding nodes
+	c := New(t, 5, WithWeights(3, 1, 1, 1, 1), WithFaults(map[uint32]string{3: "withhold", 4: "withhold"}))
+
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	// without the heavy node the four others are short of a quorum
+	c.Nodes[3].InjectBehavior(nil)
+	c.Nodes[4].InjectBehavior(nil)
+	c.Nodes[0].InjectBehavior(fault.Withhold{})
+	c.Put(t, "2", &types.KeyValue{Key: "hello", Val: "again"})
+	time.Sleep(5 * time.Second)
+	for i := range c.Nodes {
+		require.Equal(t, "world", c.Get(t, i, "hello"))
+	}
+}
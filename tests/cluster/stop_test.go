@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStop(t *testing.T) {
+	c := New(t, 5)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	watch, err := types.NewExternalRPCClient(c.Client(t, 4)).Watch(ctx, &types.WatchReq{})
+	require.NoError(t, err)
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	// the watches of a stopped node end, and its ports are free again
+	c.Stop(t, 4)
+	for {
+		_, err = watch.Recv()
+		if err != nil {
+			break
+		}
+	}
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	for _, addr := range []string{c.Configs[4].ListenAddr, c.Configs[4].RPCListenAddr, c.Configs[4].MetricsListenAddr} {
+		lis, err := net.Listen("tcp", addr)
+		require.NoError(t, err)
+		lis.Close()
+	}
+
+	// the others go on without it
+	c.Put(t, "2", &types.KeyValue{Key: "hello", Val: "again"}, 0, 1, 2, 3)
+	c.RequireValue(t, "hello", "again", 15*time.Second, 0, 1, 2, 3)
+}
+
+func TestStopLeaks(t *testing.T) {
+	before := runtime.NumGoroutine()
+	c := New(t, 4, WithHTTP())
+	c.Put(t, "1", &types.KeyValue{Key: "hello", Val: "world"})
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+	c.Stop(t)
+
+	// not require.Eventually, whose own goroutines would be counted
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<20)
+			t.Fatalf("%d goroutines left over from %d:\n%s", runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
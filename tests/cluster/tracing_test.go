@@ -0,0 +1,70 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patrickmao1/beeftea/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+type exportedSpan struct {
+	Name        string
+	SpanContext struct {
+		TraceID string
+	}
+}
+
+func readSpans(t *testing.T, file string) []exportedSpan {
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	var spans []exportedSpan
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var span exportedSpan
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		spans = append(spans, span)
+	}
+	require.NoError(t, scanner.Err())
+	return spans
+}
+
+func TestTracing(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "spans")
+	c := New(t, 5, WithTraceFile(traceFile))
+
+	// the client starts the trace and sends its context in the request metadata
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"traceparent", fmt.Sprintf("00-%s-00f067aa0ba902b7-01", traceID))
+	_, err := types.NewExternalRPCClient(c.Client(t, 0)).Put(ctx, &types.PutReq{
+		Id: "1",
+		Kv: &types.KeyValue{Key: "hello", Val: "world"},
+	})
+	require.NoError(t, err)
+	c.RequireValue(t, "hello", "world", 15*time.Second)
+
+	// every node applies the request in the client's trace
+	for i := range c.Nodes {
+		names := make(map[string]bool)
+		for _, span := range readSpans(t, fmt.Sprintf("%s.%d", traceFile, i)) {
+			if span.SpanContext.TraceID == traceID {
+				names[span.Name] = true
+			}
+		}
+		require.True(t, names["commitLocal"], "node %d has no commitLocal span in the trace", i)
+		if i == 0 {
+			require.True(t, names["Put"])
+			require.True(t, names["mempool"])
+		}
+	}
+}
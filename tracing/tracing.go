@@ -0,0 +1,121 @@
+// Package tracing sets up OpenTelemetry tracing for a node and propagates trace context through
+// client requests and consensus messages.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/metadata"
+)
+
+const TracerName = "github.com/patrickmao1/beeftea"
+
+var propagator = propagation.TraceContext{}
+
+// ShutdownFunc flushes the spans that haven't been exported yet and stops the exporters
+type ShutdownFunc func(ctx context.Context) error
+
+// NewProvider creates the tracer provider of node nodeIdx. Spans are exported to the OTLP
+// collector at otlpEndpoint (host:port) and/or appended as JSON lines to file. If neither is set,
+// tracing is disabled.
+func NewProvider(nodeIdx uint32, otlpEndpoint, file string) (trace.TracerProvider, ShutdownFunc, error) {
+	if otlpEndpoint == "" && file == "" {
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	}
+	res := resource.NewSchemaless(
+		semconv.ServiceName("beeftea"),
+		semconv.ServiceInstanceID(fmt.Sprint(nodeIdx)),
+		attribute.Int("beeftea.node_index", int(nodeIdx)),
+	)
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	var closers []func() error
+
+	if otlpEndpoint != "" {
+		exporter, err := otlptracegrpc.New(context.Background(),
+			otlptracegrpc.WithEndpoint(otlpEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %s", err.Error())
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	if file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %s", err.Error())
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create file exporter: %s", err.Error())
+		}
+		// export synchronously so that the file is always up to date
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+		closers = append(closers, f.Close)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	shutdown := func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		for _, c := range closers {
+			err = errors.Join(err, c())
+		}
+		return err
+	}
+	return tp, shutdown, nil
+}
+
+// Inject returns the trace context of the span in ctx, to be carried in a message
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns a context whose remote parent span is the one described by traceContext
+func Extract(ctx context.Context, traceContext map[string]string) context.Context {
+	if len(traceContext) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(traceContext))
+}
+
+// ExtractGRPC returns a context whose remote parent span is the one sent by the gRPC client in
+// the request metadata, if any
+func ExtractGRPC(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	carrier := propagation.MapCarrier{}
+	for _, key := range propagator.Fields() {
+		if vals := md.Get(key); len(vals) > 0 {
+			carrier[key] = vals[0]
+		}
+	}
+	return Extract(ctx, carrier)
+}
+
+// Link links to the span described by traceContext, e.g. a request batched into a proposal
+func Link(traceContext map[string]string) (trace.Link, bool) {
+	sc := trace.SpanContextFromContext(Extract(context.Background(), traceContext))
+	if !sc.IsValid() {
+		return trace.Link{}, false
+	}
+	return trace.Link{SpanContext: sc}, true
+}
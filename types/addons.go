@@ -1,15 +1,44 @@
 package types
 
 import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+
 	"github.com/patrickmao1/beeftea/crypto"
 	"github.com/patrickmao1/beeftea/utils"
-	"math"
 )
 
+// DigestLen is the length of the digests produced by the Hash methods
+const DigestLen = 32
+
 func (e *Envelope) Hash() []byte {
 	return utils.MustHash(e)
 }
 
+// TypeName returns the name of the message type, e.g. "Prepare"
+func (m *Message) TypeName() string {
+	switch m.Type.(type) {
+	case *Message_Proposal:
+		return "Proposal"
+	case *Message_Prepare:
+		return "Prepare"
+	case *Message_Commit:
+		return "Commit"
+	case *Message_Equivocation:
+		return "Equivocation"
+	case *Message_Timeout:
+		return "Timeout"
+	case *Message_BeaconShare:
+		return "BeaconShare"
+	case *Message_QuorumCertificate:
+		return "QuorumCertificate"
+	default:
+		return "Unknown"
+	}
+}
+
 func (p *Proposal) Score() uint32 {
 	if p == nil {
 		return math.MaxUint32
@@ -20,3 +49,93 @@ func (p *Proposal) Score() uint32 {
 func (p *Proposal) Hash() []byte {
 	return utils.MustHash(p)
 }
+
+// VoteMsg returns what a node signs with its BLS key to vote for digest in a phase of round on the
+// network of chainHash
+func VoteMsg(chainHash []byte, phase Phase, round uint32, digest []byte) []byte {
+	bs := appendBytes([]byte("beeftea vote"), chainHash)
+	return slices.Concat(bs, []byte{byte(phase)}, binary.BigEndian.AppendUint32(nil, round), digest)
+}
+
+// SignedMsg returns the vote the signers of the certificate signed on the network of chainHash
+func (qc *QuorumCertificate) SignedMsg(chainHash []byte) []byte {
+	return VoteMsg(chainHash, qc.Phase, qc.Round, qc.ProposalDigest)
+}
+
+// Verify checks that the signers of the certificate are a quorum and that their aggregate
+// signature verifies on the network of chainHash. keys are the BLS public keys of the peers by
+// index.
+func (qc *QuorumCertificate) Verify(chainHash []byte, keys []*crypto.BLSPublicKey, isQuorum func(signers []uint32) bool) error {
+	signers := crypto.Bitmap(qc.Signers).Indices()
+	if len(signers) > 0 && int(signers[len(signers)-1]) >= len(keys) {
+		return fmt.Errorf("unknown signer %d", signers[len(signers)-1])
+	}
+	if !isQuorum(signers) {
+		return fmt.Errorf("signers %v are not a quorum", signers)
+	}
+	var signerKeys []*crypto.BLSPublicKey
+	for _, signer := range signers {
+		if keys[signer] == nil {
+			return fmt.Errorf("signer %d has no BLS key", signer)
+		}
+		signerKeys = append(signerKeys, keys[signer])
+	}
+	if !crypto.VerifyAggregateBLS(signerKeys, qc.SignedMsg(chainHash), qc.AggSig) {
+		return fmt.Errorf("aggregate signature doesn't verify")
+	}
+	return nil
+}
+
+// SignedBytes returns what the operator signs to authorize the membership change on the network
+// of chainHash
+func (rc *Reconfig) SignedBytes(chainHash []byte) []byte {
+	bs := []byte("beeftea reconfig")
+	bs = appendBytes(bs, chainHash)
+	bs = binary.BigEndian.AppendUint32(bs, uint32(rc.Op))
+	bs = binary.BigEndian.AppendUint32(bs, rc.Index)
+	bs = appendBytes(bs, []byte(rc.Url))
+	bs = appendBytes(bs, rc.PublicKey)
+	bs = binary.BigEndian.AppendUint64(bs, rc.Weight)
+	if rc.Learner {
+		bs = append(bs, 1)
+	} else {
+		bs = append(bs, 0)
+	}
+	return binary.BigEndian.AppendUint32(bs, rc.Seq)
+}
+
+// Sign sets the operator signature of the membership change
+func (rc *Reconfig) Sign(operator crypto.PrivateKey, chainHash []byte) {
+	rc.OperatorSig = crypto.Sign(operator, rc.SignedBytes(chainHash))
+}
+
+// VerifyOperator checks that the operator signed the membership change, nil operator keys
+// authorize no change
+func (rc *Reconfig) VerifyOperator(operator crypto.PublicKey, chainHash []byte) error {
+	if operator == nil {
+		return fmt.Errorf("membership changes need an operator key in the genesis")
+	}
+	if !crypto.Verify(operator, rc.SignedBytes(chainHash), rc.OperatorSig) {
+		return fmt.Errorf("membership change isn't signed by the operator")
+	}
+	return nil
+}
+
+// SignedBytes returns what the node that joins or catches up with the network of chainHash signs
+// to fetch a snapshot
+func (r *GetSnapshotReq) SignedBytes(chainHash []byte) []byte {
+	bs := []byte("beeftea snapshot")
+	bs = appendBytes(bs, chainHash)
+	bs = appendBytes(bs, r.PublicKey)
+	bs = binary.BigEndian.AppendUint64(bs, uint64(r.TimeMs))
+	if r.Now {
+		return append(bs, 1)
+	}
+	return append(bs, 0)
+}
+
+// appendBytes appends b with its length, so that concatenated fields can't be shifted into
+// one another
+func appendBytes(bs, b []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(bs, uint32(len(b))), b...)
+}
@@ -20,6 +20,148 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ReconfigOp int32
+
+const (
+	ReconfigOp_RECONFIG_OP_ADD     ReconfigOp = 0
+	ReconfigOp_RECONFIG_OP_REMOVE  ReconfigOp = 1
+	ReconfigOp_RECONFIG_OP_REPLACE ReconfigOp = 2
+)
+
+// Enum value maps for ReconfigOp.
+var (
+	ReconfigOp_name = map[int32]string{
+		0: "RECONFIG_OP_ADD",
+		1: "RECONFIG_OP_REMOVE",
+		2: "RECONFIG_OP_REPLACE",
+	}
+	ReconfigOp_value = map[string]int32{
+		"RECONFIG_OP_ADD":     0,
+		"RECONFIG_OP_REMOVE":  1,
+		"RECONFIG_OP_REPLACE": 2,
+	}
+)
+
+func (x ReconfigOp) Enum() *ReconfigOp {
+	p := new(ReconfigOp)
+	*p = x
+	return p
+}
+
+func (x ReconfigOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReconfigOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_beeftea_proto_enumTypes[0].Descriptor()
+}
+
+func (ReconfigOp) Type() protoreflect.EnumType {
+	return &file_beeftea_proto_enumTypes[0]
+}
+
+func (x ReconfigOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReconfigOp.Descriptor instead.
+func (ReconfigOp) EnumDescriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{0}
+}
+
+// values match crypto.Algorithm
+type KeyAlgorithm int32
+
+const (
+	KeyAlgorithm_KEY_ALGORITHM_P256    KeyAlgorithm = 0
+	KeyAlgorithm_KEY_ALGORITHM_ED25519 KeyAlgorithm = 1
+)
+
+// Enum value maps for KeyAlgorithm.
+var (
+	KeyAlgorithm_name = map[int32]string{
+		0: "KEY_ALGORITHM_P256",
+		1: "KEY_ALGORITHM_ED25519",
+	}
+	KeyAlgorithm_value = map[string]int32{
+		"KEY_ALGORITHM_P256":    0,
+		"KEY_ALGORITHM_ED25519": 1,
+	}
+)
+
+func (x KeyAlgorithm) Enum() *KeyAlgorithm {
+	p := new(KeyAlgorithm)
+	*p = x
+	return p
+}
+
+func (x KeyAlgorithm) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KeyAlgorithm) Descriptor() protoreflect.EnumDescriptor {
+	return file_beeftea_proto_enumTypes[1].Descriptor()
+}
+
+func (KeyAlgorithm) Type() protoreflect.EnumType {
+	return &file_beeftea_proto_enumTypes[1]
+}
+
+func (x KeyAlgorithm) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use KeyAlgorithm.Descriptor instead.
+func (KeyAlgorithm) EnumDescriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{1}
+}
+
+type Phase int32
+
+const (
+	Phase_PHASE_PREPARE Phase = 0
+	Phase_PHASE_COMMIT  Phase = 1
+)
+
+// Enum value maps for Phase.
+var (
+	Phase_name = map[int32]string{
+		0: "PHASE_PREPARE",
+		1: "PHASE_COMMIT",
+	}
+	Phase_value = map[string]int32{
+		"PHASE_PREPARE": 0,
+		"PHASE_COMMIT":  1,
+	}
+)
+
+func (x Phase) Enum() *Phase {
+	p := new(Phase)
+	*p = x
+	return p
+}
+
+func (x Phase) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Phase) Descriptor() protoreflect.EnumDescriptor {
+	return file_beeftea_proto_enumTypes[2].Descriptor()
+}
+
+func (Phase) Type() protoreflect.EnumType {
+	return &file_beeftea_proto_enumTypes[2]
+}
+
+func (x Phase) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Phase.Descriptor instead.
+func (Phase) EnumDescriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{2}
+}
+
 type PutReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -27,6 +169,12 @@ type PutReq struct {
 
 	Id string    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Kv *KeyValue `protobuf:"bytes,2,opt,name=kv,proto3" json:"kv,omitempty"`
+	// W3C trace context of the span that submitted the request
+	TraceContext map[string]string `protobuf:"bytes,3,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// a membership change instead of a write, submitted with AdminRPC.Reconfigure
+	Reconfig *Reconfig `protobuf:"bytes,4,opt,name=reconfig,proto3" json:"reconfig,omitempty"`
+	// remove kv.key instead of writing it, submitted with ExternalRPC.Delete
+	Delete bool `protobuf:"varint,5,opt,name=delete,proto3" json:"delete,omitempty"`
 }
 
 func (x *PutReq) Reset() {
@@ -73,6 +221,137 @@ func (x *PutReq) GetKv() *KeyValue {
 	return nil
 }
 
+func (x *PutReq) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
+func (x *PutReq) GetReconfig() *Reconfig {
+	if x != nil {
+		return x.Reconfig
+	}
+	return nil
+}
+
+func (x *PutReq) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+// Reconfig adds a peer in a new slot, removes the peer of a slot, or replaces it with another
+// machine. Slots are never reused, so a removed peer keeps its index with no weight.
+type Reconfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Op ReconfigOp `protobuf:"varint,1,opt,name=op,proto3,enum=beeftea.ReconfigOp" json:"op,omitempty"`
+	// slot to remove or replace
+	Index uint32 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// consensus address, crypto.MarshalPublic key and weight of the new peer, 0 counts as 1
+	Url       string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	PublicKey []byte `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Weight    uint64 `protobuf:"varint,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	// the new peer is a learner, see types.Peer.Learner
+	Learner bool `protobuf:"varint,6,opt,name=learner,proto3" json:"learner,omitempty"`
+	// number of membership changes committed before this one, so that a signed change can't be
+	// committed twice
+	Seq uint32 `protobuf:"varint,7,opt,name=seq,proto3" json:"seq,omitempty"`
+	// signature of the operator key of the genesis over the other fields (see
+	// types.Reconfig.SignedBytes)
+	OperatorSig []byte `protobuf:"bytes,8,opt,name=operator_sig,json=operatorSig,proto3" json:"operator_sig,omitempty"`
+}
+
+func (x *Reconfig) Reset() {
+	*x = Reconfig{}
+	mi := &file_beeftea_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reconfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reconfig) ProtoMessage() {}
+
+func (x *Reconfig) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reconfig.ProtoReflect.Descriptor instead.
+func (*Reconfig) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Reconfig) GetOp() ReconfigOp {
+	if x != nil {
+		return x.Op
+	}
+	return ReconfigOp_RECONFIG_OP_ADD
+}
+
+func (x *Reconfig) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Reconfig) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Reconfig) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *Reconfig) GetWeight() uint64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Reconfig) GetLearner() bool {
+	if x != nil {
+		return x.Learner
+	}
+	return false
+}
+
+func (x *Reconfig) GetSeq() uint32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Reconfig) GetOperatorSig() []byte {
+	if x != nil {
+		return x.OperatorSig
+	}
+	return nil
+}
+
 type PutRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -83,7 +362,7 @@ type PutRes struct {
 
 func (x *PutRes) Reset() {
 	*x = PutRes{}
-	mi := &file_beeftea_proto_msgTypes[1]
+	mi := &file_beeftea_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -95,7 +374,7 @@ func (x *PutRes) String() string {
 func (*PutRes) ProtoMessage() {}
 
 func (x *PutRes) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[1]
+	mi := &file_beeftea_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -108,7 +387,7 @@ func (x *PutRes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PutRes.ProtoReflect.Descriptor instead.
 func (*PutRes) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{1}
+	return file_beeftea_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *PutRes) GetId() string {
@@ -124,11 +403,13 @@ type GetReq struct {
 	unknownFields protoimpl.UnknownFields
 
 	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// return the proof that the value was committed, see GetRes
+	Prove bool `protobuf:"varint,2,opt,name=prove,proto3" json:"prove,omitempty"`
 }
 
 func (x *GetReq) Reset() {
 	*x = GetReq{}
-	mi := &file_beeftea_proto_msgTypes[2]
+	mi := &file_beeftea_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -140,7 +421,7 @@ func (x *GetReq) String() string {
 func (*GetReq) ProtoMessage() {}
 
 func (x *GetReq) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[2]
+	mi := &file_beeftea_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -153,7 +434,7 @@ func (x *GetReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetReq.ProtoReflect.Descriptor instead.
 func (*GetReq) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{2}
+	return file_beeftea_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *GetReq) GetKey() string {
@@ -163,49 +444,1468 @@ func (x *GetReq) GetKey() string {
 	return ""
 }
 
+func (x *GetReq) GetProve() bool {
+	if x != nil {
+		return x.Prove
+	}
+	return false
+}
+
 type GetRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Kv *KeyValue `protobuf:"bytes,1,opt,name=kv,proto3" json:"kv,omitempty"`
+	// With prove, the round the key was last written or deleted in, the proposal applied in that
+	// round and the certificate of its commit quorum. Unset if the node can't prove the value: the
+	// nodes don't have BLS keys, the key was never written since the node started, or the node
+	// missed the certificate.
+	Round    uint32             `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Proposal *Proposal          `protobuf:"bytes,3,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	CommitQc *QuorumCertificate `protobuf:"bytes,4,opt,name=commit_qc,json=commitQc,proto3" json:"commit_qc,omitempty"`
 }
 
 func (x *GetRes) Reset() {
 	*x = GetRes{}
-	mi := &file_beeftea_proto_msgTypes[3]
+	mi := &file_beeftea_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRes) ProtoMessage() {}
+
+func (x *GetRes) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRes.ProtoReflect.Descriptor instead.
+func (*GetRes) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetRes) GetKv() *KeyValue {
+	if x != nil {
+		return x.Kv
+	}
+	return nil
+}
+
+func (x *GetRes) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *GetRes) GetProposal() *Proposal {
+	if x != nil {
+		return x.Proposal
+	}
+	return nil
+}
+
+func (x *GetRes) GetCommitQc() *QuorumCertificate {
+	if x != nil {
+		return x.CommitQc
+	}
+	return nil
+}
+
+type WatchReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// all keys if empty
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+}
+
+func (x *WatchReq) Reset() {
+	*x = WatchReq{}
+	mi := &file_beeftea_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchReq) ProtoMessage() {}
+
+func (x *WatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchReq.ProtoReflect.Descriptor instead.
+func (*WatchReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchReq) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type WatchEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kv *KeyValue `protobuf:"bytes,1,opt,name=kv,proto3" json:"kv,omitempty"`
+	// the round the write was committed in
+	Round uint32 `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	// the key was deleted, kv.val is empty
+	Deleted bool `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_beeftea_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetRes) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchEvent) GetKv() *KeyValue {
+	if x != nil {
+		return x.Kv
+	}
+	return nil
+}
+
+func (x *WatchEvent) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *WatchEvent) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type DeleteReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id  string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Key string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *DeleteReq) Reset() {
+	*x = DeleteReq{}
+	mi := &file_beeftea_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReq) ProtoMessage() {}
+
+func (x *DeleteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReq.ProtoReflect.Descriptor instead.
+func (*DeleteReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteReq) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type ScanReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// all keys if empty
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// return the first limit keys only, all of them if 0
+	Limit uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *ScanReq) Reset() {
+	*x = ScanReq{}
+	mi := &file_beeftea_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanReq) ProtoMessage() {}
+
+func (x *ScanReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanReq.ProtoReflect.Descriptor instead.
+func (*ScanReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScanReq) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ScanReq) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ScanRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kvs []*KeyValue `protobuf:"bytes,1,rep,name=kvs,proto3" json:"kvs,omitempty"`
+	// there are more keys than limit
+	Truncated bool `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"`
+}
+
+func (x *ScanRes) Reset() {
+	*x = ScanRes{}
+	mi := &file_beeftea_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRes) ProtoMessage() {}
+
+func (x *ScanRes) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRes.ProtoReflect.Descriptor instead.
+func (*ScanRes) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ScanRes) GetKvs() []*KeyValue {
+	if x != nil {
+		return x.Kvs
+	}
+	return nil
+}
+
+func (x *ScanRes) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type ListEvidenceReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// only return evidence against this node if set
+	NodeIndex *uint32 `protobuf:"varint,1,opt,name=node_index,json=nodeIndex,proto3,oneof" json:"node_index,omitempty"`
+}
+
+func (x *ListEvidenceReq) Reset() {
+	*x = ListEvidenceReq{}
+	mi := &file_beeftea_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEvidenceReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEvidenceReq) ProtoMessage() {}
+
+func (x *ListEvidenceReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEvidenceReq.ProtoReflect.Descriptor instead.
+func (*ListEvidenceReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListEvidenceReq) GetNodeIndex() uint32 {
+	if x != nil && x.NodeIndex != nil {
+		return *x.NodeIndex
+	}
+	return 0
+}
+
+type ListEvidenceRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Proofs []*EquivocationProof `protobuf:"bytes,1,rep,name=proofs,proto3" json:"proofs,omitempty"`
+}
+
+func (x *ListEvidenceRes) Reset() {
+	*x = ListEvidenceRes{}
+	mi := &file_beeftea_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEvidenceRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEvidenceRes) ProtoMessage() {}
+
+func (x *ListEvidenceRes) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEvidenceRes.ProtoReflect.Descriptor instead.
+func (*ListEvidenceRes) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListEvidenceRes) GetProofs() []*EquivocationProof {
+	if x != nil {
+		return x.Proofs
+	}
+	return nil
+}
+
+type GetBeaconReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the latest round the node has a beacon value for if unset
+	Round *uint32 `protobuf:"varint,1,opt,name=round,proto3,oneof" json:"round,omitempty"`
+}
+
+func (x *GetBeaconReq) Reset() {
+	*x = GetBeaconReq{}
+	mi := &file_beeftea_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBeaconReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBeaconReq) ProtoMessage() {}
+
+func (x *GetBeaconReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBeaconReq.ProtoReflect.Descriptor instead.
+func (*GetBeaconReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetBeaconReq) GetRound() uint32 {
+	if x != nil && x.Round != nil {
+		return *x.Round
+	}
+	return 0
+}
+
+type GetBeaconRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round uint32 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	// threshold BLS signature of the round, verifiable against the group key (see beacon.Verify)
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// hash of the signature, the random bytes of the round
+	Randomness []byte `protobuf:"bytes,3,opt,name=randomness,proto3" json:"randomness,omitempty"`
+}
+
+func (x *GetBeaconRes) Reset() {
+	*x = GetBeaconRes{}
+	mi := &file_beeftea_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBeaconRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBeaconRes) ProtoMessage() {}
+
+func (x *GetBeaconRes) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBeaconRes.ProtoReflect.Descriptor instead.
+func (*GetBeaconRes) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetBeaconRes) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *GetBeaconRes) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *GetBeaconRes) GetRandomness() []byte {
+	if x != nil {
+		return x.Randomness
+	}
+	return nil
+}
+
+type ReconfigureReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reconfig *Reconfig `protobuf:"bytes,2,opt,name=reconfig,proto3" json:"reconfig,omitempty"`
+}
+
+func (x *ReconfigureReq) Reset() {
+	*x = ReconfigureReq{}
+	mi := &file_beeftea_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconfigureReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconfigureReq) ProtoMessage() {}
+
+func (x *ReconfigureReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconfigureReq.ProtoReflect.Descriptor instead.
+func (*ReconfigureReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReconfigureReq) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReconfigureReq) GetReconfig() *Reconfig {
+	if x != nil {
+		return x.Reconfig
+	}
+	return nil
+}
+
+// ScheduledReconfig is a committed membership change and the round it takes effect in
+type ScheduledReconfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round    uint32    `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Reconfig *Reconfig `protobuf:"bytes,2,opt,name=reconfig,proto3" json:"reconfig,omitempty"`
+}
+
+func (x *ScheduledReconfig) Reset() {
+	*x = ScheduledReconfig{}
+	mi := &file_beeftea_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledReconfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledReconfig) ProtoMessage() {}
+
+func (x *ScheduledReconfig) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledReconfig.ProtoReflect.Descriptor instead.
+func (*ScheduledReconfig) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ScheduledReconfig) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *ScheduledReconfig) GetReconfig() *Reconfig {
+	if x != nil {
+		return x.Reconfig
+	}
+	return nil
+}
+
+// GetSnapshotReq is signed by the key of the node that joins or catches up, which must be a peer
+// or be added by a pending membership change
+type GetSnapshotReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// crypto.MarshalPublic key of the node
+	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	// unix milliseconds of the request, which is only accepted for a minute
+	TimeMs int64 `protobuf:"varint,2,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"`
+	// signature over the other fields, see types.GetSnapshotReq.SignedBytes
+	Sig []byte `protobuf:"bytes,3,opt,name=sig,proto3" json:"sig,omitempty"`
+	// take the snapshot right away, at the start of the current round if it isn't applied yet,
+	// instead of once a round is applied
+	Now bool `protobuf:"varint,4,opt,name=now,proto3" json:"now,omitempty"`
+}
+
+func (x *GetSnapshotReq) Reset() {
+	*x = GetSnapshotReq{}
+	mi := &file_beeftea_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnapshotReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotReq) ProtoMessage() {}
+
+func (x *GetSnapshotReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotReq.ProtoReflect.Descriptor instead.
+func (*GetSnapshotReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetSnapshotReq) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *GetSnapshotReq) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *GetSnapshotReq) GetSig() []byte {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *GetSnapshotReq) GetNow() bool {
+	if x != nil {
+		return x.Now
+	}
+	return false
+}
+
+// Snapshot is the state of a node at the start of a round
+type Snapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round uint32 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	// proof of the last applied proposal, which seeds the round
+	PrevProposerProof []byte               `protobuf:"bytes,2,opt,name=prev_proposer_proof,json=prevProposerProof,proto3" json:"prev_proposer_proof,omitempty"`
+	Db                map[string]string    `protobuf:"bytes,3,rep,name=db,proto3" json:"db,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Peers             []*PeerInfo          `protobuf:"bytes,4,rep,name=peers,proto3" json:"peers,omitempty"`
+	PendingReconfigs  []*ScheduledReconfig `protobuf:"bytes,5,rep,name=pending_reconfigs,json=pendingReconfigs,proto3" json:"pending_reconfigs,omitempty"`
+	ChainHash         []byte               `protobuf:"bytes,6,opt,name=chain_hash,json=chainHash,proto3" json:"chain_hash,omitempty"`
+	// number of membership changes committed so far, see Reconfig.seq
+	ReconfigSeq uint32 `protobuf:"varint,7,opt,name=reconfig_seq,json=reconfigSeq,proto3" json:"reconfig_seq,omitempty"`
+	// ids of the requests committed in the last rounds -> round, which aren't applied again
+	CommittedIds map[string]uint32 `protobuf:"bytes,8,rep,name=committed_ids,json=committedIds,proto3" json:"committed_ids,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_beeftea_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Snapshot) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Snapshot) GetPrevProposerProof() []byte {
+	if x != nil {
+		return x.PrevProposerProof
+	}
+	return nil
+}
+
+func (x *Snapshot) GetDb() map[string]string {
+	if x != nil {
+		return x.Db
+	}
+	return nil
+}
+
+func (x *Snapshot) GetPeers() []*PeerInfo {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *Snapshot) GetPendingReconfigs() []*ScheduledReconfig {
+	if x != nil {
+		return x.PendingReconfigs
+	}
+	return nil
+}
+
+func (x *Snapshot) GetChainHash() []byte {
+	if x != nil {
+		return x.ChainHash
+	}
+	return nil
+}
+
+func (x *Snapshot) GetReconfigSeq() uint32 {
+	if x != nil {
+		return x.ReconfigSeq
+	}
+	return 0
+}
+
+func (x *Snapshot) GetCommittedIds() map[string]uint32 {
+	if x != nil {
+		return x.CommittedIds
+	}
+	return nil
+}
+
+type PeerInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// crypto.MarshalPublic of the key
+	PublicKey []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Weight    uint64 `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	Removed   bool   `protobuf:"varint,4,opt,name=removed,proto3" json:"removed,omitempty"`
+	Learner   bool   `protobuf:"varint,5,opt,name=learner,proto3" json:"learner,omitempty"`
+}
+
+func (x *PeerInfo) Reset() {
+	*x = PeerInfo{}
+	mi := &file_beeftea_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfo) ProtoMessage() {}
+
+func (x *PeerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfo.ProtoReflect.Descriptor instead.
+func (*PeerInfo) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PeerInfo) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PeerInfo) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *PeerInfo) GetWeight() uint64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *PeerInfo) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
+
+func (x *PeerInfo) GetLearner() bool {
+	if x != nil {
+		return x.Learner
+	}
+	return false
+}
+
+type SetBehaviorReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// comma separated list of faults to inject (see fault.Parse), empty means honest
+	Spec string `protobuf:"bytes,1,opt,name=spec,proto3" json:"spec,omitempty"`
+}
+
+func (x *SetBehaviorReq) Reset() {
+	*x = SetBehaviorReq{}
+	mi := &file_beeftea_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBehaviorReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBehaviorReq) ProtoMessage() {}
+
+func (x *SetBehaviorReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBehaviorReq.ProtoReflect.Descriptor instead.
+func (*SetBehaviorReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetBehaviorReq) GetSpec() string {
+	if x != nil {
+		return x.Spec
+	}
+	return ""
+}
+
+type StatusReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StatusReq) Reset() {
+	*x = StatusReq{}
+	mi := &file_beeftea_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusReq) ProtoMessage() {}
+
+func (x *StatusReq) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusReq.ProtoReflect.Descriptor instead.
+func (*StatusReq) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{20}
+}
+
+type StatusRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeIndex uint32 `protobuf:"varint,1,opt,name=node_index,json=nodeIndex,proto3" json:"node_index,omitempty"`
+	Round     uint32 `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Seed      []byte `protobuf:"bytes,3,opt,name=seed,proto3" json:"seed,omitempty"`
+	// the lowest scoring proposal seen this round, unset if there is none
+	MinProposal  *ProposalSummary `protobuf:"bytes,4,opt,name=min_proposal,json=minProposal,proto3" json:"min_proposal,omitempty"`
+	NumProposals uint32           `protobuf:"varint,5,opt,name=num_proposals,json=numProposals,proto3" json:"num_proposals,omitempty"`
+	Prepared     bool             `protobuf:"varint,6,opt,name=prepared,proto3" json:"prepared,omitempty"`
+	Committed    bool             `protobuf:"varint,7,opt,name=committed,proto3" json:"committed,omitempty"`
+	Prepares     []*VoteTally     `protobuf:"bytes,8,rep,name=prepares,proto3" json:"prepares,omitempty"`
+	Commits      []*VoteTally     `protobuf:"bytes,9,rep,name=commits,proto3" json:"commits,omitempty"`
+	// number of client requests waiting to be committed
+	MempoolSize  uint32             `protobuf:"varint,10,opt,name=mempool_size,json=mempoolSize,proto3" json:"mempool_size,omitempty"`
+	FutureBuffer *FutureBufferStats `protobuf:"bytes,11,opt,name=future_buffer,json=futureBuffer,proto3" json:"future_buffer,omitempty"`
+	Peers        []*PeerStatus      `protobuf:"bytes,12,rep,name=peers,proto3" json:"peers,omitempty"`
+	// unset if the node hasn't committed anything since it started
+	LastCommittedRound *uint32 `protobuf:"varint,13,opt,name=last_committed_round,json=lastCommittedRound,proto3,oneof" json:"last_committed_round,omitempty"`
+	// certificate of the last commit quorum I saw, unset if none or the nodes don't have BLS keys
+	LastCommitQc *QuorumCertificate `protobuf:"bytes,14,opt,name=last_commit_qc,json=lastCommitQc,proto3" json:"last_commit_qc,omitempty"`
+	// network the node runs, empty if it has no genesis
+	ChainId   string `protobuf:"bytes,15,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ChainHash []byte `protobuf:"bytes,16,opt,name=chain_hash,json=chainHash,proto3" json:"chain_hash,omitempty"`
+	// committed membership changes that haven't taken effect yet
+	PendingReconfigs []*ScheduledReconfig `protobuf:"bytes,17,rep,name=pending_reconfigs,json=pendingReconfigs,proto3" json:"pending_reconfigs,omitempty"`
+	// seq of the next membership change, see Reconfig.seq
+	ReconfigSeq uint32 `protobuf:"varint,18,opt,name=reconfig_seq,json=reconfigSeq,proto3" json:"reconfig_seq,omitempty"`
+}
+
+func (x *StatusRes) Reset() {
+	*x = StatusRes{}
+	mi := &file_beeftea_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRes) ProtoMessage() {}
+
+func (x *StatusRes) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRes.ProtoReflect.Descriptor instead.
+func (*StatusRes) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StatusRes) GetNodeIndex() uint32 {
+	if x != nil {
+		return x.NodeIndex
+	}
+	return 0
+}
+
+func (x *StatusRes) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *StatusRes) GetSeed() []byte {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+func (x *StatusRes) GetMinProposal() *ProposalSummary {
+	if x != nil {
+		return x.MinProposal
+	}
+	return nil
+}
+
+func (x *StatusRes) GetNumProposals() uint32 {
+	if x != nil {
+		return x.NumProposals
+	}
+	return 0
+}
+
+func (x *StatusRes) GetPrepared() bool {
+	if x != nil {
+		return x.Prepared
+	}
+	return false
+}
+
+func (x *StatusRes) GetCommitted() bool {
+	if x != nil {
+		return x.Committed
+	}
+	return false
+}
+
+func (x *StatusRes) GetPrepares() []*VoteTally {
+	if x != nil {
+		return x.Prepares
+	}
+	return nil
+}
+
+func (x *StatusRes) GetCommits() []*VoteTally {
+	if x != nil {
+		return x.Commits
+	}
+	return nil
+}
+
+func (x *StatusRes) GetMempoolSize() uint32 {
+	if x != nil {
+		return x.MempoolSize
+	}
+	return 0
+}
+
+func (x *StatusRes) GetFutureBuffer() *FutureBufferStats {
+	if x != nil {
+		return x.FutureBuffer
+	}
+	return nil
+}
+
+func (x *StatusRes) GetPeers() []*PeerStatus {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+func (x *StatusRes) GetLastCommittedRound() uint32 {
+	if x != nil && x.LastCommittedRound != nil {
+		return *x.LastCommittedRound
+	}
+	return 0
+}
+
+func (x *StatusRes) GetLastCommitQc() *QuorumCertificate {
+	if x != nil {
+		return x.LastCommitQc
+	}
+	return nil
+}
+
+func (x *StatusRes) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *StatusRes) GetChainHash() []byte {
+	if x != nil {
+		return x.ChainHash
+	}
+	return nil
+}
+
+func (x *StatusRes) GetPendingReconfigs() []*ScheduledReconfig {
+	if x != nil {
+		return x.PendingReconfigs
+	}
+	return nil
+}
+
+func (x *StatusRes) GetReconfigSeq() uint32 {
+	if x != nil {
+		return x.ReconfigSeq
+	}
+	return 0
+}
+
+type ProposalSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digest        []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	ProposerIndex uint32 `protobuf:"varint,2,opt,name=proposer_index,json=proposerIndex,proto3" json:"proposer_index,omitempty"`
+	Score         uint32 `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	NumReqs       uint32 `protobuf:"varint,4,opt,name=num_reqs,json=numReqs,proto3" json:"num_reqs,omitempty"`
+}
+
+func (x *ProposalSummary) Reset() {
+	*x = ProposalSummary{}
+	mi := &file_beeftea_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProposalSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProposalSummary) ProtoMessage() {}
+
+func (x *ProposalSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProposalSummary.ProtoReflect.Descriptor instead.
+func (*ProposalSummary) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ProposalSummary) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *ProposalSummary) GetProposerIndex() uint32 {
+	if x != nil {
+		return x.ProposerIndex
+	}
+	return 0
+}
+
+func (x *ProposalSummary) GetScore() uint32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ProposalSummary) GetNumReqs() uint32 {
+	if x != nil {
+		return x.NumReqs
+	}
+	return 0
+}
+
+type VoteTally struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digest []byte   `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+	Voters []uint32 `protobuf:"varint,2,rep,packed,name=voters,proto3" json:"voters,omitempty"`
+}
+
+func (x *VoteTally) Reset() {
+	*x = VoteTally{}
+	mi := &file_beeftea_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoteTally) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoteTally) ProtoMessage() {}
+
+func (x *VoteTally) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoteTally.ProtoReflect.Descriptor instead.
+func (*VoteTally) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *VoteTally) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *VoteTally) GetVoters() []uint32 {
+	if x != nil {
+		return x.Voters
+	}
+	return nil
+}
+
+type FutureBufferStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Buffered uint32 `protobuf:"varint,1,opt,name=buffered,proto3" json:"buffered,omitempty"`
+	Released uint64 `protobuf:"varint,2,opt,name=released,proto3" json:"released,omitempty"`
+	Dropped  uint64 `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Expired  uint64 `protobuf:"varint,4,opt,name=expired,proto3" json:"expired,omitempty"`
+}
+
+func (x *FutureBufferStats) Reset() {
+	*x = FutureBufferStats{}
+	mi := &file_beeftea_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FutureBufferStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FutureBufferStats) ProtoMessage() {}
+
+func (x *FutureBufferStats) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FutureBufferStats.ProtoReflect.Descriptor instead.
+func (*FutureBufferStats) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *FutureBufferStats) GetBuffered() uint32 {
+	if x != nil {
+		return x.Buffered
+	}
+	return 0
+}
+
+func (x *FutureBufferStats) GetReleased() uint64 {
+	if x != nil {
+		return x.Released
+	}
+	return 0
+}
+
+func (x *FutureBufferStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *FutureBufferStats) GetExpired() uint64 {
+	if x != nil {
+		return x.Expired
+	}
+	return 0
+}
+
+type PeerStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Url   string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// gRPC connectivity state of the connection to the peer, e.g. READY or TRANSIENT_FAILURE
+	State   string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Weight  uint64 `protobuf:"varint,4,opt,name=weight,proto3" json:"weight,omitempty"`
+	Removed bool   `protobuf:"varint,5,opt,name=removed,proto3" json:"removed,omitempty"`
+	Learner bool   `protobuf:"varint,6,opt,name=learner,proto3" json:"learner,omitempty"`
+}
+
+func (x *PeerStatus) Reset() {
+	*x = PeerStatus{}
+	mi := &file_beeftea_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerStatus) ProtoMessage() {}
+
+func (x *PeerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerStatus.ProtoReflect.Descriptor instead.
+func (*PeerStatus) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PeerStatus) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PeerStatus) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PeerStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
 }
 
-func (*GetRes) ProtoMessage() {}
-
-func (x *GetRes) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[3]
+func (x *PeerStatus) GetWeight() uint64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Weight
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use GetRes.ProtoReflect.Descriptor instead.
-func (*GetRes) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{3}
+func (x *PeerStatus) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
 }
 
-func (x *GetRes) GetKv() *KeyValue {
+func (x *PeerStatus) GetLearner() bool {
 	if x != nil {
-		return x.Kv
+		return x.Learner
 	}
-	return nil
+	return false
 }
 
 type Empty struct {
@@ -216,7 +1916,7 @@ type Empty struct {
 
 func (x *Empty) Reset() {
 	*x = Empty{}
-	mi := &file_beeftea_proto_msgTypes[4]
+	mi := &file_beeftea_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -228,7 +1928,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[4]
+	mi := &file_beeftea_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -241,7 +1941,7 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{4}
+	return file_beeftea_proto_rawDescGZIP(), []int{26}
 }
 
 type Envelope struct {
@@ -252,11 +1952,15 @@ type Envelope struct {
 	Msg       *Message `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
 	NodeIndex uint32   `protobuf:"varint,2,opt,name=node_index,json=nodeIndex,proto3" json:"node_index,omitempty"`
 	Sig       []byte   `protobuf:"bytes,3,opt,name=sig,proto3" json:"sig,omitempty"`
+	// W3C trace context of the span that sent the message, not covered by sig
+	TraceContext map[string]string `protobuf:"bytes,4,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// algorithm of sig, which must be the one of the sender's key
+	SigAlg KeyAlgorithm `protobuf:"varint,5,opt,name=sig_alg,json=sigAlg,proto3,enum=beeftea.KeyAlgorithm" json:"sig_alg,omitempty"`
 }
 
 func (x *Envelope) Reset() {
 	*x = Envelope{}
-	mi := &file_beeftea_proto_msgTypes[5]
+	mi := &file_beeftea_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -268,7 +1972,7 @@ func (x *Envelope) String() string {
 func (*Envelope) ProtoMessage() {}
 
 func (x *Envelope) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[5]
+	mi := &file_beeftea_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -281,7 +1985,7 @@ func (x *Envelope) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
 func (*Envelope) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{5}
+	return file_beeftea_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *Envelope) GetMsg() *Message {
@@ -305,22 +2009,42 @@ func (x *Envelope) GetSig() []byte {
 	return nil
 }
 
+func (x *Envelope) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
+func (x *Envelope) GetSigAlg() KeyAlgorithm {
+	if x != nil {
+		return x.SigAlg
+	}
+	return KeyAlgorithm_KEY_ALGORITHM_P256
+}
+
 type Message struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// the round in which the message was sent
+	Round uint32 `protobuf:"varint,4,opt,name=round,proto3" json:"round,omitempty"`
 	// Types that are assignable to Type:
 	//
 	//	*Message_Proposal
 	//	*Message_Prepare
 	//	*Message_Commit
+	//	*Message_Equivocation
+	//	*Message_Timeout
+	//	*Message_BeaconShare
+	//	*Message_QuorumCertificate
 	Type isMessage_Type `protobuf_oneof:"type"`
 }
 
 func (x *Message) Reset() {
 	*x = Message{}
-	mi := &file_beeftea_proto_msgTypes[6]
+	mi := &file_beeftea_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -332,7 +2056,7 @@ func (x *Message) String() string {
 func (*Message) ProtoMessage() {}
 
 func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[6]
+	mi := &file_beeftea_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -345,7 +2069,14 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Message.ProtoReflect.Descriptor instead.
 func (*Message) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{6}
+	return file_beeftea_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Message) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
 }
 
 func (m *Message) GetType() isMessage_Type {
@@ -376,6 +2107,34 @@ func (x *Message) GetCommit() *Commit {
 	return nil
 }
 
+func (x *Message) GetEquivocation() *EquivocationProof {
+	if x, ok := x.GetType().(*Message_Equivocation); ok {
+		return x.Equivocation
+	}
+	return nil
+}
+
+func (x *Message) GetTimeout() *Timeout {
+	if x, ok := x.GetType().(*Message_Timeout); ok {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Message) GetBeaconShare() *BeaconShare {
+	if x, ok := x.GetType().(*Message_BeaconShare); ok {
+		return x.BeaconShare
+	}
+	return nil
+}
+
+func (x *Message) GetQuorumCertificate() *QuorumCertificate {
+	if x, ok := x.GetType().(*Message_QuorumCertificate); ok {
+		return x.QuorumCertificate
+	}
+	return nil
+}
+
 type isMessage_Type interface {
 	isMessage_Type()
 }
@@ -392,12 +2151,120 @@ type Message_Commit struct {
 	Commit *Commit `protobuf:"bytes,3,opt,name=commit,proto3,oneof"`
 }
 
+type Message_Equivocation struct {
+	Equivocation *EquivocationProof `protobuf:"bytes,5,opt,name=equivocation,proto3,oneof"`
+}
+
+type Message_Timeout struct {
+	Timeout *Timeout `protobuf:"bytes,6,opt,name=timeout,proto3,oneof"`
+}
+
+type Message_BeaconShare struct {
+	BeaconShare *BeaconShare `protobuf:"bytes,7,opt,name=beacon_share,json=beaconShare,proto3,oneof"`
+}
+
+type Message_QuorumCertificate struct {
+	QuorumCertificate *QuorumCertificate `protobuf:"bytes,8,opt,name=quorum_certificate,json=quorumCertificate,proto3,oneof"`
+}
+
 func (*Message_Proposal) isMessage_Type() {}
 
 func (*Message_Prepare) isMessage_Type() {}
 
 func (*Message_Commit) isMessage_Type() {}
 
+func (*Message_Equivocation) isMessage_Type() {}
+
+func (*Message_Timeout) isMessage_Type() {}
+
+func (*Message_BeaconShare) isMessage_Type() {}
+
+func (*Message_QuorumCertificate) isMessage_Type() {}
+
+// Timeout says that the sender's timer for the round has expired. Timeouts for a round from a
+// quorum form a timeout certificate that moves the nodes to the next round.
+type Timeout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *Timeout) Reset() {
+	*x = Timeout{}
+	mi := &file_beeftea_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Timeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timeout) ProtoMessage() {}
+
+func (x *Timeout) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timeout.ProtoReflect.Descriptor instead.
+func (*Timeout) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{29}
+}
+
+// BeaconShare is the sender's partial signature of the beacon value of the round
+type BeaconShare struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PartialSig []byte `protobuf:"bytes,1,opt,name=partial_sig,json=partialSig,proto3" json:"partial_sig,omitempty"`
+}
+
+func (x *BeaconShare) Reset() {
+	*x = BeaconShare{}
+	mi := &file_beeftea_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BeaconShare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BeaconShare) ProtoMessage() {}
+
+func (x *BeaconShare) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BeaconShare.ProtoReflect.Descriptor instead.
+func (*BeaconShare) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *BeaconShare) GetPartialSig() []byte {
+	if x != nil {
+		return x.PartialSig
+	}
+	return nil
+}
+
 type Proposal struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -406,11 +2273,18 @@ type Proposal struct {
 	Reqs          []*PutReq `protobuf:"bytes,1,rep,name=reqs,proto3" json:"reqs,omitempty"`
 	ProposerProof []byte    `protobuf:"bytes,2,opt,name=proposer_proof,json=proposerProof,proto3" json:"proposer_proof,omitempty"`
 	ProposerIndex uint32    `protobuf:"varint,3,opt,name=proposer_index,json=proposerIndex,proto3" json:"proposer_index,omitempty"`
+	// the base timeouts the proposer suggests from the latencies it observed, adopted by all
+	// nodes when the proposal is committed. 0 if the proposer doesn't adapt its timeouts.
+	ProposalTimeoutMs uint64 `protobuf:"varint,4,opt,name=proposal_timeout_ms,json=proposalTimeoutMs,proto3" json:"proposal_timeout_ms,omitempty"`
+	RoundTimeoutMs    uint64 `protobuf:"varint,5,opt,name=round_timeout_ms,json=roundTimeoutMs,proto3" json:"round_timeout_ms,omitempty"`
+	// the beacon value of the round, so that nodes that haven't recovered it yet can check the
+	// proposer proof. Empty if the nodes don't run a beacon.
+	Beacon []byte `protobuf:"bytes,6,opt,name=beacon,proto3" json:"beacon,omitempty"`
 }
 
 func (x *Proposal) Reset() {
 	*x = Proposal{}
-	mi := &file_beeftea_proto_msgTypes[7]
+	mi := &file_beeftea_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -422,7 +2296,7 @@ func (x *Proposal) String() string {
 func (*Proposal) ProtoMessage() {}
 
 func (x *Proposal) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[7]
+	mi := &file_beeftea_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -435,7 +2309,7 @@ func (x *Proposal) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Proposal.ProtoReflect.Descriptor instead.
 func (*Proposal) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{7}
+	return file_beeftea_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *Proposal) GetReqs() []*PutReq {
@@ -459,6 +2333,27 @@ func (x *Proposal) GetProposerIndex() uint32 {
 	return 0
 }
 
+func (x *Proposal) GetProposalTimeoutMs() uint64 {
+	if x != nil {
+		return x.ProposalTimeoutMs
+	}
+	return 0
+}
+
+func (x *Proposal) GetRoundTimeoutMs() uint64 {
+	if x != nil {
+		return x.RoundTimeoutMs
+	}
+	return 0
+}
+
+func (x *Proposal) GetBeacon() []byte {
+	if x != nil {
+		return x.Beacon
+	}
+	return nil
+}
+
 type Prepare struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -467,11 +2362,13 @@ type Prepare struct {
 	// the digest of the Message the proposal is in
 	// aka the digest over which Envelope.sig is signed
 	ProposalDigest []byte `protobuf:"bytes,1,opt,name=proposal_digest,json=proposalDigest,proto3" json:"proposal_digest,omitempty"`
+	// BLS signature of the vote for quorum certificates, empty if the nodes don't have BLS keys
+	BlsSig []byte `protobuf:"bytes,2,opt,name=bls_sig,json=blsSig,proto3" json:"bls_sig,omitempty"`
 }
 
 func (x *Prepare) Reset() {
 	*x = Prepare{}
-	mi := &file_beeftea_proto_msgTypes[8]
+	mi := &file_beeftea_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -483,7 +2380,7 @@ func (x *Prepare) String() string {
 func (*Prepare) ProtoMessage() {}
 
 func (x *Prepare) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[8]
+	mi := &file_beeftea_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -496,7 +2393,7 @@ func (x *Prepare) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Prepare.ProtoReflect.Descriptor instead.
 func (*Prepare) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{8}
+	return file_beeftea_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *Prepare) GetProposalDigest() []byte {
@@ -506,17 +2403,25 @@ func (x *Prepare) GetProposalDigest() []byte {
 	return nil
 }
 
+func (x *Prepare) GetBlsSig() []byte {
+	if x != nil {
+		return x.BlsSig
+	}
+	return nil
+}
+
 type Commit struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	ProposalDigest []byte `protobuf:"bytes,1,opt,name=proposal_digest,json=proposalDigest,proto3" json:"proposal_digest,omitempty"`
+	BlsSig         []byte `protobuf:"bytes,2,opt,name=bls_sig,json=blsSig,proto3" json:"bls_sig,omitempty"`
 }
 
 func (x *Commit) Reset() {
 	*x = Commit{}
-	mi := &file_beeftea_proto_msgTypes[9]
+	mi := &file_beeftea_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -528,7 +2433,7 @@ func (x *Commit) String() string {
 func (*Commit) ProtoMessage() {}
 
 func (x *Commit) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[9]
+	mi := &file_beeftea_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -541,7 +2446,7 @@ func (x *Commit) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Commit.ProtoReflect.Descriptor instead.
 func (*Commit) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{9}
+	return file_beeftea_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *Commit) GetProposalDigest() []byte {
@@ -551,6 +2456,147 @@ func (x *Commit) GetProposalDigest() []byte {
 	return nil
 }
 
+func (x *Commit) GetBlsSig() []byte {
+	if x != nil {
+		return x.BlsSig
+	}
+	return nil
+}
+
+// QuorumCertificate proves that a quorum voted for a proposal in a phase of a round. It aggregates
+// the BLS signatures of their votes, so it is verified with a single pairing check.
+type QuorumCertificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round          uint32 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Phase          Phase  `protobuf:"varint,2,opt,name=phase,proto3,enum=beeftea.Phase" json:"phase,omitempty"`
+	ProposalDigest []byte `protobuf:"bytes,3,opt,name=proposal_digest,json=proposalDigest,proto3" json:"proposal_digest,omitempty"`
+	// the nodes whose signatures are aggregated, see crypto.Bitmap
+	Signers []byte `protobuf:"bytes,4,opt,name=signers,proto3" json:"signers,omitempty"`
+	AggSig  []byte `protobuf:"bytes,5,opt,name=agg_sig,json=aggSig,proto3" json:"agg_sig,omitempty"`
+}
+
+func (x *QuorumCertificate) Reset() {
+	*x = QuorumCertificate{}
+	mi := &file_beeftea_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuorumCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuorumCertificate) ProtoMessage() {}
+
+func (x *QuorumCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuorumCertificate.ProtoReflect.Descriptor instead.
+func (*QuorumCertificate) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *QuorumCertificate) GetRound() uint32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *QuorumCertificate) GetPhase() Phase {
+	if x != nil {
+		return x.Phase
+	}
+	return Phase_PHASE_PREPARE
+}
+
+func (x *QuorumCertificate) GetProposalDigest() []byte {
+	if x != nil {
+		return x.ProposalDigest
+	}
+	return nil
+}
+
+func (x *QuorumCertificate) GetSigners() []byte {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+func (x *QuorumCertificate) GetAggSig() []byte {
+	if x != nil {
+		return x.AggSig
+	}
+	return nil
+}
+
+// Two conflicting votes (both Prepare or both Commit) signed by the same node in the same round
+type EquivocationProof struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	First  *Envelope `protobuf:"bytes,1,opt,name=first,proto3" json:"first,omitempty"`
+	Second *Envelope `protobuf:"bytes,2,opt,name=second,proto3" json:"second,omitempty"`
+}
+
+func (x *EquivocationProof) Reset() {
+	*x = EquivocationProof{}
+	mi := &file_beeftea_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EquivocationProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EquivocationProof) ProtoMessage() {}
+
+func (x *EquivocationProof) ProtoReflect() protoreflect.Message {
+	mi := &file_beeftea_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EquivocationProof.ProtoReflect.Descriptor instead.
+func (*EquivocationProof) Descriptor() ([]byte, []int) {
+	return file_beeftea_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *EquivocationProof) GetFirst() *Envelope {
+	if x != nil {
+		return x.First
+	}
+	return nil
+}
+
+func (x *EquivocationProof) GetSecond() *Envelope {
+	if x != nil {
+		return x.Second
+	}
+	return nil
+}
+
 type KeyValue struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -562,7 +2608,7 @@ type KeyValue struct {
 
 func (x *KeyValue) Reset() {
 	*x = KeyValue{}
-	mi := &file_beeftea_proto_msgTypes[10]
+	mi := &file_beeftea_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -574,7 +2620,7 @@ func (x *KeyValue) String() string {
 func (*KeyValue) ProtoMessage() {}
 
 func (x *KeyValue) ProtoReflect() protoreflect.Message {
-	mi := &file_beeftea_proto_msgTypes[10]
+	mi := &file_beeftea_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -587,7 +2633,7 @@ func (x *KeyValue) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KeyValue.ProtoReflect.Descriptor instead.
 func (*KeyValue) Descriptor() ([]byte, []int) {
-	return file_beeftea_proto_rawDescGZIP(), []int{10}
+	return file_beeftea_proto_rawDescGZIP(), []int{36}
 }
 
 func (x *KeyValue) GetKey() string {
@@ -608,62 +2654,380 @@ var File_beeftea_proto protoreflect.FileDescriptor
 
 var file_beeftea_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x07, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x22, 0x3b, 0x0a, 0x06, 0x50, 0x75, 0x74, 0x52,
-	0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x21, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
-	0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75,
-	0x65, 0x52, 0x02, 0x6b, 0x76, 0x22, 0x18, 0x0a, 0x06, 0x50, 0x75, 0x74, 0x52, 0x65, 0x73, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22,
-	0x1a, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x2b, 0x0a, 0x06, 0x47,
-	0x65, 0x74, 0x52, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65, 0x79, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x52, 0x02, 0x6b, 0x76, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x22, 0x5f, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x12, 0x22, 0x0a,
-	0x03, 0x6d, 0x73, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x62, 0x65, 0x65,
-	0x66, 0x74, 0x65, 0x61, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x03, 0x6d, 0x73,
-	0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78,
-	0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x73,
-	0x69, 0x67, 0x22, 0x9b, 0x01, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2f,
-	0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12,
-	0x2c, 0x0a, 0x07, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x10, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x61,
-	0x72, 0x65, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x12, 0x29, 0x0a,
-	0x06, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
-	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x48, 0x00,
-	0x52, 0x06, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x22, 0x7d, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x23, 0x0a, 0x04,
-	0x72, 0x65, 0x71, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x62, 0x65, 0x65,
-	0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x71, 0x52, 0x04, 0x72, 0x65, 0x71,
-	0x73, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x72,
-	0x6f, 0x6f, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x6f, 0x70,
-	0x6f, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
-	0x52, 0x0d, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22,
-	0x32, 0x0a, 0x07, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72,
-	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x44, 0x69, 0x67,
-	0x65, 0x73, 0x74, 0x22, 0x31, 0x0a, 0x06, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x27, 0x0a,
-	0x0f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c,
-	0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0x2e, 0x0a, 0x08, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x32, 0x5f, 0x0a, 0x0b, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e,
-	0x61, 0x6c, 0x52, 0x50, 0x43, 0x12, 0x27, 0x0a, 0x03, 0x50, 0x75, 0x74, 0x12, 0x0f, 0x2e, 0x62,
-	0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0f, 0x2e,
-	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x73, 0x12, 0x27,
-	0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e,
-	0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61,
-	0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x32, 0x39, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x73, 0x65,
-	0x6e, 0x73, 0x75, 0x73, 0x52, 0x50, 0x43, 0x12, 0x29, 0x0a, 0x04, 0x53, 0x65, 0x6e, 0x64, 0x12,
-	0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f,
-	0x70, 0x65, 0x1a, 0x0e, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x07, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x22, 0x8b, 0x02, 0x0a, 0x06, 0x50, 0x75, 0x74,
+	0x52, 0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x21, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x02, 0x6b, 0x76, 0x12, 0x46, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x71, 0x2e, 0x54,
+	0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x0c, 0x74, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x2d,
+	0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x1a, 0x3f, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xdd, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x12, 0x23, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x13, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x4f, 0x70, 0x52, 0x02, 0x6f, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10,
+	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x65, 0x61, 0x72, 0x6e,
+	0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65,
+	0x72, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03,
+	0x73, 0x65, 0x71, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x5f,
+	0x73, 0x69, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x6f, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x6f, 0x72, 0x53, 0x69, 0x67, 0x22, 0x18, 0x0a, 0x06, 0x50, 0x75, 0x74, 0x52, 0x65, 0x73,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x22, 0x30, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x70, 0x72, 0x6f, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x70, 0x72, 0x6f,
+	0x76, 0x65, 0x22, 0xa9, 0x01, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x12, 0x21, 0x0a,
+	0x02, 0x6b, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66,
+	0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x02, 0x6b, 0x76,
+	0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x2d, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73,
+	0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74,
+	0x65, 0x61, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x37, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x5f,
+	0x71, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74,
+	0x65, 0x61, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x51, 0x63, 0x22, 0x22,
+	0x0a, 0x08, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x22, 0x5f, 0x0a, 0x0a, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x21, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62,
+	0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52,
+	0x02, 0x6b, 0x76, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x22, 0x2d, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x22, 0x37, 0x0a, 0x07, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x4c, 0x0a, 0x07, 0x53,
+	0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x03, 0x6b, 0x76, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65,
+	0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x03, 0x6b, 0x76, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
+	0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x22, 0x44, 0x0a, 0x0f, 0x4c, 0x69, 0x73,
+	0x74, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x12, 0x22, 0x0a, 0x0a,
+	0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x48, 0x00, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x88, 0x01, 0x01,
+	0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22,
+	0x45, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x52,
+	0x65, 0x73, 0x12, 0x32, 0x0a, 0x06, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45, 0x71, 0x75,
+	0x69, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x06,
+	0x70, 0x72, 0x6f, 0x6f, 0x66, 0x73, 0x22, 0x33, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x65, 0x61,
+	0x63, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x12, 0x19, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x88, 0x01,
+	0x01, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x62, 0x0a, 0x0c, 0x47,
+	0x65, 0x74, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x6e, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0a, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x6e, 0x65, 0x73, 0x73, 0x22,
+	0x4f, 0x0a, 0x0e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x52, 0x65,
+	0x71, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x2d, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x22, 0x58, 0x0a, 0x11, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x2d, 0x0a, 0x08, 0x72,
+	0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0x6c, 0x0a, 0x0e, 0x47, 0x65,
+	0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x12, 0x1d, 0x0a, 0x0a,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x74,
+	0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x69,
+	0x6d, 0x65, 0x4d, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x03, 0x73, 0x69, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x6f, 0x77, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x03, 0x6e, 0x6f, 0x77, 0x22, 0xf1, 0x03, 0x0a, 0x08, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x2e, 0x0a, 0x13, 0x70,
+	0x72, 0x65, 0x76, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f,
+	0x6f, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x11, 0x70, 0x72, 0x65, 0x76, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x29, 0x0a, 0x02, 0x64,
+	0x62, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65,
+	0x61, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x44, 0x62, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x02, 0x64, 0x62, 0x12, 0x27, 0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e,
+	0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x12,
+	0x47, 0x0a, 0x11, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65,
+	0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x52, 0x65,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x10, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52,
+	0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x48, 0x61, 0x73, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x72,
+	0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x65, 0x71, 0x12, 0x48, 0x0a, 0x0d, 0x63, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x23, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x49, 0x64,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65,
+	0x64, 0x49, 0x64, 0x73, 0x1a, 0x35, 0x0a, 0x07, 0x44, 0x62, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3f, 0x0a, 0x11, 0x43,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x49, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x87, 0x01, 0x0a,
+	0x08, 0x50, 0x65, 0x65, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x70,
+	0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6c,
+	0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x22, 0x24, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x42, 0x65, 0x68,
+	0x61, 0x76, 0x69, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x70, 0x65, 0x63,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x70, 0x65, 0x63, 0x22, 0x0b, 0x0a, 0x09,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x22, 0x95, 0x06, 0x0a, 0x09, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x65, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6e, 0x6f, 0x64,
+	0x65, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x65, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x73, 0x65, 0x65, 0x64,
+	0x12, 0x3b, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61,
+	0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x23, 0x0a,
+	0x0d, 0x6e, 0x75, 0x6d, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6e, 0x75, 0x6d, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61,
+	0x6c, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x12, 0x1c,
+	0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x08,
+	0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x54, 0x61, 0x6c,
+	0x6c, 0x79, 0x52, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x54, 0x61, 0x6c, 0x6c,
+	0x79, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65,
+	0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x3f, 0x0a,
+	0x0d, 0x66, 0x75, 0x74, 0x75, 0x72, 0x65, 0x5f, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x46,
+	0x75, 0x74, 0x75, 0x72, 0x65, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x0c, 0x66, 0x75, 0x74, 0x75, 0x72, 0x65, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x12, 0x29,
+	0x0a, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x65, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x05, 0x70, 0x65, 0x65, 0x72, 0x73, 0x12, 0x35, 0x0a, 0x14, 0x6c, 0x61, 0x73,
+	0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x5f, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x88, 0x01, 0x01,
+	0x12, 0x40, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x5f,
+	0x71, 0x63, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74,
+	0x65, 0x61, 0x2e, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x51, 0x63, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x64, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x10, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x09, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x48, 0x61, 0x73, 0x68, 0x12, 0x47, 0x0a, 0x11,
+	0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x73, 0x18, 0x11, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65,
+	0x61, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x52, 0x10, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x72, 0x65, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x65, 0x71, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x6c, 0x61, 0x73,
+	0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x5f, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x22, 0x81, 0x01, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x53, 0x75,
+	0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a,
+	0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x75,
+	0x6d, 0x5f, 0x72, 0x65, 0x71, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6e, 0x75,
+	0x6d, 0x52, 0x65, 0x71, 0x73, 0x22, 0x3b, 0x0a, 0x09, 0x56, 0x6f, 0x74, 0x65, 0x54, 0x61, 0x6c,
+	0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f,
+	0x74, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x76, 0x6f, 0x74, 0x65,
+	0x72, 0x73, 0x22, 0x7f, 0x0a, 0x11, 0x46, 0x75, 0x74, 0x75, 0x72, 0x65, 0x42, 0x75, 0x66, 0x66,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x75, 0x66, 0x66, 0x65,
+	0x72, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x62, 0x75, 0x66, 0x66, 0x65,
+	0x72, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x64, 0x22, 0x96, 0x01, 0x0a, 0x0a, 0x50, 0x65, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x22, 0x07, 0x0a, 0x05,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x9a, 0x02, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f,
+	0x70, 0x65, 0x12, 0x22, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x65,
+	0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x03, 0x73, 0x69, 0x67, 0x12, 0x48, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x63, 0x65,
+	0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23,
+	0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70,
+	0x65, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x12, 0x2e, 0x0a, 0x07, 0x73, 0x69, 0x67, 0x5f, 0x61, 0x6c, 0x67, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x15, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4b, 0x65, 0x79,
+	0x41, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x52, 0x06, 0x73, 0x69, 0x67, 0x41, 0x6c,
+	0x67, 0x1a, 0x3f, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xa9, 0x03, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x12, 0x2f, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61,
+	0x2e, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x2c, 0x0a, 0x07, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61,
+	0x2e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x48, 0x00, 0x52, 0x07, 0x70, 0x72, 0x65, 0x70,
+	0x61, 0x72, 0x65, 0x12, 0x29, 0x0a, 0x06, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x48, 0x00, 0x52, 0x06, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x40,
+	0x0a, 0x0c, 0x65, 0x71, 0x75, 0x69, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45,
+	0x71, 0x75, 0x69, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x48, 0x00, 0x52, 0x0c, 0x65, 0x71, 0x75, 0x69, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x2c, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x48, 0x00, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x39,
+	0x0a, 0x0c, 0x62, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x42,
+	0x65, 0x61, 0x63, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x62, 0x65,
+	0x61, 0x63, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x4b, 0x0a, 0x12, 0x71, 0x75, 0x6f,
+	0x72, 0x75, 0x6d, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e,
+	0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x48, 0x00, 0x52, 0x11, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x09,
+	0x0a, 0x07, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x2e, 0x0a, 0x0b, 0x42, 0x65, 0x61,
+	0x63, 0x6f, 0x6e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69, 0x67, 0x22, 0xef, 0x01, 0x0a, 0x08, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x23, 0x0a, 0x04, 0x72, 0x65, 0x71, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50,
+	0x75, 0x74, 0x52, 0x65, 0x71, 0x52, 0x04, 0x72, 0x65, 0x71, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x70,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f,
+	0x6f, 0x66, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x72, 0x5f, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x2e, 0x0a, 0x13, 0x70, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0e, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x4d, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x22, 0x4b, 0x0a, 0x07, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73,
+	0x61, 0x6c, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12,
+	0x17, 0x0a, 0x07, 0x62, 0x6c, 0x73, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x06, 0x62, 0x6c, 0x73, 0x53, 0x69, 0x67, 0x22, 0x4a, 0x0a, 0x06, 0x43, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x5f, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x72, 0x6f,
+	0x70, 0x6f, 0x73, 0x61, 0x6c, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x62,
+	0x6c, 0x73, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x62, 0x6c,
+	0x73, 0x53, 0x69, 0x67, 0x22, 0xab, 0x01, 0x0a, 0x11, 0x51, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x43,
+	0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x12, 0x24, 0x0a, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0e, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52,
+	0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73,
+	0x61, 0x6c, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0e, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x67, 0x67,
+	0x5f, 0x73, 0x69, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x61, 0x67, 0x67, 0x53,
+	0x69, 0x67, 0x22, 0x67, 0x0a, 0x11, 0x45, 0x71, 0x75, 0x69, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x27, 0x0a, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61,
+	0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x12, 0x29, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c,
+	0x6f, 0x70, 0x65, 0x52, 0x06, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22, 0x2e, 0x0a, 0x08, 0x4b,
+	0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x2a, 0x52, 0x0a, 0x0a, 0x52,
+	0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4f, 0x70, 0x12, 0x13, 0x0a, 0x0f, 0x52, 0x45, 0x43,
+	0x4f, 0x4e, 0x46, 0x49, 0x47, 0x5f, 0x4f, 0x50, 0x5f, 0x41, 0x44, 0x44, 0x10, 0x00, 0x12, 0x16,
+	0x0a, 0x12, 0x52, 0x45, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x47, 0x5f, 0x4f, 0x50, 0x5f, 0x52, 0x45,
+	0x4d, 0x4f, 0x56, 0x45, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x52, 0x45, 0x43, 0x4f, 0x4e, 0x46,
+	0x49, 0x47, 0x5f, 0x4f, 0x50, 0x5f, 0x52, 0x45, 0x50, 0x4c, 0x41, 0x43, 0x45, 0x10, 0x02, 0x2a,
+	0x41, 0x0a, 0x0c, 0x4b, 0x65, 0x79, 0x41, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12,
+	0x16, 0x0a, 0x12, 0x4b, 0x45, 0x59, 0x5f, 0x41, 0x4c, 0x47, 0x4f, 0x52, 0x49, 0x54, 0x48, 0x4d,
+	0x5f, 0x50, 0x32, 0x35, 0x36, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x4b, 0x45, 0x59, 0x5f, 0x41,
+	0x4c, 0x47, 0x4f, 0x52, 0x49, 0x54, 0x48, 0x4d, 0x5f, 0x45, 0x44, 0x32, 0x35, 0x35, 0x31, 0x39,
+	0x10, 0x01, 0x2a, 0x2c, 0x0a, 0x05, 0x50, 0x68, 0x61, 0x73, 0x65, 0x12, 0x11, 0x0a, 0x0d, 0x50,
+	0x48, 0x41, 0x53, 0x45, 0x5f, 0x50, 0x52, 0x45, 0x50, 0x41, 0x52, 0x45, 0x10, 0x00, 0x12, 0x10,
+	0x0a, 0x0c, 0x50, 0x48, 0x41, 0x53, 0x45, 0x5f, 0x43, 0x4f, 0x4d, 0x4d, 0x49, 0x54, 0x10, 0x01,
+	0x32, 0xec, 0x02, 0x0a, 0x0b, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x52, 0x50, 0x43,
+	0x12, 0x27, 0x0a, 0x03, 0x50, 0x75, 0x74, 0x12, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65,
+	0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74,
+	0x65, 0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x03, 0x47, 0x65, 0x74,
+	0x12, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x71, 0x1a, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x73, 0x12, 0x42, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e,
+	0x63, 0x65, 0x12, 0x18, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x18, 0x2e, 0x62,
+	0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x76, 0x69, 0x64, 0x65,
+	0x6e, 0x63, 0x65, 0x52, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x42, 0x65, 0x61,
+	0x63, 0x6f, 0x6e, 0x12, 0x15, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x47, 0x65,
+	0x74, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x15, 0x2e, 0x62, 0x65, 0x65,
+	0x66, 0x74, 0x65, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x12, 0x31, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x11, 0x2e, 0x62, 0x65, 0x65,
+	0x66, 0x74, 0x65, 0x61, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x13, 0x2e,
+	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x30, 0x01, 0x12, 0x2d, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x12,
+	0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x1a, 0x0f, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x75, 0x74,
+	0x52, 0x65, 0x73, 0x12, 0x2a, 0x0a, 0x04, 0x53, 0x63, 0x61, 0x6e, 0x12, 0x10, 0x2e, 0x62, 0x65,
+	0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e,
+	0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x32,
+	0xad, 0x01, 0x0a, 0x08, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x52, 0x50, 0x43, 0x12, 0x36, 0x0a, 0x0b,
+	0x53, 0x65, 0x74, 0x42, 0x65, 0x68, 0x61, 0x76, 0x69, 0x6f, 0x72, 0x12, 0x17, 0x2e, 0x62, 0x65,
+	0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x65, 0x74, 0x42, 0x65, 0x68, 0x61, 0x76, 0x69, 0x6f,
+	0x72, 0x52, 0x65, 0x71, 0x1a, 0x0e, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x12, 0x30, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12,
+	0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x65, 0x71, 0x1a, 0x12, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x65, 0x12, 0x17, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x0f,
+	0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x73, 0x32,
+	0x74, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x52, 0x50, 0x43, 0x12,
+	0x29, 0x0a, 0x04, 0x53, 0x65, 0x6e, 0x64, 0x12, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65,
+	0x61, 0x2e, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x1a, 0x0e, 0x2e, 0x62, 0x65, 0x65,
+	0x66, 0x74, 0x65, 0x61, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x39, 0x0a, 0x0b, 0x47, 0x65,
+	0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x17, 0x2e, 0x62, 0x65, 0x65, 0x66,
+	0x74, 0x65, 0x61, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
+	0x65, 0x71, 0x1a, 0x11, 0x2e, 0x62, 0x65, 0x65, 0x66, 0x74, 0x65, 0x61, 0x2e, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -678,39 +3042,121 @@ func file_beeftea_proto_rawDescGZIP() []byte {
 	return file_beeftea_proto_rawDescData
 }
 
-var file_beeftea_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_beeftea_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_beeftea_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_beeftea_proto_goTypes = []any{
-	(*PutReq)(nil),   // 0: beeftea.PutReq
-	(*PutRes)(nil),   // 1: beeftea.PutRes
-	(*GetReq)(nil),   // 2: beeftea.GetReq
-	(*GetRes)(nil),   // 3: beeftea.GetRes
-	(*Empty)(nil),    // 4: beeftea.Empty
-	(*Envelope)(nil), // 5: beeftea.Envelope
-	(*Message)(nil),  // 6: beeftea.Message
-	(*Proposal)(nil), // 7: beeftea.Proposal
-	(*Prepare)(nil),  // 8: beeftea.Prepare
-	(*Commit)(nil),   // 9: beeftea.Commit
-	(*KeyValue)(nil), // 10: beeftea.KeyValue
+	(ReconfigOp)(0),           // 0: beeftea.ReconfigOp
+	(KeyAlgorithm)(0),         // 1: beeftea.KeyAlgorithm
+	(Phase)(0),                // 2: beeftea.Phase
+	(*PutReq)(nil),            // 3: beeftea.PutReq
+	(*Reconfig)(nil),          // 4: beeftea.Reconfig
+	(*PutRes)(nil),            // 5: beeftea.PutRes
+	(*GetReq)(nil),            // 6: beeftea.GetReq
+	(*GetRes)(nil),            // 7: beeftea.GetRes
+	(*WatchReq)(nil),          // 8: beeftea.WatchReq
+	(*WatchEvent)(nil),        // 9: beeftea.WatchEvent
+	(*DeleteReq)(nil),         // 10: beeftea.DeleteReq
+	(*ScanReq)(nil),           // 11: beeftea.ScanReq
+	(*ScanRes)(nil),           // 12: beeftea.ScanRes
+	(*ListEvidenceReq)(nil),   // 13: beeftea.ListEvidenceReq
+	(*ListEvidenceRes)(nil),   // 14: beeftea.ListEvidenceRes
+	(*GetBeaconReq)(nil),      // 15: beeftea.GetBeaconReq
+	(*GetBeaconRes)(nil),      // 16: beeftea.GetBeaconRes
+	(*ReconfigureReq)(nil),    // 17: beeftea.ReconfigureReq
+	(*ScheduledReconfig)(nil), // 18: beeftea.ScheduledReconfig
+	(*GetSnapshotReq)(nil),    // 19: beeftea.GetSnapshotReq
+	(*Snapshot)(nil),          // 20: beeftea.Snapshot
+	(*PeerInfo)(nil),          // 21: beeftea.PeerInfo
+	(*SetBehaviorReq)(nil),    // 22: beeftea.SetBehaviorReq
+	(*StatusReq)(nil),         // 23: beeftea.StatusReq
+	(*StatusRes)(nil),         // 24: beeftea.StatusRes
+	(*ProposalSummary)(nil),   // 25: beeftea.ProposalSummary
+	(*VoteTally)(nil),         // 26: beeftea.VoteTally
+	(*FutureBufferStats)(nil), // 27: beeftea.FutureBufferStats
+	(*PeerStatus)(nil),        // 28: beeftea.PeerStatus
+	(*Empty)(nil),             // 29: beeftea.Empty
+	(*Envelope)(nil),          // 30: beeftea.Envelope
+	(*Message)(nil),           // 31: beeftea.Message
+	(*Timeout)(nil),           // 32: beeftea.Timeout
+	(*BeaconShare)(nil),       // 33: beeftea.BeaconShare
+	(*Proposal)(nil),          // 34: beeftea.Proposal
+	(*Prepare)(nil),           // 35: beeftea.Prepare
+	(*Commit)(nil),            // 36: beeftea.Commit
+	(*QuorumCertificate)(nil), // 37: beeftea.QuorumCertificate
+	(*EquivocationProof)(nil), // 38: beeftea.EquivocationProof
+	(*KeyValue)(nil),          // 39: beeftea.KeyValue
+	nil,                       // 40: beeftea.PutReq.TraceContextEntry
+	nil,                       // 41: beeftea.Snapshot.DbEntry
+	nil,                       // 42: beeftea.Snapshot.CommittedIdsEntry
+	nil,                       // 43: beeftea.Envelope.TraceContextEntry
 }
 var file_beeftea_proto_depIdxs = []int32{
-	10, // 0: beeftea.PutReq.kv:type_name -> beeftea.KeyValue
-	10, // 1: beeftea.GetRes.kv:type_name -> beeftea.KeyValue
-	6,  // 2: beeftea.Envelope.msg:type_name -> beeftea.Message
-	7,  // 3: beeftea.Message.proposal:type_name -> beeftea.Proposal
-	8,  // 4: beeftea.Message.prepare:type_name -> beeftea.Prepare
-	9,  // 5: beeftea.Message.commit:type_name -> beeftea.Commit
-	0,  // 6: beeftea.Proposal.reqs:type_name -> beeftea.PutReq
-	0,  // 7: beeftea.ExternalRPC.Put:input_type -> beeftea.PutReq
-	2,  // 8: beeftea.ExternalRPC.Get:input_type -> beeftea.GetReq
-	5,  // 9: beeftea.ConsensusRPC.Send:input_type -> beeftea.Envelope
-	1,  // 10: beeftea.ExternalRPC.Put:output_type -> beeftea.PutRes
-	3,  // 11: beeftea.ExternalRPC.Get:output_type -> beeftea.GetRes
-	4,  // 12: beeftea.ConsensusRPC.Send:output_type -> beeftea.Empty
-	10, // [10:13] is the sub-list for method output_type
-	7,  // [7:10] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	39, // 0: beeftea.PutReq.kv:type_name -> beeftea.KeyValue
+	40, // 1: beeftea.PutReq.trace_context:type_name -> beeftea.PutReq.TraceContextEntry
+	4,  // 2: beeftea.PutReq.reconfig:type_name -> beeftea.Reconfig
+	0,  // 3: beeftea.Reconfig.op:type_name -> beeftea.ReconfigOp
+	39, // 4: beeftea.GetRes.kv:type_name -> beeftea.KeyValue
+	34, // 5: beeftea.GetRes.proposal:type_name -> beeftea.Proposal
+	37, // 6: beeftea.GetRes.commit_qc:type_name -> beeftea.QuorumCertificate
+	39, // 7: beeftea.WatchEvent.kv:type_name -> beeftea.KeyValue
+	39, // 8: beeftea.ScanRes.kvs:type_name -> beeftea.KeyValue
+	38, // 9: beeftea.ListEvidenceRes.proofs:type_name -> beeftea.EquivocationProof
+	4,  // 10: beeftea.ReconfigureReq.reconfig:type_name -> beeftea.Reconfig
+	4,  // 11: beeftea.ScheduledReconfig.reconfig:type_name -> beeftea.Reconfig
+	41, // 12: beeftea.Snapshot.db:type_name -> beeftea.Snapshot.DbEntry
+	21, // 13: beeftea.Snapshot.peers:type_name -> beeftea.PeerInfo
+	18, // 14: beeftea.Snapshot.pending_reconfigs:type_name -> beeftea.ScheduledReconfig
+	42, // 15: beeftea.Snapshot.committed_ids:type_name -> beeftea.Snapshot.CommittedIdsEntry
+	25, // 16: beeftea.StatusRes.min_proposal:type_name -> beeftea.ProposalSummary
+	26, // 17: beeftea.StatusRes.prepares:type_name -> beeftea.VoteTally
+	26, // 18: beeftea.StatusRes.commits:type_name -> beeftea.VoteTally
+	27, // 19: beeftea.StatusRes.future_buffer:type_name -> beeftea.FutureBufferStats
+	28, // 20: beeftea.StatusRes.peers:type_name -> beeftea.PeerStatus
+	37, // 21: beeftea.StatusRes.last_commit_qc:type_name -> beeftea.QuorumCertificate
+	18, // 22: beeftea.StatusRes.pending_reconfigs:type_name -> beeftea.ScheduledReconfig
+	31, // 23: beeftea.Envelope.msg:type_name -> beeftea.Message
+	43, // 24: beeftea.Envelope.trace_context:type_name -> beeftea.Envelope.TraceContextEntry
+	1,  // 25: beeftea.Envelope.sig_alg:type_name -> beeftea.KeyAlgorithm
+	34, // 26: beeftea.Message.proposal:type_name -> beeftea.Proposal
+	35, // 27: beeftea.Message.prepare:type_name -> beeftea.Prepare
+	36, // 28: beeftea.Message.commit:type_name -> beeftea.Commit
+	38, // 29: beeftea.Message.equivocation:type_name -> beeftea.EquivocationProof
+	32, // 30: beeftea.Message.timeout:type_name -> beeftea.Timeout
+	33, // 31: beeftea.Message.beacon_share:type_name -> beeftea.BeaconShare
+	37, // 32: beeftea.Message.quorum_certificate:type_name -> beeftea.QuorumCertificate
+	3,  // 33: beeftea.Proposal.reqs:type_name -> beeftea.PutReq
+	2,  // 34: beeftea.QuorumCertificate.phase:type_name -> beeftea.Phase
+	30, // 35: beeftea.EquivocationProof.first:type_name -> beeftea.Envelope
+	30, // 36: beeftea.EquivocationProof.second:type_name -> beeftea.Envelope
+	3,  // 37: beeftea.ExternalRPC.Put:input_type -> beeftea.PutReq
+	6,  // 38: beeftea.ExternalRPC.Get:input_type -> beeftea.GetReq
+	13, // 39: beeftea.ExternalRPC.ListEvidence:input_type -> beeftea.ListEvidenceReq
+	15, // 40: beeftea.ExternalRPC.GetBeacon:input_type -> beeftea.GetBeaconReq
+	8,  // 41: beeftea.ExternalRPC.Watch:input_type -> beeftea.WatchReq
+	10, // 42: beeftea.ExternalRPC.Delete:input_type -> beeftea.DeleteReq
+	11, // 43: beeftea.ExternalRPC.Scan:input_type -> beeftea.ScanReq
+	22, // 44: beeftea.AdminRPC.SetBehavior:input_type -> beeftea.SetBehaviorReq
+	23, // 45: beeftea.AdminRPC.Status:input_type -> beeftea.StatusReq
+	17, // 46: beeftea.AdminRPC.Reconfigure:input_type -> beeftea.ReconfigureReq
+	30, // 47: beeftea.ConsensusRPC.Send:input_type -> beeftea.Envelope
+	19, // 48: beeftea.ConsensusRPC.GetSnapshot:input_type -> beeftea.GetSnapshotReq
+	5,  // 49: beeftea.ExternalRPC.Put:output_type -> beeftea.PutRes
+	7,  // 50: beeftea.ExternalRPC.Get:output_type -> beeftea.GetRes
+	14, // 51: beeftea.ExternalRPC.ListEvidence:output_type -> beeftea.ListEvidenceRes
+	16, // 52: beeftea.ExternalRPC.GetBeacon:output_type -> beeftea.GetBeaconRes
+	9,  // 53: beeftea.ExternalRPC.Watch:output_type -> beeftea.WatchEvent
+	5,  // 54: beeftea.ExternalRPC.Delete:output_type -> beeftea.PutRes
+	12, // 55: beeftea.ExternalRPC.Scan:output_type -> beeftea.ScanRes
+	29, // 56: beeftea.AdminRPC.SetBehavior:output_type -> beeftea.Empty
+	24, // 57: beeftea.AdminRPC.Status:output_type -> beeftea.StatusRes
+	5,  // 58: beeftea.AdminRPC.Reconfigure:output_type -> beeftea.PutRes
+	29, // 59: beeftea.ConsensusRPC.Send:output_type -> beeftea.Empty
+	20, // 60: beeftea.ConsensusRPC.GetSnapshot:output_type -> beeftea.Snapshot
+	49, // [49:61] is the sub-list for method output_type
+	37, // [37:49] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_beeftea_proto_init() }
@@ -718,23 +3164,31 @@ func file_beeftea_proto_init() {
 	if File_beeftea_proto != nil {
 		return
 	}
-	file_beeftea_proto_msgTypes[6].OneofWrappers = []any{
+	file_beeftea_proto_msgTypes[10].OneofWrappers = []any{}
+	file_beeftea_proto_msgTypes[12].OneofWrappers = []any{}
+	file_beeftea_proto_msgTypes[21].OneofWrappers = []any{}
+	file_beeftea_proto_msgTypes[28].OneofWrappers = []any{
 		(*Message_Proposal)(nil),
 		(*Message_Prepare)(nil),
 		(*Message_Commit)(nil),
+		(*Message_Equivocation)(nil),
+		(*Message_Timeout)(nil),
+		(*Message_BeaconShare)(nil),
+		(*Message_QuorumCertificate)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_beeftea_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      3,
+			NumMessages:   41,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   3,
 		},
 		GoTypes:           file_beeftea_proto_goTypes,
 		DependencyIndexes: file_beeftea_proto_depIdxs,
+		EnumInfos:         file_beeftea_proto_enumTypes,
 		MessageInfos:      file_beeftea_proto_msgTypes,
 	}.Build()
 	File_beeftea_proto = out.File
@@ -19,8 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ExternalRPC_Put_FullMethodName = "/beeftea.ExternalRPC/Put"
-	ExternalRPC_Get_FullMethodName = "/beeftea.ExternalRPC/Get"
+	ExternalRPC_Put_FullMethodName          = "/beeftea.ExternalRPC/Put"
+	ExternalRPC_Get_FullMethodName          = "/beeftea.ExternalRPC/Get"
+	ExternalRPC_ListEvidence_FullMethodName = "/beeftea.ExternalRPC/ListEvidence"
+	ExternalRPC_GetBeacon_FullMethodName    = "/beeftea.ExternalRPC/GetBeacon"
+	ExternalRPC_Watch_FullMethodName        = "/beeftea.ExternalRPC/Watch"
+	ExternalRPC_Delete_FullMethodName       = "/beeftea.ExternalRPC/Delete"
+	ExternalRPC_Scan_FullMethodName         = "/beeftea.ExternalRPC/Scan"
 )
 
 // ExternalRPCClient is the client API for ExternalRPC service.
@@ -29,6 +34,13 @@ const (
 type ExternalRPCClient interface {
 	Put(ctx context.Context, in *PutReq, opts ...grpc.CallOption) (*PutRes, error)
 	Get(ctx context.Context, in *GetReq, opts ...grpc.CallOption) (*GetRes, error)
+	ListEvidence(ctx context.Context, in *ListEvidenceReq, opts ...grpc.CallOption) (*ListEvidenceRes, error)
+	GetBeacon(ctx context.Context, in *GetBeaconReq, opts ...grpc.CallOption) (*GetBeaconRes, error)
+	// Watch streams the writes and deletes committed from now on to the keys that start with a prefix
+	Watch(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+	Delete(ctx context.Context, in *DeleteReq, opts ...grpc.CallOption) (*PutRes, error)
+	// Scan returns the keys that start with a prefix and their values, in key order
+	Scan(ctx context.Context, in *ScanReq, opts ...grpc.CallOption) (*ScanRes, error)
 }
 
 type externalRPCClient struct {
@@ -59,12 +71,78 @@ func (c *externalRPCClient) Get(ctx context.Context, in *GetReq, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *externalRPCClient) ListEvidence(ctx context.Context, in *ListEvidenceReq, opts ...grpc.CallOption) (*ListEvidenceRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEvidenceRes)
+	err := c.cc.Invoke(ctx, ExternalRPC_ListEvidence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *externalRPCClient) GetBeacon(ctx context.Context, in *GetBeaconReq, opts ...grpc.CallOption) (*GetBeaconRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBeaconRes)
+	err := c.cc.Invoke(ctx, ExternalRPC_GetBeacon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *externalRPCClient) Watch(ctx context.Context, in *WatchReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ExternalRPC_ServiceDesc.Streams[0], ExternalRPC_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchReq, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExternalRPC_WatchClient = grpc.ServerStreamingClient[WatchEvent]
+
+func (c *externalRPCClient) Delete(ctx context.Context, in *DeleteReq, opts ...grpc.CallOption) (*PutRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutRes)
+	err := c.cc.Invoke(ctx, ExternalRPC_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *externalRPCClient) Scan(ctx context.Context, in *ScanReq, opts ...grpc.CallOption) (*ScanRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanRes)
+	err := c.cc.Invoke(ctx, ExternalRPC_Scan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ExternalRPCServer is the server API for ExternalRPC service.
 // All implementations should embed UnimplementedExternalRPCServer
 // for forward compatibility.
 type ExternalRPCServer interface {
 	Put(context.Context, *PutReq) (*PutRes, error)
 	Get(context.Context, *GetReq) (*GetRes, error)
+	ListEvidence(context.Context, *ListEvidenceReq) (*ListEvidenceRes, error)
+	GetBeacon(context.Context, *GetBeaconReq) (*GetBeaconRes, error)
+	// Watch streams the writes and deletes committed from now on to the keys that start with a prefix
+	Watch(*WatchReq, grpc.ServerStreamingServer[WatchEvent]) error
+	Delete(context.Context, *DeleteReq) (*PutRes, error)
+	// Scan returns the keys that start with a prefix and their values, in key order
+	Scan(context.Context, *ScanReq) (*ScanRes, error)
 }
 
 // UnimplementedExternalRPCServer should be embedded to have
@@ -80,6 +158,21 @@ func (UnimplementedExternalRPCServer) Put(context.Context, *PutReq) (*PutRes, er
 func (UnimplementedExternalRPCServer) Get(context.Context, *GetReq) (*GetRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
+func (UnimplementedExternalRPCServer) ListEvidence(context.Context, *ListEvidenceReq) (*ListEvidenceRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEvidence not implemented")
+}
+func (UnimplementedExternalRPCServer) GetBeacon(context.Context, *GetBeaconReq) (*GetBeaconRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBeacon not implemented")
+}
+func (UnimplementedExternalRPCServer) Watch(*WatchReq, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedExternalRPCServer) Delete(context.Context, *DeleteReq) (*PutRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedExternalRPCServer) Scan(context.Context, *ScanReq) (*ScanRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
 func (UnimplementedExternalRPCServer) testEmbeddedByValue() {}
 
 // UnsafeExternalRPCServer may be embedded to opt out of forward compatibility for this service.
@@ -136,6 +229,89 @@ func _ExternalRPC_Get_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ExternalRPC_ListEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEvidenceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExternalRPCServer).ListEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExternalRPC_ListEvidence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExternalRPCServer).ListEvidence(ctx, req.(*ListEvidenceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExternalRPC_GetBeacon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBeaconReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExternalRPCServer).GetBeacon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExternalRPC_GetBeacon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExternalRPCServer).GetBeacon(ctx, req.(*GetBeaconReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExternalRPC_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExternalRPCServer).Watch(m, &grpc.GenericServerStream[WatchReq, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExternalRPC_WatchServer = grpc.ServerStreamingServer[WatchEvent]
+
+func _ExternalRPC_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExternalRPCServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExternalRPC_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExternalRPCServer).Delete(ctx, req.(*DeleteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExternalRPC_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExternalRPCServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExternalRPC_Scan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExternalRPCServer).Scan(ctx, req.(*ScanReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ExternalRPC_ServiceDesc is the grpc.ServiceDesc for ExternalRPC service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,13 +327,216 @@ var ExternalRPC_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Get",
 			Handler:    _ExternalRPC_Get_Handler,
 		},
+		{
+			MethodName: "ListEvidence",
+			Handler:    _ExternalRPC_ListEvidence_Handler,
+		},
+		{
+			MethodName: "GetBeacon",
+			Handler:    _ExternalRPC_GetBeacon_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ExternalRPC_Delete_Handler,
+		},
+		{
+			MethodName: "Scan",
+			Handler:    _ExternalRPC_Scan_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _ExternalRPC_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "beeftea.proto",
+}
+
+const (
+	AdminRPC_SetBehavior_FullMethodName = "/beeftea.AdminRPC/SetBehavior"
+	AdminRPC_Status_FullMethodName      = "/beeftea.AdminRPC/Status"
+	AdminRPC_Reconfigure_FullMethodName = "/beeftea.AdminRPC/Reconfigure"
+)
+
+// AdminRPCClient is the client API for AdminRPC service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminRPCClient interface {
+	SetBehavior(ctx context.Context, in *SetBehaviorReq, opts ...grpc.CallOption) (*Empty, error)
+	Status(ctx context.Context, in *StatusReq, opts ...grpc.CallOption) (*StatusRes, error)
+	// Reconfigure submits a membership change, which takes effect a few rounds after it is
+	// committed
+	Reconfigure(ctx context.Context, in *ReconfigureReq, opts ...grpc.CallOption) (*PutRes, error)
+}
+
+type adminRPCClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminRPCClient(cc grpc.ClientConnInterface) AdminRPCClient {
+	return &adminRPCClient{cc}
+}
+
+func (c *adminRPCClient) SetBehavior(ctx context.Context, in *SetBehaviorReq, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AdminRPC_SetBehavior_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminRPCClient) Status(ctx context.Context, in *StatusReq, opts ...grpc.CallOption) (*StatusRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusRes)
+	err := c.cc.Invoke(ctx, AdminRPC_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminRPCClient) Reconfigure(ctx context.Context, in *ReconfigureReq, opts ...grpc.CallOption) (*PutRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutRes)
+	err := c.cc.Invoke(ctx, AdminRPC_Reconfigure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminRPCServer is the server API for AdminRPC service.
+// All implementations should embed UnimplementedAdminRPCServer
+// for forward compatibility.
+type AdminRPCServer interface {
+	SetBehavior(context.Context, *SetBehaviorReq) (*Empty, error)
+	Status(context.Context, *StatusReq) (*StatusRes, error)
+	// Reconfigure submits a membership change, which takes effect a few rounds after it is
+	// committed
+	Reconfigure(context.Context, *ReconfigureReq) (*PutRes, error)
+}
+
+// UnimplementedAdminRPCServer should be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminRPCServer struct{}
+
+func (UnimplementedAdminRPCServer) SetBehavior(context.Context, *SetBehaviorReq) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBehavior not implemented")
+}
+func (UnimplementedAdminRPCServer) Status(context.Context, *StatusReq) (*StatusRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedAdminRPCServer) Reconfigure(context.Context, *ReconfigureReq) (*PutRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reconfigure not implemented")
+}
+func (UnimplementedAdminRPCServer) testEmbeddedByValue() {}
+
+// UnsafeAdminRPCServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminRPCServer will
+// result in compilation errors.
+type UnsafeAdminRPCServer interface {
+	mustEmbedUnimplementedAdminRPCServer()
+}
+
+func RegisterAdminRPCServer(s grpc.ServiceRegistrar, srv AdminRPCServer) {
+	// If the following call pancis, it indicates UnimplementedAdminRPCServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminRPC_ServiceDesc, srv)
+}
+
+func _AdminRPC_SetBehavior_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBehaviorReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminRPCServer).SetBehavior(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminRPC_SetBehavior_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminRPCServer).SetBehavior(ctx, req.(*SetBehaviorReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminRPC_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminRPCServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminRPC_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminRPCServer).Status(ctx, req.(*StatusReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminRPC_Reconfigure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconfigureReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminRPCServer).Reconfigure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminRPC_Reconfigure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminRPCServer).Reconfigure(ctx, req.(*ReconfigureReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminRPC_ServiceDesc is the grpc.ServiceDesc for AdminRPC service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminRPC_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "beeftea.AdminRPC",
+	HandlerType: (*AdminRPCServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetBehavior",
+			Handler:    _AdminRPC_SetBehavior_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _AdminRPC_Status_Handler,
+		},
+		{
+			MethodName: "Reconfigure",
+			Handler:    _AdminRPC_Reconfigure_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "beeftea.proto",
 }
 
 const (
-	ConsensusRPC_Send_FullMethodName = "/beeftea.ConsensusRPC/Send"
+	ConsensusRPC_Send_FullMethodName        = "/beeftea.ConsensusRPC/Send"
+	ConsensusRPC_GetSnapshot_FullMethodName = "/beeftea.ConsensusRPC/GetSnapshot"
 )
 
 // ConsensusRPCClient is the client API for ConsensusRPC service.
@@ -165,6 +544,8 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ConsensusRPCClient interface {
 	Send(ctx context.Context, in *Envelope, opts ...grpc.CallOption) (*Empty, error)
+	// GetSnapshot returns the state a node needs to join the cluster
+	GetSnapshot(ctx context.Context, in *GetSnapshotReq, opts ...grpc.CallOption) (*Snapshot, error)
 }
 
 type consensusRPCClient struct {
@@ -185,11 +566,23 @@ func (c *consensusRPCClient) Send(ctx context.Context, in *Envelope, opts ...grp
 	return out, nil
 }
 
+func (c *consensusRPCClient) GetSnapshot(ctx context.Context, in *GetSnapshotReq, opts ...grpc.CallOption) (*Snapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, ConsensusRPC_GetSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConsensusRPCServer is the server API for ConsensusRPC service.
 // All implementations should embed UnimplementedConsensusRPCServer
 // for forward compatibility.
 type ConsensusRPCServer interface {
 	Send(context.Context, *Envelope) (*Empty, error)
+	// GetSnapshot returns the state a node needs to join the cluster
+	GetSnapshot(context.Context, *GetSnapshotReq) (*Snapshot, error)
 }
 
 // UnimplementedConsensusRPCServer should be embedded to have
@@ -202,6 +595,9 @@ type UnimplementedConsensusRPCServer struct{}
 func (UnimplementedConsensusRPCServer) Send(context.Context, *Envelope) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Send not implemented")
 }
+func (UnimplementedConsensusRPCServer) GetSnapshot(context.Context, *GetSnapshotReq) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
 func (UnimplementedConsensusRPCServer) testEmbeddedByValue() {}
 
 // UnsafeConsensusRPCServer may be embedded to opt out of forward compatibility for this service.
@@ -240,6 +636,24 @@ func _ConsensusRPC_Send_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ConsensusRPC_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsensusRPCServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConsensusRPC_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsensusRPCServer).GetSnapshot(ctx, req.(*GetSnapshotReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ConsensusRPC_ServiceDesc is the grpc.ServiceDesc for ConsensusRPC service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -251,6 +665,10 @@ var ConsensusRPC_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Send",
 			Handler:    _ConsensusRPC_Send_Handler,
 		},
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _ConsensusRPC_GetSnapshot_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "beeftea.proto",
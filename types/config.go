@@ -1,25 +1,100 @@
 package types
 
 import (
-	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"github.com/patrickmao1/beeftea/beacon"
+	"github.com/patrickmao1/beeftea/clock"
+	"github.com/patrickmao1/beeftea/crypto"
 	"github.com/patrickmao1/beeftea/utils"
 	log "github.com/sirupsen/logrus"
+	"net"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	InitTime          time.Time
+	// A node's round lasts RoundDuration on its own clock, of which ProposalDuration is the
+	// proposal phase. Then it sends a Timeout and moves on to the next round once 2f+1 nodes did.
 	RoundDuration     time.Duration
 	ProposalDuration  time.Duration
 	ProposalThreshold uint32
+	// In responsive mode a node moves on to the next round as soon as the current one is
+	// committed, and times out right after the proposal phase if no proposal arrived.
+	// RoundDuration then only bounds how long it waits for the commit quorum.
+	Responsive bool
+	// With AdaptiveTimeouts the proposal phase and round timeouts start at ProposalDuration and
+	// RoundDuration and then follow the observed latencies within [MinTimeout, MaxTimeout]. In
+	// the non-responsive mode only the proposal phase adapts, as rounds are fixed slots.
+	AdaptiveTimeouts bool
+	MinTimeout       time.Duration // defaults to 50ms
+	MaxTimeout       time.Duration // defaults to 8 * RoundDuration, or 3/4 of it if not responsive
 
 	Peers []*Peer
 
+	// Identity of the network, from its genesis (see package genesis). ChainHash is mixed into the
+	// initial seed and into the signature of every envelope so that messages of one network are
+	// rejected by the others. Nodes without a genesis have none.
+	ChainID   string
+	ChainHash []byte
+	// Key that signs the membership changes (see Reconfig.OperatorSig), none are accepted if nil
+	OperatorKey crypto.PublicKey
+	// Contents of the key-value store before the first round
+	InitialState map[string]string
+	// Consensus address of a node of a running cluster to join from: the node fetches its state
+	// and peers from it and takes the slot of its own key, once a Reconfigure added it. Peers then
+	// only needs to hold my key.
+	JoinFrom string
+
+	// Keys of the randomness beacon that seeds sortition: the public keys of the dealing and my
+	// secret share (see package beacon). Without them the seed is chained from the proposer
+	// proofs, which a proposer can bias by withholding its proposal.
+	Beacon       *beacon.Public
+	BeaconSecret []byte
+
+	// Addresses the node's gRPC servers listen on. Default to 0.0.0.0:9090 for the consensus
+	// server and 0.0.0.0:8080 for the external server. Port 0 listens on a port picked by the OS,
+	// which Service.Start writes back into the address, as for the other listen addresses.
+	ListenAddr    string
+	RPCListenAddr string
+	// Address of the admin server (AdminRPC), which has no authentication and is only meant to be
+	// reached from the machine. Defaults to 127.0.0.1:7070.
+	AdminListenAddr string
+	// Address to serve Prometheus metrics on, metrics aren't served if empty
+	MetricsListenAddr string
+	// Address to serve the HTTP/JSON gateway of the external server on (see package gateway),
+	// not served if empty
+	HTTPListenAddr string
+	// Listener of the consensus server to use instead of listening on ListenAddr, for a port
+	// picked by the OS that the peers have to know before the node starts
+	Listener net.Listener
+
+	// Where to export trace spans: an OTLP/gRPC collector (host:port) and/or a file that spans are
+	// appended to as JSON lines. Tracing is disabled if both are empty.
+	OTLPEndpoint string
+	TraceFile    string
+
+	// File to append the per-round decision log to as JSON lines (see package audit), not written
+	// if empty
+	AuditLogFile string
+
+	// Time source of the node, defaults to the system clock
+	Clock clock.Clock
+
+	// Byzantine behaviors to inject, node index -> fault spec (see fault.Parse)
+	Faults map[uint32]string
+	// Lets Faults and AdminRPC.SetBehavior make the node Byzantine, for tests and testnets only
+	AllowFaultInjection bool
+
 	// cache fields
 	myIndex *uint32
 }
 
+// SetMyIndex tells the node which peer it is instead of finding itself by its private IP
+func (c *Config) SetMyIndex(idx uint32) {
+	c.myIndex = &idx
+}
+
 func (c *Config) MyIndex() uint32 {
 	if c.myIndex != nil {
 		return *c.myIndex
@@ -40,11 +115,113 @@ func (c *Config) MyIndex() uint32 {
 	return 0
 }
 
-func (c *Config) MyKey() *ecdsa.PrivateKey {
+func (c *Config) MyKey() crypto.PrivateKey {
 	return c.Peers[c.MyIndex()].Key
 }
 
+// Weight returns the weight of peer i in sortition and votes
+func (c *Config) Weight(i uint32) uint64 {
+	return c.Peers[i].weight()
+}
+
+// HasBLSKeys tells whether all validators have BLS keys, which quorum certificates need
+func (c *Config) HasBLSKeys() bool {
+	for _, peer := range c.Peers {
+		if peer.IsValidator() && peer.BLSKey == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// TotalWeight returns the sum of the weights of all peers
+func (c *Config) TotalWeight() uint64 {
+	total := uint64(0)
+	for _, peer := range c.Peers {
+		total += peer.weight()
+	}
+	return total
+}
+
 type Peer struct {
 	URL string
-	Key *ecdsa.PrivateKey
+	// Key the peer signs its messages and sortition proofs with, P-256 or Ed25519. Only my own
+	// is needed, the other peers can be given by their PublicKey.
+	Key       crypto.PrivateKey
+	PublicKey crypto.PublicKey
+	// Stake of the peer: the number of sub-users it runs in sortition and how much its votes
+	// count. 0 counts as 1 so that all peers weigh the same by default.
+	Weight uint64
+	// Key the peer signs its votes with for quorum certificates, nil if it has none
+	BLSKey *crypto.BLSPrivateKey
+	// Removed peers keep their slot so that indices don't change, but weigh nothing and their
+	// messages are rejected
+	Removed bool
+	// Learners replicate the store from the messages of the others, but don't propose, vote or
+	// weigh anything, and never send messages
+	Learner bool
+}
+
+// PeerEntry is a peer as written in config files, with its public key only
+type PeerEntry struct {
+	URL       string `json:"url"`
+	PublicKey string `json:"public_key"` // hex of crypto.MarshalPublic
+	Weight    uint64 `json:"weight,omitempty"`
+	Learner   bool   `json:"learner,omitempty"`
+	// Address clients reach the node's external RPC server at, optional
+	RPCURL string `json:"rpc_url,omitempty"`
+}
+
+func NewPeerEntry(url string, key crypto.PublicKey, weight uint64) *PeerEntry {
+	return &PeerEntry{URL: url, PublicKey: hex.EncodeToString(crypto.MarshalPublic(key)), Weight: weight}
+}
+
+// Peer decodes the entry
+func (e *PeerEntry) Peer() (*Peer, error) {
+	bs, err := hex.DecodeString(e.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of peer %s: %s", e.URL, err.Error())
+	}
+	key, err := crypto.UnmarshalPublic(bs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of peer %s: %s", e.URL, err.Error())
+	}
+	return &Peer{URL: e.URL, PublicKey: key, Weight: e.Weight, Learner: e.Learner}, nil
+}
+
+// PubKey returns the public key of the peer, from Key if PublicKey isn't set
+func (p *Peer) PubKey() crypto.PublicKey {
+	if p.PublicKey != nil {
+		return p.PublicKey
+	}
+	return p.Key.PublicKey()
+}
+
+// Info returns the peer as sent to joining nodes, without its private keys
+func (p *Peer) Info() *PeerInfo {
+	return &PeerInfo{Url: p.URL, PublicKey: crypto.MarshalPublic(p.PubKey()), Weight: p.Weight, Removed: p.Removed, Learner: p.Learner}
+}
+
+// NewPeer decodes a peer sent by another node
+func NewPeer(info *PeerInfo) (*Peer, error) {
+	key, err := crypto.UnmarshalPublic(info.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of peer %s: %s", info.Url, err.Error())
+	}
+	return &Peer{URL: info.Url, PublicKey: key, Weight: info.Weight, Removed: info.Removed, Learner: info.Learner}, nil
+}
+
+func (p *Peer) weight() uint64 {
+	if p.Removed || p.Learner {
+		return 0
+	}
+	if p.Weight == 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+// IsValidator tells whether the peer takes part in sortition and votes
+func (p *Peer) IsValidator() bool {
+	return !p.Removed && !p.Learner
 }
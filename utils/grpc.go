@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// GracefulStop stops svr once the RPCs in flight are handled, or right away when ctx is done
+func GracefulStop(ctx context.Context, svr *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		svr.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		svr.Stop()
+		<-done
+	}
+}